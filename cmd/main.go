@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -14,17 +21,31 @@ import (
 	"github.com/Secure-Website-Builder/Backend/internal/http/middleware"
 	"github.com/Secure-Website-Builder/Backend/internal/http/router"
 	"github.com/Secure-Website-Builder/Backend/internal/limiter"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/analytics"
 	"github.com/Secure-Website-Builder/Backend/internal/services/auth"
 	"github.com/Secure-Website-Builder/Backend/internal/services/cart"
 	"github.com/Secure-Website-Builder/Backend/internal/services/category"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
 	"github.com/Secure-Website-Builder/Backend/internal/services/media"
+	"github.com/Secure-Website-Builder/Backend/internal/services/order"
 	"github.com/Secure-Website-Builder/Backend/internal/services/product"
+	"github.com/Secure-Website-Builder/Backend/internal/services/session"
 	"github.com/Secure-Website-Builder/Backend/internal/services/store"
+	"github.com/Secure-Website-Builder/Backend/internal/services/tracking"
 	"github.com/Secure-Website-Builder/Backend/internal/storage"
 )
 
+// shutdownTimeout bounds how long in-flight requests get to finish once the
+// server is asked to stop.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 
+	// Cancelled on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment")
 	}
@@ -72,13 +93,30 @@ func main() {
 		log.Fatalf("failed to initialize image storage: %v", err)
 	}
 
+	// Payment providers. Only the fake, test-only provider exists so far, so a
+	// production server has none and checkout fails with "no payment provider".
+	payments := payment.NewRegistry()
+	if secrets.AppEnv != "production" {
+		// Without a secret anyone could forge a webhook and mark orders paid
+		if secrets.PaymentWebhookSecret == "" {
+			log.Println("PAYMENT_WEBHOOK_SECRET is not set, fake payment provider disabled")
+		} else {
+			payments.Register(payment.FakeMethod, payment.NewFakeProvider(secrets.PaymentWebhookSecret))
+		}
+	}
+
 	// Services
 	mediaService := media.New(storage)
 	categoryService := category.New(db)
 	productService := product.New(db, storage, mediaService)
-	cartService := cart.New(db)
+	cartService := cart.New(db, payments, appConfig.Checkout.ReservationTTL())
 	storeService := store.New(db, storage)
 	authService := auth.New(db, secrets.JWTSecret)
+	analyticsService := analytics.New(db)
+	orderService := order.New(db, payments)
+	sessionService := session.New(db)
+	inventoryService := inventory.New(db)
+	trackingService := tracking.New(db, appConfig.Tracking.BufferSize, appConfig.Tracking.BatchSize)
 
 	// Middleware helpers
 	storeOwnerChecker := middleware.NewStoreOwnerChecker(storeService)
@@ -93,11 +131,38 @@ func main() {
 
 	// Handlers
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	productHandler := handlers.NewProductHandler(productService)
+	productHandler := handlers.NewProductHandler(productService, trackingService)
 	categoryProductHandler := handlers.NewCategoryProductHandler(productService)
 	cartHandler := handlers.NewCartHandler(cartService)
 	authHandler := handlers.NewAuthHandler(authService)
 	storeHandler := handlers.NewStoreHandler(storeService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	paymentHandler := handlers.NewPaymentHandler(cartService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	trackingHandler := handlers.NewTrackingHandler(trackingService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+	// Imports run inside the server; any left open by the last run are lost
+	if n, err := productService.FailInterruptedImports(context.Background()); err != nil {
+		log.Printf("failed to close interrupted product imports: %v", err)
+	} else if n > 0 {
+		log.Printf("closed %d interrupted product imports", n)
+	}
+
+	// Background jobs
+	go cartService.RunReservationSweeper(ctx, appConfig.Checkout.SweepInterval())
+	go inventoryService.RunReconciler(ctx, appConfig.Inventory.ReconcileInterval())
+	go inventoryService.RunAlertScanner(ctx, appConfig.Inventory.AlertScanInterval())
+
+	// Tracking outlives the server so views recorded by the last requests
+	// are still flushed
+	trackingCtx, stopTracking := context.WithCancel(context.Background())
+	trackingDone := make(chan struct{})
+	go func() {
+		trackingService.Run(trackingCtx, appConfig.Tracking.FlushInterval())
+		close(trackingDone)
+	}()
 
 	// Router
 	r := router.SetupRouter(
@@ -107,6 +172,12 @@ func main() {
 		cartHandler,
 		authHandler,
 		storeHandler,
+		analyticsHandler,
+		orderHandler,
+		paymentHandler,
+		sessionHandler,
+		trackingHandler,
+		inventoryHandler,
 		rateLimiter,
 		storeOwnerChecker,
 		secrets.JWTSecret,
@@ -117,5 +188,33 @@ func main() {
 		port = "8080"
 	}
 
-	r.Run(":" + port)
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: r,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("server stopped: %v", err)
+		}
+	case <-ctx.Done():
+		log.Println("shutting down")
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown: %v", err)
+	}
+
+	// Wait for the final flush before the database is closed
+	stopTracking()
+	<-trackingDone
 }
@@ -16,8 +16,27 @@ type RateLimitConfig struct {
 	CleanupIntervalMinutes int `json:"cleanup_interval_minutes"`
 }
 
+type CheckoutConfig struct {
+	ReservationMinutes   int `json:"reservation_minutes"`
+	SweepIntervalSeconds int `json:"sweep_interval_seconds"`
+}
+
+type TrackingConfig struct {
+	BufferSize           int `json:"buffer_size"`
+	BatchSize            int `json:"batch_size"`
+	FlushIntervalSeconds int `json:"flush_interval_seconds"`
+}
+
+type InventoryConfig struct {
+	ReconcileIntervalMinutes int `json:"reconcile_interval_minutes"`
+	AlertScanIntervalSeconds int `json:"alert_scan_interval_seconds"`
+}
+
 type AppConfig struct {
 	RateLimit RateLimitConfig `json:"rate_limit"`
+	Checkout  CheckoutConfig  `json:"checkout"`
+	Tracking  TrackingConfig  `json:"tracking"`
+	Inventory InventoryConfig `json:"inventory"`
 }
 
 func LoadAppConfig(path string) (*AppConfig, error) {
@@ -40,9 +59,41 @@ func LoadAppConfig(path string) (*AppConfig, error) {
 		return nil, fmt.Errorf("invalid rate limit config")
 	}
 
+	if cfg.Checkout.ReservationMinutes <= 0 || cfg.Checkout.SweepIntervalSeconds <= 0 {
+		return nil, fmt.Errorf("invalid checkout config")
+	}
+
+	if cfg.Tracking.BufferSize <= 0 || cfg.Tracking.BatchSize <= 0 || cfg.Tracking.FlushIntervalSeconds <= 0 {
+		return nil, fmt.Errorf("invalid tracking config")
+	}
+
+	if cfg.Inventory.ReconcileIntervalMinutes <= 0 || cfg.Inventory.AlertScanIntervalSeconds <= 0 {
+		return nil, fmt.Errorf("invalid inventory config")
+	}
+
 	return &cfg, nil
 }
 
 func (r RateLimitConfig) CleanupInterval() time.Duration {
 	return time.Duration(r.CleanupIntervalMinutes) * time.Minute
 }
+
+func (c CheckoutConfig) ReservationTTL() time.Duration {
+	return time.Duration(c.ReservationMinutes) * time.Minute
+}
+
+func (c CheckoutConfig) SweepInterval() time.Duration {
+	return time.Duration(c.SweepIntervalSeconds) * time.Second
+}
+
+func (t TrackingConfig) FlushInterval() time.Duration {
+	return time.Duration(t.FlushIntervalSeconds) * time.Second
+}
+
+func (i InventoryConfig) ReconcileInterval() time.Duration {
+	return time.Duration(i.ReconcileIntervalMinutes) * time.Minute
+}
+
+func (i InventoryConfig) AlertScanInterval() time.Duration {
+	return time.Duration(i.AlertScanIntervalSeconds) * time.Second
+}
@@ -18,6 +18,9 @@ type Secret struct {
 	MinIOUser     string
 	MinIOPass     string
 	MinIOBucket   string
+
+	// optional
+	PaymentWebhookSecret string
 }
 
 func LoadSecrets() (*Secret, error) {
@@ -63,5 +66,7 @@ func LoadSecrets() (*Secret, error) {
 		MinIOUser:     values["MINIO_USER"],
 		MinIOPass:     values["MINIO_PASS"],
 		MinIOBucket:   values["MINIO_BUCKET"],
+
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
 	}, nil
 }
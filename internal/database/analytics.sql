@@ -1,26 +1,26 @@
 -- name: GetTotalRevenueCurrentMonth :one
 -- In Overview and Analytics pages
 
-SELECT COALESCE(SUM(co.total_amount), 0) AS total_revenue
+SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS total_revenue
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'completed'
+  AND co.status IN ('completed', 'shipped', 'delivered')
   AND co.created_at >= $2
   AND co.created_at < $3;
 
 -- name: GetRevenueGrowthPercent :one
 WITH current_period AS
-  (SELECT COALESCE(SUM(co.total_amount), 0) AS revenue
+  (SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS revenue
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= $2
      AND co.created_at < $3),
      previous_period AS
-  (SELECT COALESCE(SUM(co.total_amount), 0) AS revenue
+  (SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS revenue
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= ($2 - ($3 - $2))
      AND co.created_at < $2)
 SELECT CASE
@@ -44,14 +44,14 @@ WITH current_period AS
   (SELECT COALESCE(COUNT(*), 0) AS orders_count
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= $2
      AND co.created_at < $3),
      previous_period AS
   (SELECT COALESCE(COUNT(*), 0) AS orders_count
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= ($2 - ($3 - $2))
      AND co.created_at < $2)
 SELECT CASE
@@ -62,41 +62,52 @@ FROM current_period,
      previous_period;
 
 -- name: GetTotalOrders :one
--- Orders page
+-- Orders page; the status counters cover orders placed in the period
 
 SELECT COUNT(*) AS total_orders
 FROM customer_order co
-WHERE co.store_id = $1;
+WHERE co.store_id = $1
+  AND co.created_at >= $2
+  AND co.created_at < $3;
 
 -- name: GetPendingOrders :one
 
 SELECT COUNT(*) AS pending_orders
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'pending';
+  AND co.status = 'pending'
+  AND co.created_at >= $2
+  AND co.created_at < $3;
 
 -- name: GetShippedOrders :one
 
 SELECT COUNT(*) AS shipped_orders
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'shipped';
+  AND co.status = 'shipped'
+  AND co.created_at >= $2
+  AND co.created_at < $3;
 
 -- name: GetCompletedOrders :one
 
 SELECT COUNT(*) AS completed_orders
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'completed';
+  AND co.status = 'completed'
+  AND co.created_at >= $2
+  AND co.created_at < $3;
 
 -- name: GetAverageDeliveryDays :one
+-- Shipments delivered in the period
 
-SELECT ROUND(AVG(EXTRACT(EPOCH
-                         FROM (s.delivered_at - s.shipped_at))/86400), 2) AS avg_delivery_days
+SELECT COALESCE(ROUND(AVG(EXTRACT(EPOCH
+                                  FROM (s.delivered_at - s.shipped_at))/86400), 2), 0)::NUMERIC AS avg_delivery_days
 FROM shipment s
 JOIN customer_order o ON o.order_id = s.order_id
 WHERE o.store_id = $1
-  AND s.delivered_at IS NOT NULL;
+  AND s.shipped_at IS NOT NULL
+  AND s.delivered_at >= $2
+  AND s.delivered_at < $3;
 
 -- name: GetTotalVisitors :one
 -- Total visitors(Overview page) / Visitors(Analytics page)
@@ -113,14 +124,15 @@ SELECT COUNT(DISTINCT vs.session_id) AS new_visitors
 FROM visitor_session vs
 WHERE vs.store_id = $1
   AND vs.first_seen_at >= $2
-  AND vs.first_seen_at < $3;
+  AND vs.first_seen_at < $3
+  AND NOT COALESCE(vs.is_returning, FALSE);
 
 -- name: GetPageViews :one
 
 SELECT COUNT(pv.product_view_id) AS total_page_views
 FROM product_view pv
 WHERE pv.store_id = $1
-  AND pv.viewed_at BETWEEN $2 AND $3;
+  AND pv.viewed_at >= $2 AND pv.viewed_at < $3;
 
 -- name: GetRegisteredCustomers :one
 -- Overview & Customers page
@@ -155,7 +167,7 @@ FROM current_period,
 SELECT COUNT(DISTINCT co.customer_id) AS purchasing_customers
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'completed'
+  AND co.status IN ('completed', 'shipped', 'delivered')
   AND co.customer_id IS NOT NULL;
 
 -- name: GetNewRegisteredCustomers :one
@@ -223,13 +235,14 @@ SELECT p.name,
        p.stock_quantity,
        CASE
            WHEN p.stock_quantity = 0 THEN 'OUT_OF_STOCK'
-           WHEN p.stock_quantity BETWEEN 1 AND 10 THEN 'LOW_STOCK'
+           WHEN p.stock_quantity <= s.low_stock_threshold THEN 'LOW_STOCK'
        END AS stock_status,
        p.updated_at
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
-  AND p.stock_quantity <= 10
+  AND p.stock_quantity <= s.low_stock_threshold
 ORDER BY p.stock_quantity ASC,
          p.updated_at DESC
 LIMIT $2;
@@ -245,9 +258,10 @@ WHERE p.store_id = $1
 
 SELECT COUNT(*) AS low_out_stock_products
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
-  AND p.stock_quantity <= 10;
+  AND p.stock_quantity <= s.low_stock_threshold;
 
 -- name: ListProductTable :many
 
@@ -260,10 +274,11 @@ SELECT p.name AS product_name,
        END AS views_to_purchase_ratio,
        CASE
            WHEN COALESCE(SUM(pv.stock_quantity), 0) = 0 THEN 'out of stock'
-           WHEN COALESCE(SUM(pv.stock_quantity), 0) <= 10 THEN 'low stock'
+           WHEN COALESCE(SUM(pv.stock_quantity), 0) <= s.low_stock_threshold THEN 'low stock'
            ELSE 'in stock'
        END AS stock_status
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 LEFT JOIN product_variant pv ON pv.product_id = p.product_id
 LEFT JOIN order_item oi ON oi.variant_id = pv.variant_id
 LEFT JOIN customer_order o ON o.order_id = oi.order_id
@@ -277,42 +292,46 @@ LEFT JOIN
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
 GROUP BY p.product_id,
-         p.name
+         p.name,
+         s.low_stock_threshold
 ORDER BY p.name ASC;
 
 -- name: GetConversionRate :one
 
-SELECT COUNT(DISTINCT co.order_id)::FLOAT / NULLIF(COUNT(DISTINCT vs.session_id), 0) AS conversion_rate
+SELECT COALESCE(COUNT(DISTINCT co.order_id)::FLOAT / NULLIF(COUNT(DISTINCT vs.session_id), 0), 0)::FLOAT AS conversion_rate
 FROM visitor_session vs
 LEFT JOIN customer_order co ON vs.customer_id = co.customer_id
 AND co.store_id = $1
-AND co.status = 'completed'
-AND co.created_at BETWEEN $2 AND $3
+AND co.status IN ('completed', 'shipped', 'delivered')
+AND co.created_at >= $2 AND co.created_at < $3
 WHERE vs.store_id = $1
-  AND vs.first_seen_at BETWEEN $2 AND $3;
+  AND vs.first_seen_at >= $2 AND vs.first_seen_at < $3;
 
 -- name: GetRevenueOverTime :many
-
-SELECT DATE(co.created_at) AS order_date,
-       SUM(co.total_amount) AS revenue,
+-- Days are calendar days in the given timezone, the store's.
+SELECT DATE(co.created_at AT TIME ZONE @timezone::TEXT) AS order_date,
+       SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded'))::NUMERIC AS revenue,
        COUNT(DISTINCT co.order_id) AS total_orders
 FROM customer_order co
-WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
-GROUP BY DATE(co.created_at)
+WHERE co.store_id = @store_id
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= @from_time
+  AND co.created_at < @to_time
+GROUP BY order_date
 ORDER BY order_date;
 
 -- name: GetTopSellingProducts :many
 
 SELECT p.name AS product_name,
-       SUM(oi.quantity) AS units_sold,
-       SUM(oi.subtotal) AS revenue
+       SUM(oi.quantity - oi.refunded_quantity) AS units_sold,
+       SUM(oi.subtotal - oi.unit_price * oi.refunded_quantity)::NUMERIC AS revenue
 FROM order_item oi
 JOIN product_variant pv ON oi.variant_id = pv.variant_id
 JOIN product p ON pv.product_id = p.product_id
 JOIN customer_order co ON oi.order_id = co.order_id
 WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= $2 AND co.created_at < $3
 GROUP BY p.product_id,
          p.name
 ORDER BY revenue DESC
@@ -321,12 +340,13 @@ LIMIT 5;
 -- name: GetLoyalCustomers :many
 
 SELECT c.name AS customer_name,
-       SUM(co.total_amount) AS total_spent,
+       SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded'))::NUMERIC AS total_spent,
        COUNT(co.order_id) AS orders_count
 FROM customer c
 JOIN customer_order co ON c.customer_id = co.customer_id
 WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= $2 AND co.created_at < $3
 GROUP BY c.customer_id,
          c.name
 ORDER BY orders_count DESC
@@ -335,13 +355,13 @@ LIMIT 5;
 -- name: GetProductsNeedingAttention :many
 WITH product_sales AS
   (SELECT pv.product_id,
-          COALESCE(SUM(oi.quantity), 0) AS units_sold,
-          COALESCE(SUM(oi.subtotal), 0) AS revenue
+          COALESCE(SUM(oi.quantity - oi.refunded_quantity), 0) AS units_sold,
+          COALESCE(SUM(oi.subtotal - oi.unit_price * oi.refunded_quantity), 0) AS revenue
    FROM product_variant pv
    LEFT JOIN order_item oi ON pv.variant_id = oi.variant_id
    LEFT JOIN customer_order co ON oi.order_id = co.order_id
    AND co.store_id = $1
-   AND co.created_at BETWEEN $2 AND $3
+   AND co.created_at >= $2 AND co.created_at < $3
    WHERE pv.store_id = $1
    GROUP BY pv.product_id)
 SELECT p.name AS product_name,
@@ -361,7 +381,7 @@ WITH views AS (
         COUNT(*) AS views
     FROM product_view pv
     WHERE pv.store_id = $1
-      AND pv.viewed_at BETWEEN $2 AND $3
+      AND pv.viewed_at >= $2 AND pv.viewed_at < $3
     GROUP BY pv.product_id
 ),
 sales AS (
@@ -374,8 +394,8 @@ sales AS (
     JOIN customer_order
       ON customer_order.order_id = order_item.order_id
     WHERE product_variant.store_id = $1
-      AND customer_order.status = 'completed'
-      AND customer_order.created_at BETWEEN $2 AND $3
+      AND customer_order.status IN ('completed', 'shipped', 'delivered')
+      AND customer_order.created_at >= $2 AND customer_order.created_at < $3
     GROUP BY product_variant.product_id
 )
 SELECT
@@ -393,7 +413,7 @@ WITH visits AS
   (SELECT vs.session_id
    FROM visitor_session vs
    WHERE vs.store_id = $1
-     AND vs.first_seen_at BETWEEN $2 AND $3),
+     AND vs.first_seen_at >= $2 AND vs.first_seen_at < $3),
      product_views AS
   (SELECT DISTINCT pv.session_id
    FROM product_view pv
@@ -407,13 +427,13 @@ WITH visits AS
   (SELECT DISTINCT co.session_id
    FROM customer_order co
    JOIN added_to_cart ac ON ac.session_id = co.session_id
-   WHERE co.created_at BETWEEN $2 AND $3),
+   WHERE co.created_at >= $2 AND co.created_at < $3),
      purchase_complete AS
   (SELECT DISTINCT co.session_id
    FROM customer_order co
    JOIN checkout_started cs ON cs.session_id = co.session_id
-   WHERE co.status = 'completed'
-     AND co.created_at BETWEEN $2 AND $3)
+   WHERE co.status IN ('completed', 'shipped', 'delivered')
+     AND co.created_at >= $2 AND co.created_at < $3)
 SELECT
   (SELECT COUNT(*)
    FROM visits) AS site_visits,
@@ -429,46 +449,46 @@ SELECT
 
   (SELECT COUNT(*)
    FROM purchase_complete) AS purchase_complete,
-       ROUND((
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM visits) -
                 (SELECT COUNT(*)
                  FROM product_views))::numeric / NULLIF(
                                                           (SELECT COUNT(*)
-                                                           FROM visits),0) * 100, 2) AS drop_site_to_view_pct,
-       ROUND((
+                                                           FROM visits),0) * 100, 2), 0)::NUMERIC AS drop_site_to_view_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM product_views) -
                 (SELECT COUNT(*)
                  FROM added_to_cart))::numeric / NULLIF(
                                                           (SELECT COUNT(*)
-                                                           FROM product_views),0) * 100, 2) AS drop_view_to_cart_pct,
-       ROUND((
+                                                           FROM product_views),0) * 100, 2), 0)::NUMERIC AS drop_view_to_cart_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM added_to_cart) -
                 (SELECT COUNT(*)
                  FROM checkout_started))::numeric / NULLIF(
                                                              (SELECT COUNT(*)
-                                                              FROM added_to_cart),0) * 100, 2) AS drop_cart_to_checkout_pct,
-       ROUND((
+                                                              FROM added_to_cart),0) * 100, 2), 0)::NUMERIC AS drop_cart_to_checkout_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM checkout_started) -
                 (SELECT COUNT(*)
                  FROM purchase_complete))::numeric / NULLIF(
                                                               (SELECT COUNT(*)
-                                                               FROM checkout_started),0) * 100, 2) AS drop_checkout_to_purchase_pct;
+                                                               FROM checkout_started),0) * 100, 2), 0)::NUMERIC AS drop_checkout_to_purchase_pct;
 
 -- name: GetConversionOverTime :many
-
-SELECT DATE(vs.first_seen_at) AS DAY,
+-- Days are calendar days in the given timezone, the store's.
+SELECT DATE(vs.first_seen_at AT TIME ZONE @timezone::TEXT) AS DAY,
        ROUND(COUNT(DISTINCT co.session_id)::numeric / NULLIF(COUNT(DISTINCT vs.session_id), 0) * 100, 2) AS conversion_rate_percent
 FROM visitor_session vs
 LEFT JOIN customer_order co ON vs.session_id = co.session_id
-AND co.created_at >= $2
-AND co.created_at < $3
-AND co.status = 'completed'
-WHERE vs.store_id = $1
-  AND vs.first_seen_at >= $2
-  AND vs.first_seen_at < $3
+AND co.created_at >= @from_time
+AND co.created_at < @to_time
+AND co.status IN ('completed', 'shipped', 'delivered')
+WHERE vs.store_id = @store_id
+  AND vs.first_seen_at >= @from_time
+  AND vs.first_seen_at < @to_time
 GROUP BY DAY
 ORDER BY DAY;
\ No newline at end of file
@@ -27,6 +27,14 @@ func (d *DB) QueryContext(
 	return d.db.QueryContext(ctx, query, args...)
 }
 
+func (d *DB) QueryRowContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) *sql.Row {
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
 func (d *DB) RunInTx(ctx context.Context, fn func(q *models.Queries) error) error {
 	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -2,15 +2,50 @@ package database
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode"
+)
 
-	"github.com/Secure-Website-Builder/Backend/internal/utils"
+// Attribute data types, see attribute_definition.data_type.
+const (
+	AttributeText    = "text"
+	AttributeNumeric = "numeric"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
 )
 
-// AttributeFilter: attribute_id and desired value
+// NumericValuePattern is the shape of a valid numeric attribute value. Range
+// filters only cast values of this shape, so stray text never breaks a query.
+const NumericValuePattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// NumericValue matches NumericValuePattern.
+var NumericValue = regexp.MustCompile(NumericValuePattern)
+
+// ValidateAttributeValue checks a value against its attribute's data type.
+func ValidateAttributeValue(dataType, value string) error {
+	switch dataType {
+	case AttributeNumeric:
+		if !NumericValue.MatchString(value) {
+			return fmt.Errorf("value %q is not a number", value)
+		}
+	case AttributeBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value %q is not true or false", value)
+		}
+	}
+	return nil
+}
+
+// AttributeFilter: attribute_id and desired values and/or numeric range; a
+// value matching any of Values or falling in the range passes
 type AttributeFilter struct {
 	AttributeID int64
-	Values      []string
+	Values      []string // value IN (...)
+	Numeric     bool     // compare Values as numbers, so "13" matches "13.0"
+	Min         *float64 // inclusive bounds, numeric attributes only
+	Max         *float64
+	AnyVariant  bool // match any live in-stock variant, not only the default one
 }
 
 // BuildAttributeFilterSQL builds multi-JOINs for each attribute filter
@@ -24,30 +59,96 @@ func BuildAttributeFilterSQL(filters []AttributeFilter, startIndex int) (string,
 	args := make([]interface{}, 0)
 	paramIndex := startIndex
 
+	next := func(arg interface{}) string {
+		args = append(args, arg)
+		paramIndex++
+		return fmt.Sprintf("$%d", paramIndex-1)
+	}
+
 	for i, f := range filters {
 		alias := fmt.Sprintf("vav%d", i)
 
-		// attribute_id placeholder
-		attrParam := paramIndex
-		paramIndex++
+		conds := []string{fmt.Sprintf("%s.attribute_id = %s", alias, next(f.AttributeID))}
+
+		numeric := fmt.Sprintf("(CASE WHEN %s.value ~ '%s' THEN %s.value::NUMERIC END)", alias, NumericValuePattern, alias)
+		var matches []string
 
-		// value placeholders
-		placeholders := make([]string, 0, len(f.Values))
-		for range f.Values {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", paramIndex))
-			paramIndex++
+		if len(f.Values) > 0 {
+			placeholders := make([]string, 0, len(f.Values))
+			for _, v := range f.Values {
+				if f.Numeric {
+					placeholders = append(placeholders, next(v)+"::NUMERIC")
+				} else {
+					placeholders = append(placeholders, next(v))
+				}
+			}
+			if f.Numeric {
+				matches = append(matches, fmt.Sprintf("%s IN (%s)", numeric, strings.Join(placeholders, ", ")))
+			} else {
+				matches = append(matches, fmt.Sprintf("%s.value IN (%s)", alias, strings.Join(placeholders, ", ")))
+			}
 		}
 
-		sb.WriteString(fmt.Sprintf(`
+		var bounds []string
+		if f.Min != nil {
+			bounds = append(bounds, fmt.Sprintf("%s >= %s", numeric, next(*f.Min)))
+		}
+		if f.Max != nil {
+			bounds = append(bounds, fmt.Sprintf("%s <= %s", numeric, next(*f.Max)))
+		}
+		if len(bounds) > 0 {
+			matches = append(matches, "("+strings.Join(bounds, " AND ")+")")
+		}
+
+		if len(matches) > 0 {
+			conds = append(conds, "("+strings.Join(matches, " OR ")+")")
+		}
+
+		if !f.AnyVariant {
+			sb.WriteString(fmt.Sprintf(`
 JOIN variant_attribute_value %s
   ON %s.variant_id = p.default_variant_id
- AND %s.attribute_id = $%d
- AND %s.value IN (%s)
-`, alias, alias, alias, attrParam, alias, strings.Join(placeholders, ", ")))
+ AND %s
+`, alias, alias, strings.Join(conds, "\n AND ")))
+			continue
+		}
 
-		args = append(args, f.AttributeID)
-		args = append(args, utils.InterfaceSlice(f.Values)...)
+		// LATERAL ... LIMIT 1 keeps one row per product however many
+		// variants match
+		sb.WriteString(fmt.Sprintf(`
+JOIN LATERAL (
+  SELECT 1
+  FROM product_variant v%d
+  JOIN variant_attribute_value %s
+    ON %s.variant_id = v%d.variant_id
+  WHERE v%d.product_id = p.product_id
+    AND v%d.deleted_at IS NULL
+    AND v%d.stock_quantity > 0
+    AND %s
+  LIMIT 1
+) m%d ON TRUE
+`, i, alias, alias, i, i, i, i, strings.Join(conds, "\n    AND "), i))
 	}
 
 	return sb.String(), args
 }
+
+// BuildSearchQuery turns free text into a to_tsquery expression where every
+// word must match as a prefix ("red sh" -> "red:* & sh:*"), so results narrow
+// as the user types. Anything but letters and digits separates words, which
+// keeps tsquery operators out of user input. It returns nil when q has no words.
+func BuildSearchQuery(q string) *string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	query := strings.Join(terms, " & ")
+	return &query
+}
@@ -1,4 +1,6 @@
 -- ListProductsBase template: the service will replace the placeholders.
+-- SORT_KEY is the sort column; together with product_id it forms the keyset
+-- cursor, and CURSOR resumes strictly after the previous page's last row.
 SELECT
   p.product_id,
   p.name,
@@ -10,19 +12,36 @@ SELECT
   pv.stock_quantity AS item_stock,
   pv.price,
   pv.primary_image_url,
-  p.in_stock
+  p.in_stock,
+  (/*{{SORT_KEY}}*/)::TEXT AS sort_key
 FROM product p
 JOIN product_variant pv
   ON pv.variant_id = p.default_variant_id
+LEFT JOIN product_search ps
+  ON ps.product_id = p.product_id
+
+/*{{SORT_JOINS}}*/
 
 /*{{DYNAMIC_JOINS}}*/
 
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
-  AND ($4::BIGINT IS NULL OR p.category_id = $4)
-  AND ($5::TEXT IS NULL OR p.brand = $5)
-  AND ($6::DECIMAL IS NULL OR pv.price >= $6)
-  AND ($7::DECIMAL IS NULL OR pv.price <= $7)
-  AND ($8::BOOLEAN IS NULL OR p.in_stock = $8)
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3;
+  -- a category matches its products and those of all its subcategories
+  AND ($3::BIGINT IS NULL OR p.category_id IN (
+    WITH RECURSIVE sub AS (
+      SELECT $3::BIGINT AS category_id
+      UNION ALL
+      SELECT c.category_id
+      FROM category_definition c
+      JOIN sub ON c.parent_id = sub.category_id
+    )
+    SELECT category_id FROM sub
+  ))
+  AND ($4::TEXT IS NULL OR p.brand = $4)
+  AND ($5::DECIMAL IS NULL OR pv.price >= $5)
+  AND ($6::DECIMAL IS NULL OR pv.price <= $6)
+  AND ($7::BOOLEAN IS NULL OR p.in_stock = $7)
+  AND ($8::TEXT IS NULL OR ps.document @@ to_tsquery('simple', $8))
+  /*{{CURSOR}}*/
+ORDER BY /*{{ORDER_BY}}*/
+LIMIT $2;
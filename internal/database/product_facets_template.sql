@@ -0,0 +1,34 @@
+-- ProductFacets template: the products matching the listing filters, wrapped
+-- by the listing's total count and by each facet query. The service replaces
+-- the placeholders and passes NULL for the filter a facet counts over.
+SELECT
+  p.product_id,
+  p.brand,
+  pv.price,
+  p.default_variant_id
+FROM product p
+JOIN product_variant pv
+  ON pv.variant_id = p.default_variant_id
+LEFT JOIN product_search ps
+  ON ps.product_id = p.product_id
+
+/*{{DYNAMIC_JOINS}}*/
+
+WHERE p.store_id = $1
+  AND p.deleted_at IS NULL
+  -- a category matches its products and those of all its subcategories
+  AND ($2::BIGINT IS NULL OR p.category_id IN (
+    WITH RECURSIVE sub AS (
+      SELECT $2::BIGINT AS category_id
+      UNION ALL
+      SELECT c.category_id
+      FROM category_definition c
+      JOIN sub ON c.parent_id = sub.category_id
+    )
+    SELECT category_id FROM sub
+  ))
+  AND ($3::TEXT IS NULL OR p.brand = $3)
+  AND ($4::DECIMAL IS NULL OR pv.price >= $4)
+  AND ($5::DECIMAL IS NULL OR pv.price <= $5)
+  AND ($6::BOOLEAN IS NULL OR p.in_stock = $6)
+  AND ($7::TEXT IS NULL OR ps.document @@ to_tsquery('simple', $7))
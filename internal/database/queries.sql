@@ -6,7 +6,7 @@ JOIN category_definition c
 JOIN category_definition pc
   ON c.parent_id = pc.category_id
 WHERE s.store_id = $1
-ORDER BY c.name;
+ORDER BY s.position, c.name;
 
 -- name: GetProductBase :one
 SELECT
@@ -88,10 +88,18 @@ WHERE product_id = $1
 FOR UPDATE;
 
 
+-- name: GetAttributeByName :one
+-- A global attribute or one of the store's private ones.
+SELECT *
+FROM attribute_definition
+WHERE name = $1
+  AND (store_id IS NULL OR store_id = $2);
+
 -- name: ResolveAttributeIDByName :one
 SELECT attribute_id
 FROM attribute_definition
 WHERE name = $1
+  AND (store_id IS NULL OR store_id = $2)
 LIMIT 1;
 
 -- name: ResolveCategoryIDByName :one
@@ -119,11 +127,13 @@ SELECT
   ci.quantity AS cart_quantity,
   ci.unit_price,
   v.stock_quantity AS available_stock,
-  (v.unit_price * ci.quantity)::NUMERIC AS subtotal
+  (ci.unit_price * ci.quantity)::NUMERIC AS subtotal,
+  (v.deleted_at IS NULL AND p.deleted_at IS NULL) AS purchasable
 FROM cart_item ci
 JOIN product_variant v ON v.variant_id = ci.variant_id
+JOIN product p ON p.product_id = v.product_id
 WHERE ci.cart_id = $1
-FOR UPDATE;
+FOR UPDATE OF ci, v;
 
 -- name: GetCartTotal :one
 SELECT
@@ -158,9 +168,11 @@ INSERT INTO customer_order (
   store_id,
   customer_id,
   session_id,
-  total_amount
+  total_amount,
+  email,
+  shipping_address
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
@@ -181,7 +193,7 @@ SET status = $2,
     updated_at = NOW()
 WHERE order_id = $1;
 
--- name: CreatePayment :exec
+-- name: CreatePayment :one
 INSERT INTO payment (
   order_id,
   method,
@@ -190,20 +202,59 @@ INSERT INTO payment (
   transaction_ref
 ) VALUES (
   $1, $2, $3, $4, $5
-);
+)
+RETURNING *;
 
+-- name: UpdatePaymentStatus :exec
+UPDATE payment
+SET status = $2,
+    transaction_ref = COALESCE(sqlc.narg('transaction_ref'), transaction_ref)
+WHERE payment_id = $1;
+
+-- name: GetPaymentByTransactionRef :one
+SELECT *
+FROM payment
+WHERE method = $1 AND transaction_ref = $2
+ORDER BY payment_id
+LIMIT 1;
 
--- name: DecreaseVariantStock :exec
-UPDATE product_variant
-SET stock_quantity = stock_quantity - @cart_quantity,
-    updated_at = NOW()
-WHERE variant_id = $1;
 
 -- name: GetSession :one
 SELECT session_id, customer_id
 FROM visitor_session
 WHERE session_id = $1 AND store_id = $2;
 
+-- name: GetVisitorSession :one
+SELECT *
+FROM visitor_session
+WHERE session_id = $1 AND store_id = $2;
+
+-- name: CreateVisitorSession :one
+INSERT INTO visitor_session (store_id, customer_id, ip_address, user_agent)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: TouchVisitorSession :one
+-- A session seen again after visit_started_before starts a new visit,
+-- which makes the visitor a returning one.
+UPDATE visitor_session
+SET last_seen_at = NOW(),
+    is_returning = COALESCE(is_returning, FALSE) OR last_seen_at < sqlc.arg('visit_started_before'),
+    ip_address = COALESCE(sqlc.narg('ip_address'), ip_address),
+    user_agent = COALESCE(sqlc.narg('user_agent'), user_agent),
+    customer_id = COALESCE(customer_id, sqlc.narg('customer_id'))
+WHERE session_id = sqlc.arg('session_id') AND store_id = sqlc.arg('store_id')
+RETURNING *;
+
+-- name: AttachSessionCustomer :one
+UPDATE visitor_session
+SET customer_id = $1,
+    last_seen_at = NOW()
+WHERE session_id = $2
+  AND store_id = $3
+  AND (customer_id IS NULL OR customer_id = $1)
+RETURNING session_id;
+
 -- name: GetCartForSession :one
 SELECT *
 FROM cart
@@ -241,12 +292,19 @@ RETURNING *;
 -- name: GetVariantForCart :one
 SELECT
   variant_id,
+  product_id,
   price,
   stock_quantity
 FROM product_variant
 WHERE variant_id = $1
   AND store_id = $2
   AND deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM product p
+    WHERE p.product_id = product_variant.product_id
+      AND p.deleted_at IS NULL
+  )
 FOR UPDATE;
 
 -- name: UpsertCartItem :exec
@@ -315,7 +373,7 @@ WHERE email = $1
   AND store_id = $2;
 
 -- name: ListCategoryAttributes :many
-SELECT a.attribute_id, a.name, ca.is_required
+SELECT a.attribute_id, a.name, a.data_type, ca.is_required, ca.allowed_values
 FROM category_attribute ca
 JOIN attribute_definition a 
 ON a.attribute_id = ca.attribute_id
@@ -348,9 +406,9 @@ LIMIT 1;
 
 -- name: CreateProduct :one
 INSERT INTO product (
-  store_id, category_id, name, slug, description, brand
+  store_id, category_id, name, slug, description, brand, in_stock
 )
-VALUES ($1, $2, $3, $4, $5, $6)
+VALUES ($1, $2, $3, $4, $5, $6, FALSE)
 RETURNING *;
 
 -- name: UpdateProductStock :exec
@@ -371,7 +429,8 @@ FROM product_variant
 WHERE product_id = $1
   AND attribute_hash = $2
   AND deleted_at IS NULL
-LIMIT 1;
+LIMIT 1
+FOR UPDATE;
 
 -- name: GetVariant :one
 SELECT *
@@ -394,12 +453,6 @@ INSERT INTO product_variant (
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
--- name: IncreaseVariantStock :exec
-UPDATE product_variant
-SET stock_quantity = stock_quantity + $2,
-    updated_at = NOW()
-WHERE variant_id = $1;
-
 -- name: InsertVariantAttribute :exec
 INSERT INTO variant_attribute_value (
   variant_id, attribute_id, value
@@ -412,7 +465,7 @@ FROM product
 WHERE store_id = $1
   AND name = $2
   AND category_id = $3
-  AND brand = $4
+  AND brand IS NOT DISTINCT FROM $4
   AND deleted_at IS NULL
 LIMIT 1;
 
@@ -496,3 +549,831 @@ WHERE src.cart_id = @from_cart_id
     FROM updated u
     WHERE u.cart_item_id = src.cart_item_id
   );
+
+-- name: ListStoreOrders :many
+SELECT
+  co.order_id,
+  co.customer_id,
+  c.name AS customer_name,
+  c.email AS customer_email,
+  co.total_amount,
+  co.status,
+  co.created_at,
+  co.updated_at
+FROM customer_order co
+LEFT JOIN customer c
+  ON c.customer_id = co.customer_id
+WHERE co.store_id = @store_id
+  AND (sqlc.narg('status')::TEXT IS NULL OR co.status = sqlc.narg('status'))
+  AND (sqlc.narg('customer_id')::BIGINT IS NULL OR co.customer_id = sqlc.narg('customer_id'))
+  AND (sqlc.narg('created_from')::TIMESTAMPTZ IS NULL OR co.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::TIMESTAMPTZ IS NULL OR co.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('min_amount')::NUMERIC IS NULL OR co.total_amount >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::NUMERIC IS NULL OR co.total_amount <= sqlc.narg('max_amount'))
+ORDER BY co.created_at DESC, co.order_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountStoreOrders :one
+SELECT COUNT(*)
+FROM customer_order co
+WHERE co.store_id = @store_id
+  AND (sqlc.narg('status')::TEXT IS NULL OR co.status = sqlc.narg('status'))
+  AND (sqlc.narg('customer_id')::BIGINT IS NULL OR co.customer_id = sqlc.narg('customer_id'))
+  AND (sqlc.narg('created_from')::TIMESTAMPTZ IS NULL OR co.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::TIMESTAMPTZ IS NULL OR co.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('min_amount')::NUMERIC IS NULL OR co.total_amount >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::NUMERIC IS NULL OR co.total_amount <= sqlc.narg('max_amount'));
+
+-- name: GetStoreOrder :one
+SELECT *
+FROM customer_order
+WHERE store_id = $1 AND order_id = $2;
+
+-- name: GetStoreOrderForUpdate :one
+SELECT *
+FROM customer_order
+WHERE store_id = $1 AND order_id = $2
+FOR UPDATE;
+
+-- name: ListOrderItems :many
+SELECT
+  oi.order_item_id,
+  oi.variant_id,
+  p.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.primary_image_url,
+  oi.quantity,
+  oi.unit_price,
+  oi.subtotal,
+  oi.refunded_quantity
+FROM order_item oi
+JOIN product_variant v ON v.variant_id = oi.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE oi.order_id = $1
+ORDER BY oi.order_item_id;
+
+-- name: ListOrderPayments :many
+SELECT *
+FROM payment
+WHERE order_id = $1
+ORDER BY created_at, payment_id;
+
+-- name: ListOrderShipments :many
+SELECT *
+FROM shipment
+WHERE order_id = $1
+ORDER BY shipment_id;
+
+-- name: ListCustomerOrders :many
+SELECT
+  order_id,
+  total_amount,
+  status,
+  created_at,
+  updated_at
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2
+ORDER BY created_at DESC, order_id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: CountCustomerOrders :one
+SELECT COUNT(*)
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2;
+
+-- name: GetCustomerOrder :one
+SELECT *
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2 AND order_id = $3;
+
+-- name: CreateStockReservation :exec
+INSERT INTO stock_reservation (
+  order_id, variant_id, quantity, expires_at
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: ListActiveReservations :many
+SELECT *
+FROM stock_reservation
+WHERE order_id = $1 AND status = 'active'
+FOR UPDATE;
+
+-- name: SetOrderReservationsStatus :exec
+UPDATE stock_reservation
+SET status = $2
+WHERE order_id = $1 AND status = 'active';
+
+-- name: ListExpiredReservationOrders :many
+SELECT DISTINCT r.order_id
+FROM stock_reservation r
+JOIN customer_order o ON o.order_id = r.order_id
+WHERE r.status = 'active'
+  AND r.expires_at <= $1
+  AND o.status = 'pending'
+LIMIT $2;
+
+-- name: GetOrderForUpdate :one
+SELECT *
+FROM customer_order
+WHERE order_id = $1
+FOR UPDATE;
+
+-- name: RefundOrderItem :one
+UPDATE order_item
+SET refunded_quantity = refunded_quantity + @quantity::INT
+WHERE order_item_id = @order_item_id
+  AND order_id = @order_id
+  AND refunded_quantity + @quantity::INT <= quantity
+RETURNING variant_id, (unit_price * @quantity::INT)::NUMERIC AS amount;
+
+-- name: RevertOrderItemRefund :exec
+UPDATE order_item
+SET refunded_quantity = refunded_quantity - $2
+WHERE order_item_id = $1;
+
+-- name: CountUnrefundedOrderItems :one
+SELECT COUNT(*)
+FROM order_item
+WHERE order_id = $1
+  AND refunded_quantity < quantity;
+
+-- name: CreateShipment :one
+INSERT INTO shipment (
+  order_id, carrier, tracking_number
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: AddShipmentItem :exec
+INSERT INTO shipment_item (
+  shipment_id, order_item_id, quantity
+) VALUES (
+  $1, $2, $3
+);
+
+-- name: ListUnshippedOrderItems :many
+SELECT
+  oi.order_item_id,
+  (oi.quantity - oi.refunded_quantity - COALESCE(SUM(si.quantity), 0))::INT AS unshipped
+FROM order_item oi
+LEFT JOIN shipment_item si ON si.order_item_id = oi.order_item_id
+WHERE oi.order_id = $1
+GROUP BY oi.order_item_id
+ORDER BY oi.order_item_id;
+
+-- name: ListOrderShipmentItems :many
+SELECT si.*
+FROM shipment_item si
+JOIN shipment s ON s.shipment_id = si.shipment_id
+WHERE s.order_id = $1
+ORDER BY si.shipment_id, si.order_item_id;
+
+-- name: GetShipmentForUpdate :one
+SELECT *
+FROM shipment
+WHERE shipment_id = $1 AND order_id = $2
+FOR UPDATE;
+
+-- name: MarkShipmentShipped :exec
+UPDATE shipment
+SET status = 'shipped',
+    shipped_at = NOW()
+WHERE shipment_id = $1;
+
+-- name: MarkShipmentDelivered :exec
+UPDATE shipment
+SET status = 'delivered',
+    delivered_at = NOW()
+WHERE shipment_id = $1;
+
+-- name: GetOrderShipmentProgress :one
+SELECT
+  (SELECT COALESCE(SUM(oi.quantity - oi.refunded_quantity), 0)
+   FROM order_item oi
+   WHERE oi.order_id = $1)::BIGINT AS ordered,
+  (SELECT COALESCE(SUM(si.quantity), 0)
+   FROM shipment_item si
+   JOIN shipment s ON s.shipment_id = si.shipment_id
+   WHERE s.order_id = $1 AND s.status IN ('shipped', 'delivered'))::BIGINT AS shipped,
+  (SELECT COALESCE(SUM(si.quantity), 0)
+   FROM shipment_item si
+   JOIN shipment s ON s.shipment_id = si.shipment_id
+   WHERE s.order_id = $1 AND s.status = 'delivered')::BIGINT AS delivered;
+
+-- name: CreateCartEvent :exec
+INSERT INTO cart_event (
+  session_id, product_id, variant_id, event_type
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: GetCartItemForUpdate :one
+SELECT
+  ci.cart_item_id,
+  ci.variant_id,
+  ci.quantity,
+  v.product_id,
+  v.stock_quantity AS available_stock
+FROM cart_item ci
+JOIN product_variant v ON v.variant_id = ci.variant_id
+WHERE ci.cart_item_id = $1
+  AND ci.cart_id = $2
+FOR UPDATE OF ci;
+
+-- name: SetCartItemQuantity :exec
+UPDATE cart_item
+SET quantity = $2
+WHERE cart_item_id = $1;
+
+-- name: DeleteCartItem :exec
+DELETE FROM cart_item
+WHERE cart_item_id = $1;
+
+-- name: InsertProductViews :exec
+-- Bulk insert; views for products or sessions outside the store are dropped.
+INSERT INTO product_view (product_id, store_id, session_id, viewed_at)
+SELECT v.product_id, v.store_id, v.session_id, v.viewed_at
+FROM unnest(
+  @product_ids::BIGINT[],
+  @store_ids::BIGINT[],
+  @session_ids::UUID[],
+  @viewed_ats::TIMESTAMPTZ[]
+) AS v(product_id, store_id, session_id, viewed_at)
+JOIN product p ON p.product_id = v.product_id AND p.store_id = v.store_id
+JOIN visitor_session vs ON vs.session_id = v.session_id AND vs.store_id = v.store_id;
+
+-- name: GetStoreProductForUpdate :one
+SELECT *
+FROM product
+WHERE product_id = $1 AND store_id = $2
+FOR UPDATE;
+
+-- name: UpdateProduct :one
+UPDATE product
+SET name = $3,
+    slug = $4,
+    description = $5,
+    brand = $6,
+    category_id = $7,
+    deleted_at = $8,
+    updated_at = NOW()
+WHERE product_id = $1 AND store_id = $2
+RETURNING *;
+
+-- name: IsStoreCategory :one
+SELECT EXISTS (
+  SELECT 1
+  FROM store_category
+  WHERE store_id = $1 AND category_id = $2
+);
+
+-- name: ProductSlugTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product
+  WHERE store_id = $1 AND slug = $2 AND product_id <> $3
+);
+
+-- name: ListProductVariantAttributes :many
+SELECT
+  v.variant_id,
+  vav.attribute_id,
+  vav.value
+FROM product_variant v
+JOIN variant_attribute_value vav ON vav.variant_id = v.variant_id
+WHERE v.product_id = $1
+  AND v.deleted_at IS NULL
+ORDER BY v.variant_id, vav.attribute_id;
+
+-- name: UpdateVariant :one
+UPDATE product_variant
+SET sku = $2,
+    price = $3,
+    attribute_hash = $4,
+    updated_at = NOW()
+WHERE variant_id = $1
+RETURNING *;
+
+-- name: VariantSKUTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product_variant
+  WHERE store_id = $1 AND sku = $2 AND variant_id <> $3
+);
+
+-- name: DeleteVariantAttributes :exec
+DELETE FROM variant_attribute_value
+WHERE variant_id = $1;
+
+-- name: RetireVariant :exec
+UPDATE product_variant
+SET deleted_at = NOW(),
+    updated_at = NOW()
+WHERE variant_id = $1;
+
+-- name: DeleteVariantCartItems :exec
+DELETE FROM cart_item
+WHERE variant_id = $1;
+
+-- name: DeleteProductCartItems :exec
+DELETE FROM cart_item
+WHERE variant_id IN (
+  SELECT variant_id
+  FROM product_variant
+  WHERE product_id = $1
+);
+
+-- name: RefreshDefaultVariant :exec
+-- Keeps the current default while it is live and in stock, otherwise fails
+-- over to the first live variant with stock (or any live one, or none).
+UPDATE product p
+SET default_variant_id = (
+  SELECT v.variant_id
+  FROM product_variant v
+  WHERE v.product_id = p.product_id
+    AND v.deleted_at IS NULL
+  ORDER BY (v.stock_quantity > 0) DESC,
+           (v.variant_id = p.default_variant_id) DESC,
+           v.variant_id
+  LIMIT 1
+)
+WHERE p.product_id = $1;
+
+-- name: RecalculateProductStock :exec
+UPDATE product p
+SET stock_quantity = s.total,
+    in_stock = s.total > 0,
+    updated_at = NOW()
+FROM (
+  SELECT COALESCE(SUM(v.stock_quantity), 0)::INT AS total
+  FROM product_variant v
+  WHERE v.product_id = $1
+    AND v.deleted_at IS NULL
+) s
+WHERE p.product_id = $1;
+
+-- name: AdjustVariantStock :one
+-- Applies a signed change; no row comes back when stock would go negative.
+UPDATE product_variant
+SET stock_quantity = stock_quantity + @quantity_change,
+    updated_at = NOW()
+WHERE variant_id = @variant_id
+  AND stock_quantity + @quantity_change >= 0
+RETURNING product_id, stock_quantity, (deleted_at IS NOT NULL) AS retired;
+
+-- name: CreateInventoryMovement :one
+INSERT INTO inventory_movement (
+  store_id, variant_id, quantity_change, reason, actor_type, actor_id, order_id, note
+)
+SELECT v.store_id, v.variant_id, $2, $3, $4, $5, $6, $7
+FROM product_variant v
+WHERE v.variant_id = $1
+RETURNING *;
+
+-- name: ListProductStockMismatches :many
+SELECT
+  p.product_id,
+  p.store_id,
+  p.stock_quantity,
+  COALESCE(SUM(v.stock_quantity), 0)::INT AS variant_stock
+FROM product p
+LEFT JOIN product_variant v
+  ON v.product_id = p.product_id
+ AND v.deleted_at IS NULL
+GROUP BY p.product_id
+HAVING p.stock_quantity <> COALESCE(SUM(v.stock_quantity), 0)
+ORDER BY p.product_id;
+
+-- name: UpdateStoreLowStockThreshold :one
+UPDATE store
+SET low_stock_threshold = $2,
+    updated_at = NOW()
+WHERE store_id = $1
+RETURNING low_stock_threshold;
+
+-- name: UpdateVariantLowStockThreshold :one
+-- A NULL threshold falls back to the store's.
+UPDATE product_variant
+SET low_stock_threshold = sqlc.narg('low_stock_threshold'),
+    updated_at = NOW()
+WHERE variant_id = @variant_id
+RETURNING *;
+
+-- name: OpenStockAlerts :execrows
+-- Opens an alert for every live variant at or below its threshold
+-- (the variant's own, else the store's) that has no unresolved alert yet.
+INSERT INTO stock_alert (store_id, variant_id, stock_quantity, threshold)
+SELECT v.store_id,
+       v.variant_id,
+       v.stock_quantity,
+       COALESCE(v.low_stock_threshold, s.low_stock_threshold)
+FROM product_variant v
+JOIN product p ON p.product_id = v.product_id
+JOIN store s ON s.store_id = v.store_id
+WHERE v.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND v.stock_quantity <= COALESCE(v.low_stock_threshold, s.low_stock_threshold)
+ON CONFLICT (variant_id) WHERE status <> 'resolved' DO NOTHING;
+
+-- name: ResolveStockAlerts :execrows
+-- Resolves unresolved alerts whose variant is back above its threshold
+-- or no longer sold.
+UPDATE stock_alert a
+SET status = 'resolved',
+    resolved_at = NOW()
+FROM product_variant v
+JOIN product p ON p.product_id = v.product_id
+JOIN store s ON s.store_id = v.store_id
+WHERE a.variant_id = v.variant_id
+  AND a.status <> 'resolved'
+  AND (v.deleted_at IS NOT NULL
+       OR p.deleted_at IS NOT NULL
+       OR v.stock_quantity > COALESCE(v.low_stock_threshold, s.low_stock_threshold));
+
+-- name: ListStockAlerts :many
+-- view is 'active' (unresolved and not snoozed), 'snoozed' or 'resolved'.
+SELECT
+  a.alert_id,
+  a.variant_id,
+  v.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.stock_quantity AS current_stock,
+  a.stock_quantity,
+  a.threshold,
+  a.status,
+  a.snoozed_until,
+  a.created_at,
+  a.acknowledged_at,
+  a.resolved_at
+FROM stock_alert a
+JOIN product_variant v ON v.variant_id = a.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE a.store_id = @store_id
+  AND CASE @view::TEXT
+        WHEN 'snoozed' THEN a.status <> 'resolved' AND a.snoozed_until > NOW()
+        WHEN 'resolved' THEN a.status = 'resolved'
+        ELSE a.status <> 'resolved' AND (a.snoozed_until IS NULL OR a.snoozed_until <= NOW())
+      END
+ORDER BY a.created_at DESC, a.alert_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountStockAlerts :one
+SELECT COUNT(*)
+FROM stock_alert a
+WHERE a.store_id = @store_id
+  AND CASE @view::TEXT
+        WHEN 'snoozed' THEN a.status <> 'resolved' AND a.snoozed_until > NOW()
+        WHEN 'resolved' THEN a.status = 'resolved'
+        ELSE a.status <> 'resolved' AND (a.snoozed_until IS NULL OR a.snoozed_until <= NOW())
+      END;
+
+-- name: GetStockAlert :one
+SELECT
+  a.alert_id,
+  a.variant_id,
+  v.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.stock_quantity AS current_stock,
+  a.stock_quantity,
+  a.threshold,
+  a.status,
+  a.snoozed_until,
+  a.created_at,
+  a.acknowledged_at,
+  a.resolved_at
+FROM stock_alert a
+JOIN product_variant v ON v.variant_id = a.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE a.alert_id = $1
+  AND a.store_id = $2;
+
+-- name: AcknowledgeStockAlert :execrows
+UPDATE stock_alert
+SET status = 'acknowledged',
+    acknowledged_at = COALESCE(acknowledged_at, NOW())
+WHERE alert_id = $1
+  AND store_id = $2
+  AND status <> 'resolved';
+
+-- name: SnoozeStockAlert :execrows
+UPDATE stock_alert
+SET snoozed_until = $3
+WHERE alert_id = $1
+  AND store_id = $2
+  AND status <> 'resolved';
+
+-- name: RefreshProductSearch :exec
+INSERT INTO product_search (product_id, document)
+SELECT p.product_id,
+       setweight(to_tsvector('simple', p.name), 'A') ||
+       setweight(to_tsvector('simple', COALESCE(p.brand, '')), 'B') ||
+       setweight(to_tsvector('simple', COALESCE((
+         SELECT string_agg(DISTINCT vav.value, ' ')
+         FROM variant_attribute_value vav
+         JOIN product_variant v ON v.variant_id = vav.variant_id
+         WHERE v.product_id = p.product_id
+           AND v.deleted_at IS NULL
+       ), '')), 'C') ||
+       setweight(to_tsvector('simple', COALESCE(p.description, '')), 'D')
+FROM product p
+WHERE p.product_id = $1
+ON CONFLICT (product_id) DO UPDATE
+SET document = EXCLUDED.document;
+
+-- name: ListStoreCategoryOptions :many
+-- Every category a store can use: all global ones and its private ones,
+-- enabled first in display order.
+SELECT
+  c.category_id,
+  c.name,
+  c.parent_id,
+  (c.store_id IS NOT NULL)::BOOLEAN AS is_private,
+  (sc.category_id IS NOT NULL)::BOOLEAN AS enabled,
+  COALESCE(sc.position, 0)::INT AS position
+FROM category_definition c
+LEFT JOIN store_category sc
+  ON sc.category_id = c.category_id
+ AND sc.store_id = @store_id
+WHERE c.store_id IS NULL
+   OR c.store_id = @store_id
+ORDER BY enabled DESC, position, c.name;
+
+-- name: GetStoreCategoryDefinition :one
+-- A global category or one of the store's private ones.
+SELECT *
+FROM category_definition
+WHERE category_id = $1
+  AND (store_id IS NULL OR store_id = $2);
+
+-- name: CategoryNameTaken :one
+-- Private names may not repeat a global name or another private name of the store.
+SELECT EXISTS (
+  SELECT 1
+  FROM category_definition
+  WHERE name = @name
+    AND category_id <> @category_id
+    AND (store_id IS NULL OR store_id = @store_id)
+);
+
+-- name: IsCategoryAncestor :one
+-- Whether ancestor_id is category_id itself or one of its ancestors.
+WITH RECURSIVE up AS (
+  SELECT category_id, parent_id
+  FROM category_definition
+  WHERE category_id = @category_id
+  UNION ALL
+  SELECT c.category_id, c.parent_id
+  FROM category_definition c
+  JOIN up ON c.category_id = up.parent_id
+)
+SELECT EXISTS (
+  SELECT 1 FROM up WHERE category_id = @ancestor_id
+);
+
+-- name: CreateStoreCategoryDefinition :one
+INSERT INTO category_definition (name, parent_id, store_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateStoreCategoryDefinition :one
+UPDATE category_definition
+SET name = $3,
+    parent_id = $4
+WHERE category_id = $1
+  AND store_id = $2
+RETURNING *;
+
+-- name: DeleteStoreCategoryDefinition :exec
+DELETE FROM category_definition
+WHERE category_id = $1
+  AND store_id = $2;
+
+-- name: CategoryHasChildren :one
+SELECT EXISTS (
+  SELECT 1 FROM category_definition WHERE parent_id = $1
+);
+
+-- name: CategoryHasProducts :one
+-- live_only ignores soft-deleted products.
+SELECT EXISTS (
+  SELECT 1
+  FROM product
+  WHERE store_id = @store_id
+    AND category_id = @category_id
+    AND (NOT @live_only::BOOLEAN OR deleted_at IS NULL)
+);
+
+-- name: EnableStoreCategory :exec
+-- Newly enabled categories go last.
+INSERT INTO store_category (store_id, category_id, position)
+SELECT $1, $2, COALESCE(MAX(position) + 1, 0)
+FROM store_category
+WHERE store_id = $1
+ON CONFLICT (store_id, category_id) DO NOTHING;
+
+-- name: DisableStoreCategory :exec
+DELETE FROM store_category
+WHERE store_id = $1
+  AND category_id = $2;
+
+-- name: CountStoreCategories :one
+SELECT COUNT(*)
+FROM store_category
+WHERE store_id = $1;
+
+-- name: ReorderStoreCategories :execrows
+-- Positions follow the order of category_ids.
+UPDATE store_category sc
+SET position = o.ord - 1
+FROM unnest(@category_ids::BIGINT[]) WITH ORDINALITY AS o(category_id, ord)
+WHERE sc.store_id = @store_id
+  AND sc.category_id = o.category_id;
+
+-- name: ListStoreCategoryTree :many
+-- The store's enabled categories with the number of in-stock products in
+-- each one and all of its subcategories.
+WITH RECURSIVE enabled AS (
+  SELECT c.category_id, c.name, c.parent_id, sc.position
+  FROM store_category sc
+  JOIN category_definition c
+    ON c.category_id = sc.category_id
+  WHERE sc.store_id = $1
+),
+subtree AS (
+  SELECT e.category_id AS root_id, e.category_id
+  FROM enabled e
+  UNION ALL
+  SELECT s.root_id, c.category_id
+  FROM subtree s
+  JOIN category_definition c
+    ON c.parent_id = s.category_id
+  WHERE c.store_id IS NULL OR c.store_id = $1
+),
+counts AS (
+  SELECT p.category_id, COUNT(*) AS n
+  FROM product p
+  WHERE p.store_id = $1
+    AND p.deleted_at IS NULL
+    AND p.in_stock
+  GROUP BY p.category_id
+)
+SELECT
+  e.category_id,
+  e.name,
+  e.parent_id,
+  COALESCE((
+    SELECT SUM(ct.n)
+    FROM subtree s
+    JOIN counts ct ON ct.category_id = s.category_id
+    WHERE s.root_id = e.category_id
+  ), 0)::BIGINT AS product_count
+FROM enabled e
+ORDER BY e.position, e.name;
+
+-- name: ListStoreAttributes :many
+-- Every attribute a store can use: all global ones and its private ones.
+SELECT
+  attribute_id,
+  name,
+  data_type,
+  (store_id IS NOT NULL)::BOOLEAN AS is_private
+FROM attribute_definition
+WHERE store_id IS NULL
+   OR store_id = $1
+ORDER BY name;
+
+-- name: GetStoreAttribute :one
+-- A global attribute or one of the store's private ones.
+SELECT *
+FROM attribute_definition
+WHERE attribute_id = $1
+  AND (store_id IS NULL OR store_id = $2);
+
+-- name: AttributeNameTaken :one
+-- Private names may not repeat a global name or another private name of the store.
+SELECT EXISTS (
+  SELECT 1
+  FROM attribute_definition
+  WHERE name = $1
+    AND (store_id IS NULL OR store_id = $2)
+);
+
+-- name: CreateStoreAttribute :one
+INSERT INTO attribute_definition (name, data_type, store_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpsertCategoryAttribute :exec
+INSERT INTO category_attribute (category_id, attribute_id, is_required, allowed_values)
+VALUES (@category_id, @attribute_id, @is_required, @allowed_values::TEXT[])
+ON CONFLICT (category_id, attribute_id) DO UPDATE
+SET is_required = EXCLUDED.is_required,
+    allowed_values = EXCLUDED.allowed_values;
+
+-- name: CountCategoryAttributeConflicts :one
+-- Live variants of the category's products that would break the rule: those
+-- missing a required attribute, or holding a value outside allowed_values.
+SELECT COUNT(*)
+FROM product_variant v
+JOIN product p
+  ON p.product_id = v.product_id
+LEFT JOIN variant_attribute_value vav
+  ON vav.variant_id = v.variant_id
+ AND vav.attribute_id = @attribute_id
+WHERE p.category_id = @category_id
+  AND p.deleted_at IS NULL
+  AND v.deleted_at IS NULL
+  AND (
+    (@is_required::BOOLEAN AND vav.value IS NULL)
+    OR (vav.value IS NOT NULL
+        AND @allowed_values::TEXT[] IS NOT NULL
+        AND NOT (vav.value = ANY(@allowed_values::TEXT[])))
+  );
+
+-- name: CategoryAttributeInUse :one
+-- Whether a live variant of the category's products has a value for the attribute.
+SELECT EXISTS (
+  SELECT 1
+  FROM product_variant v
+  JOIN product p
+    ON p.product_id = v.product_id
+  JOIN variant_attribute_value vav
+    ON vav.variant_id = v.variant_id
+  WHERE p.category_id = $1
+    AND vav.attribute_id = $2
+    AND p.deleted_at IS NULL
+    AND v.deleted_at IS NULL
+);
+
+-- name: DeleteCategoryAttribute :execrows
+DELETE FROM category_attribute
+WHERE category_id = $1
+  AND attribute_id = $2;
+
+-- name: CreateImportJob :one
+INSERT INTO product_import_job (store_id, format, dry_run, total_rows)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: StartImportJob :exec
+UPDATE product_import_job
+SET status = 'running'
+WHERE job_id = $1;
+
+-- name: UpdateImportJobProgress :exec
+UPDATE product_import_job
+SET processed_rows = $2,
+    failed_rows = $3
+WHERE job_id = $1;
+
+-- name: FinishImportJob :exec
+UPDATE product_import_job
+SET status = $2,
+    error = $3,
+    finished_at = NOW()
+WHERE job_id = $1;
+
+-- name: FailInterruptedImportJobs :execrows
+-- Jobs run in the server process; any still open at startup were cut off.
+UPDATE product_import_job
+SET status = 'failed',
+    error = 'interrupted by a server restart',
+    finished_at = NOW()
+WHERE status IN ('pending', 'running');
+
+-- name: InsertImportError :exec
+INSERT INTO product_import_error (job_id, row_number, field, message)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetImportJob :one
+SELECT *
+FROM product_import_job
+WHERE job_id = $1
+  AND store_id = $2;
+
+-- name: ListImportErrors :many
+SELECT *
+FROM product_import_error
+WHERE job_id = $1
+ORDER BY row_number, error_id;
+
+-- name: ListStoreExportAttributeNames :many
+-- Attribute names used by the store's live variants, the attribute columns
+-- of a catalogue export.
+SELECT DISTINCT ad.name
+FROM variant_attribute_value vav
+JOIN attribute_definition ad
+  ON ad.attribute_id = vav.attribute_id
+JOIN product_variant v
+  ON v.variant_id = vav.variant_id
+JOIN product p
+  ON p.product_id = v.product_id
+WHERE p.store_id = $1
+  AND p.deleted_at IS NULL
+  AND v.deleted_at IS NULL
+ORDER BY ad.name;
@@ -20,6 +20,7 @@ CREATE TABLE store (
   download_status VARCHAR(50) CHECK (download_status IN ('pending', 'completed', 'failed')) DEFAULT 'pending' NOT NULL,
   currency        VARCHAR(10) DEFAULT 'EGP',
   timezone        VARCHAR(100) DEFAULT 'UTC',
+  low_stock_threshold INT NOT NULL DEFAULT 10 CHECK (low_stock_threshold >= 0),
   created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
   updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
@@ -28,15 +29,27 @@ CREATE TABLE store (
 -- CATEGORIES
 -- ===============================
 
+-- Global categories (store_id NULL) are shared by every store; a store can
+-- also define private ones, which only it sees.
 CREATE TABLE category_definition (
   category_id     BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
-  name            VARCHAR(255) NOT NULL UNIQUE,
-  parent_id       BIGINT REFERENCES category_definition(category_id)
+  name            VARCHAR(255) NOT NULL,
+  parent_id       BIGINT REFERENCES category_definition(category_id),
+  store_id        BIGINT REFERENCES store(store_id) ON DELETE CASCADE
 );
 
+-- Global names are unique; private names are unique within their store.
+CREATE UNIQUE INDEX uq_category_global_name
+  ON category_definition (name) WHERE store_id IS NULL;
+
+CREATE UNIQUE INDEX uq_category_store_name
+  ON category_definition (store_id, name) WHERE store_id IS NOT NULL;
+
+-- The categories a store has enabled, in the order it shows them.
 CREATE TABLE store_category (
   store_id        BIGINT NOT NULL REFERENCES store(store_id) ON DELETE CASCADE,
   category_id     BIGINT NOT NULL REFERENCES category_definition(category_id) ON DELETE CASCADE,
+  position        INT NOT NULL DEFAULT 0,
   PRIMARY KEY (store_id, category_id)
 );
 
@@ -73,14 +86,26 @@ CREATE TABLE product_variant (
   created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
   updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
   deleted_at      TIMESTAMP WITH TIME ZONE NULL,
+  low_stock_threshold INT CHECK (low_stock_threshold >= 0),
   UNIQUE (store_id, sku)
 );
 
+-- data_type drives value validation and filtering: numeric values are plain
+-- decimals and can be filtered by range, boolean values are 'true'/'false'.
+-- Like categories, attributes are global (store_id NULL) or private to a store.
 CREATE TABLE attribute_definition (
   attribute_id    BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
-  name            VARCHAR(100) NOT NULL UNIQUE
+  name            VARCHAR(100) NOT NULL,
+  data_type       VARCHAR(20) NOT NULL DEFAULT 'text' CHECK (data_type IN ('text', 'numeric', 'enum', 'boolean')),
+  store_id        BIGINT REFERENCES store(store_id) ON DELETE CASCADE
 );
 
+CREATE UNIQUE INDEX uq_attribute_global_name
+  ON attribute_definition (name) WHERE store_id IS NULL;
+
+CREATE UNIQUE INDEX uq_attribute_store_name
+  ON attribute_definition (store_id, name) WHERE store_id IS NOT NULL;
+
 CREATE TABLE variant_attribute_value (
   variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id) ON DELETE CASCADE,
   attribute_id    BIGINT NOT NULL REFERENCES attribute_definition(attribute_id),
@@ -88,13 +113,45 @@ CREATE TABLE variant_attribute_value (
   PRIMARY KEY (variant_id, attribute_id)
 );
 
+-- allowed_values NULL accepts any value of the attribute's type.
 CREATE TABLE category_attribute (
   category_id     BIGINT NOT NULL REFERENCES category_definition(category_id) ON DELETE CASCADE,
   attribute_id    BIGINT NOT NULL REFERENCES attribute_definition(attribute_id),
   is_required     BOOLEAN DEFAULT FALSE NOT NULL,
+  allowed_values  TEXT[],
   PRIMARY KEY (category_id, attribute_id)
 );
 
+-- A bulk product import. Rows are applied one by one, each in its own
+-- transaction; a dry run validates every row and rolls it back.
+CREATE TABLE product_import_job (
+  job_id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  store_id        BIGINT NOT NULL REFERENCES store(store_id) ON DELETE CASCADE,
+  format          VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'ndjson')),
+  dry_run         BOOLEAN NOT NULL DEFAULT FALSE,
+  status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  total_rows      INT NOT NULL DEFAULT 0,
+  processed_rows  INT NOT NULL DEFAULT 0,
+  failed_rows     INT NOT NULL DEFAULT 0,
+  error           TEXT,
+  created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  finished_at     TIMESTAMP WITH TIME ZONE
+);
+
+-- Row numbers count data rows from 1, not counting a CSV header. A row
+-- with errors is not imported, except for image_url errors: the row is
+-- imported without its image.
+CREATE TABLE product_import_error (
+  error_id        BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  job_id          BIGINT NOT NULL REFERENCES product_import_job(job_id) ON DELETE CASCADE,
+  row_number      INT NOT NULL,
+  field           VARCHAR(120),
+  message         TEXT NOT NULL
+);
+
+CREATE INDEX idx_product_import_error_job
+  ON product_import_error (job_id, row_number);
+
 CREATE TABLE product_variant_image (
   image_id        BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
   product_variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id) ON DELETE CASCADE,
@@ -102,6 +159,36 @@ CREATE TABLE product_variant_image (
   created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
+-- Full-text search document per product, rebuilt by the product service on
+-- every write: name (A), brand (B), live variants' attribute values (C) and
+-- description (D). The 'simple' configuration keeps words unstemmed so
+-- non-English catalogues match too; prefix queries cover plurals.
+CREATE TABLE product_search (
+  product_id      BIGINT PRIMARY KEY REFERENCES product(product_id) ON DELETE CASCADE,
+  document        TSVECTOR NOT NULL
+);
+
+CREATE INDEX idx_product_search_document
+  ON product_search USING GIN (document);
+
+-- Backfill the products that existed before product_search, with the same
+-- document RefreshProductSearch builds; later writes keep it current.
+INSERT INTO product_search (product_id, document)
+SELECT p.product_id,
+       setweight(to_tsvector('simple', p.name), 'A') ||
+       setweight(to_tsvector('simple', COALESCE(p.brand, '')), 'B') ||
+       setweight(to_tsvector('simple', COALESCE((
+         SELECT string_agg(DISTINCT vav.value, ' ')
+         FROM variant_attribute_value vav
+         JOIN product_variant v ON v.variant_id = vav.variant_id
+         WHERE v.product_id = p.product_id
+           AND v.deleted_at IS NULL
+       ), '')), 'C') ||
+       setweight(to_tsvector('simple', COALESCE(p.description, '')), 'D')
+FROM product p
+WHERE p.deleted_at IS NULL
+ON CONFLICT (product_id) DO NOTHING;
+
 ALTER TABLE product
 ADD CONSTRAINT fk_product_default_variant
 FOREIGN KEY (default_variant_id)
@@ -167,9 +254,11 @@ CREATE TABLE customer_order (
   customer_id     BIGINT REFERENCES customer(customer_id),
   session_id      UUID NOT NULL REFERENCES visitor_session(session_id),
   total_amount    DECIMAL(10,2) NOT NULL,
-  status          VARCHAR(50) DEFAULT 'pending' CHECK(status IN ('pending', 'completed', 'shipped', 'cancelled', 'refunded')),
+  status          VARCHAR(50) DEFAULT 'pending' CHECK(status IN ('pending', 'completed', 'shipped', 'delivered', 'cancelled', 'refunded')),
   created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-  updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+  updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  email           VARCHAR(255), -- contact for guest orders
+  shipping_address JSONB
 );
 
 CREATE TABLE order_item (
@@ -178,9 +267,66 @@ CREATE TABLE order_item (
   variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id),
   quantity        INT NOT NULL CHECK (quantity > 0),
   unit_price      DECIMAL(10,2) NOT NULL,
-  subtotal        DECIMAL(10,2) NOT NULL
+  subtotal        DECIMAL(10,2) NOT NULL,
+  refunded_quantity INT NOT NULL DEFAULT 0 CHECK (refunded_quantity >= 0 AND refunded_quantity <= quantity)
+);
+
+-- Stock held for a pending order while the customer pays. The variant stock is
+-- decremented when the reservation is created and restored if it is released.
+CREATE TABLE stock_reservation (
+  reservation_id  BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  order_id        BIGINT NOT NULL REFERENCES customer_order(order_id),
+  variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id),
+  quantity        INT NOT NULL CHECK (quantity > 0),
+  status          VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'committed', 'released')),
+  expires_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_stock_reservation_active_expiry
+  ON stock_reservation (expires_at)
+  WHERE status = 'active';
+
+-- Every change to product_variant.stock_quantity, signed.
+-- A committed reservation is recorded as a reservation (+) / sale (-) pair.
+CREATE TABLE inventory_movement (
+  movement_id     BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  store_id        BIGINT NOT NULL REFERENCES store(store_id),
+  variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id),
+  quantity_change INT NOT NULL CHECK (quantity_change <> 0),
+  reason          VARCHAR(20) NOT NULL CHECK (reason IN ('restock', 'sale', 'refund', 'adjustment', 'reservation')),
+  actor_type      VARCHAR(20) NOT NULL CHECK (actor_type IN ('store_owner', 'admin', 'customer', 'guest', 'system')),
+  actor_id        BIGINT,
+  order_id        BIGINT REFERENCES customer_order(order_id),
+  note            TEXT,
+  created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inventory_movement_variant
+  ON inventory_movement (variant_id, created_at);
+
+-- Raised when a variant's stock falls to its low-stock threshold (the
+-- variant's own, else the store's) and resolved once it is back above it.
+CREATE TABLE stock_alert (
+  alert_id        BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  store_id        BIGINT NOT NULL REFERENCES store(store_id),
+  variant_id      BIGINT NOT NULL REFERENCES product_variant(variant_id),
+  stock_quantity  INT NOT NULL,
+  threshold       INT NOT NULL,
+  status          VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+  snoozed_until   TIMESTAMP WITH TIME ZONE,
+  created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  acknowledged_at TIMESTAMP WITH TIME ZONE,
+  resolved_at     TIMESTAMP WITH TIME ZONE
 );
 
+-- At most one unresolved alert per variant.
+CREATE UNIQUE INDEX uq_stock_alert_unresolved
+  ON stock_alert (variant_id) WHERE status <> 'resolved';
+
+CREATE INDEX idx_stock_alert_store
+  ON stock_alert (store_id, created_at);
+
 CREATE TABLE payment (
   payment_id      BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
   order_id        BIGINT NOT NULL REFERENCES customer_order(order_id),
@@ -198,7 +344,16 @@ CREATE TABLE shipment (
   carrier         VARCHAR(255),
   shipped_at      TIMESTAMP WITH TIME ZONE,
   delivered_at    TIMESTAMP WITH TIME ZONE CHECK (delivered_at >= shipped_at),
-  status          VARCHAR(50) DEFAULT 'pending'
+  status          VARCHAR(50) DEFAULT 'pending' CHECK (status IN ('pending', 'shipped', 'delivered'))
+);
+
+-- Which order items (and how many of each) travel in a shipment,
+-- so an order can be split across several shipments.
+CREATE TABLE shipment_item (
+  shipment_id     BIGINT NOT NULL REFERENCES shipment(shipment_id) ON DELETE CASCADE,
+  order_item_id   BIGINT NOT NULL REFERENCES order_item(order_item_id),
+  quantity        INT NOT NULL CHECK (quantity > 0),
+  PRIMARY KEY (shipment_id, order_item_id)
 );
 
 CREATE TABLE product_view (
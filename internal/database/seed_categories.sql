@@ -33,7 +33,7 @@ INSERT INTO category_definition (name, parent_id) VALUES
 
 -- Books
 ('Books', NULL)
-ON CONFLICT (name) DO NOTHING;
+ON CONFLICT (name) WHERE store_id IS NULL DO NOTHING;
 
 -- ===============================
 -- ATTRIBUTE DEFINITIONS
@@ -82,7 +82,18 @@ INSERT INTO attribute_definition (name) VALUES
 -- Sports
 ('Sport Type'),
 ('Usage Level')
-ON CONFLICT (name) DO NOTHING;
+ON CONFLICT (name) WHERE store_id IS NULL DO NOTHING;
+
+UPDATE attribute_definition
+SET data_type = 'numeric'
+WHERE name IN (
+  'Weight', 'RAM', 'Storage', 'Screen Size', 'Battery Capacity',
+  'Shoe Size', 'Heel Height', 'Power Consumption', 'Voltage', 'Pages'
+);
+
+UPDATE attribute_definition
+SET data_type = 'enum'
+WHERE name IN ('Size', 'Fit', 'Gender', 'Season', 'Usage Level');
 
 -- Phones
 INSERT INTO category_attribute (category_id, attribute_id, is_required)
@@ -12,7 +12,60 @@ var (
 	ErrInvalidVariant   = errors.New("invalid variant")
 	ErrInsufficientStock = errors.New("insufficient stock")
 	ErrCartNotFound     = errors.New("cart not found")
+	ErrCartItemNotFound = errors.New("cart item not found")
+	ErrGuestContactRequired = errors.New("guest contact required")
 	ErrCartEmpty        = errors.New("cart empty")
 	ErrOutOfStock       = errors.New("out of stock")
+	ErrItemUnavailable  = errors.New("item unavailable")
 	ErrInvalidQuantity  = errors.New("invalid quantity")
+	ErrInvalidDateRange = errors.New("invalid date range")
+	ErrOrderNotFound    = errors.New("order not found")
+	ErrInvalidOrderStatus      = errors.New("invalid order status")
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrUnsupportedPaymentMethod = errors.New("unsupported payment method")
+	ErrNoPaymentProvider       = errors.New("no payment provider configured")
+	ErrPaymentDeclined         = errors.New("payment declined")
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidRefund           = errors.New("invalid refund")
+	ErrOrderNotRefundable      = errors.New("order not refundable")
+	ErrRefundFailed            = errors.New("refund failed")
+	ErrShipmentNotFound          = errors.New("shipment not found")
+	ErrInvalidShipment           = errors.New("invalid shipment")
+	ErrInvalidShipmentStatus     = errors.New("invalid shipment status")
+	ErrInvalidShipmentTransition = errors.New("invalid shipment status transition")
+	ErrOrderNotShippable         = errors.New("order not shippable")
+	ErrStoreNotFound             = errors.New("store not found")
+	ErrInvalidEvent              = errors.New("invalid event")
+	ErrProductNotFound           = errors.New("product not found")
+	ErrInvalidProduct            = errors.New("invalid product")
+	ErrInvalidCategory           = errors.New("invalid category")
+	ErrSlugTaken                 = errors.New("slug taken")
+	ErrCategoryAttributeMismatch = errors.New("variant attributes do not match category")
+	ErrVariantNotFound           = errors.New("variant not found")
+	ErrInvalidVariantUpdate      = errors.New("invalid variant update")
+	ErrSKUTaken                  = errors.New("sku taken")
+	ErrVariantExists             = errors.New("variant exists")
+	ErrInvalidThreshold          = errors.New("invalid low stock threshold")
+	ErrStockAlertNotFound        = errors.New("stock alert not found")
+	ErrInvalidSnooze             = errors.New("invalid snooze")
+	ErrInvalidAlertView          = errors.New("invalid alert view")
+	ErrInvalidSort               = errors.New("invalid sort")
+	ErrInvalidCursor             = errors.New("invalid cursor")
+	ErrInvalidAttributeFilter    = errors.New("invalid attribute filter")
+	ErrCategoryNotFound          = errors.New("category not found")
+	ErrCategoryNotEditable       = errors.New("category not editable")
+	ErrCategoryNameTaken         = errors.New("category name taken")
+	ErrCategoryInUse             = errors.New("category in use")
+	ErrInvalidCategoryParent     = errors.New("invalid category parent")
+	ErrInvalidCategoryOrder      = errors.New("invalid category order")
+	ErrAttributeNotFound         = errors.New("attribute not found")
+	ErrAttributeNameTaken        = errors.New("attribute name taken")
+	ErrInvalidAllowedValues      = errors.New("invalid allowed values")
+	ErrCategoryAttributeConflict = errors.New("category attribute conflict")
+	ErrAttributeInUse            = errors.New("attribute in use")
+	ErrInvalidCatalogFormat      = errors.New("invalid catalog format")
+	ErrInvalidImportFile         = errors.New("invalid import file")
+	ErrImportTooLarge            = errors.New("import too large")
+	ErrImportJobNotFound         = errors.New("import job not found")
 )
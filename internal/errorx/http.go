@@ -36,16 +36,175 @@ func Resolve(err error) HTTPError {
 	case errors.Is(err, ErrCartNotFound):
 		return HTTPError{http.StatusNotFound, MsgCartNotFound}
 
+	case errors.Is(err, ErrCartItemNotFound):
+		return HTTPError{http.StatusNotFound, MsgCartItemNotFound}
+
+	case errors.Is(err, ErrGuestContactRequired):
+		return HTTPError{http.StatusBadRequest, MsgGuestContactRequired}
+
+	case errors.Is(err, ErrItemUnavailable):
+		return HTTPError{http.StatusConflict, MsgItemUnavailable}
+
 	case errors.Is(err, ErrCartEmpty):
 		return HTTPError{http.StatusBadRequest, MsgCartEmpty}
 
 	case errors.Is(err, ErrInvalidQuantity):
 		return HTTPError{http.StatusBadRequest, MsgInvalidQuantity}
 
+	case errors.Is(err, ErrInvalidDateRange):
+		return HTTPError{http.StatusBadRequest, MsgInvalidDateRange}
+
+	case errors.Is(err, ErrOrderNotFound):
+		return HTTPError{http.StatusNotFound, MsgOrderNotFound}
+
+	case errors.Is(err, ErrInvalidOrderStatus):
+		return HTTPError{http.StatusBadRequest, MsgInvalidOrderStatus}
+
+	case errors.Is(err, ErrInvalidStatusTransition):
+		return HTTPError{http.StatusConflict, MsgInvalidStatusTransition}
+
+	case errors.Is(err, ErrUnsupportedPaymentMethod):
+		return HTTPError{http.StatusBadRequest, MsgUnsupportedPaymentMethod}
+
+	case errors.Is(err, ErrNoPaymentProvider):
+		return HTTPError{http.StatusServiceUnavailable, MsgNoPaymentProvider}
+
+	case errors.Is(err, ErrPaymentDeclined):
+		return HTTPError{http.StatusPaymentRequired, MsgPaymentDeclined}
+
+	case errors.Is(err, ErrPaymentNotFound):
+		return HTTPError{http.StatusNotFound, MsgPaymentNotFound}
+
+	case errors.Is(err, ErrInvalidWebhookSignature):
+		return HTTPError{http.StatusUnauthorized, MsgInvalidWebhookSignature}
+
+	case errors.Is(err, ErrInvalidRefund):
+		return HTTPError{http.StatusBadRequest, MsgInvalidRefund}
+
+	case errors.Is(err, ErrOrderNotRefundable):
+		return HTTPError{http.StatusConflict, MsgOrderNotRefundable}
+
+	case errors.Is(err, ErrRefundFailed):
+		return HTTPError{http.StatusBadGateway, MsgRefundFailed}
+
+	case errors.Is(err, ErrShipmentNotFound):
+		return HTTPError{http.StatusNotFound, MsgShipmentNotFound}
+
+	case errors.Is(err, ErrInvalidShipment):
+		return HTTPError{http.StatusBadRequest, MsgInvalidShipment}
+
+	case errors.Is(err, ErrInvalidShipmentStatus):
+		return HTTPError{http.StatusBadRequest, MsgInvalidShipmentStatus}
+
+	case errors.Is(err, ErrInvalidShipmentTransition):
+		return HTTPError{http.StatusConflict, MsgInvalidShipmentTransition}
+
+	case errors.Is(err, ErrOrderNotShippable):
+		return HTTPError{http.StatusConflict, MsgOrderNotShippable}
+
+	case errors.Is(err, ErrStoreNotFound):
+		return HTTPError{http.StatusNotFound, MsgStoreNotFound}
+
+	case errors.Is(err, ErrInvalidEvent):
+		return HTTPError{http.StatusBadRequest, MsgInvalidEvent}
+
+	case errors.Is(err, ErrProductNotFound):
+		return HTTPError{http.StatusNotFound, MsgProductNotFound}
+
+	case errors.Is(err, ErrInvalidProduct):
+		return HTTPError{http.StatusBadRequest, MsgInvalidProduct}
+
+	case errors.Is(err, ErrInvalidCategory):
+		return HTTPError{http.StatusBadRequest, MsgInvalidCategory}
+
+	case errors.Is(err, ErrSlugTaken):
+		return HTTPError{http.StatusConflict, MsgSlugTaken}
+
+	case errors.Is(err, ErrCategoryAttributeMismatch):
+		return HTTPError{http.StatusConflict, MsgCategoryAttributeMismatch}
+
+	case errors.Is(err, ErrVariantNotFound):
+		return HTTPError{http.StatusNotFound, MsgVariantNotFound}
+
+	case errors.Is(err, ErrInvalidVariantUpdate):
+		return HTTPError{http.StatusBadRequest, MsgInvalidVariantUpdate}
+
+	case errors.Is(err, ErrSKUTaken):
+		return HTTPError{http.StatusConflict, MsgSKUTaken}
+
+	case errors.Is(err, ErrVariantExists):
+		return HTTPError{http.StatusConflict, MsgVariantExists}
+
+	case errors.Is(err, ErrInvalidThreshold):
+		return HTTPError{http.StatusBadRequest, MsgInvalidThreshold}
+
+	case errors.Is(err, ErrStockAlertNotFound):
+		return HTTPError{http.StatusNotFound, MsgStockAlertNotFound}
+
+	case errors.Is(err, ErrInvalidSnooze):
+		return HTTPError{http.StatusBadRequest, MsgInvalidSnooze}
+
+	case errors.Is(err, ErrInvalidAlertView):
+		return HTTPError{http.StatusBadRequest, MsgInvalidAlertView}
+
+	case errors.Is(err, ErrInvalidSort):
+		return HTTPError{http.StatusBadRequest, MsgInvalidSort}
+
+	case errors.Is(err, ErrInvalidCursor):
+		return HTTPError{http.StatusBadRequest, MsgInvalidCursor}
+
+	case errors.Is(err, ErrInvalidAttributeFilter):
+		return HTTPError{http.StatusBadRequest, MsgInvalidAttributeFilter}
+
+	case errors.Is(err, ErrCategoryNotFound):
+		return HTTPError{http.StatusNotFound, MsgCategoryNotFound}
+
+	case errors.Is(err, ErrCategoryNotEditable):
+		return HTTPError{http.StatusForbidden, MsgCategoryNotEditable}
+
+	case errors.Is(err, ErrCategoryNameTaken):
+		return HTTPError{http.StatusConflict, MsgCategoryNameTaken}
+
+	case errors.Is(err, ErrCategoryInUse):
+		return HTTPError{http.StatusConflict, MsgCategoryInUse}
+
+	case errors.Is(err, ErrInvalidCategoryParent):
+		return HTTPError{http.StatusBadRequest, MsgInvalidCategoryParent}
+
+	case errors.Is(err, ErrInvalidCategoryOrder):
+		return HTTPError{http.StatusBadRequest, MsgInvalidCategoryOrder}
+
+	case errors.Is(err, ErrAttributeNotFound):
+		return HTTPError{http.StatusNotFound, MsgAttributeNotFound}
+
+	case errors.Is(err, ErrAttributeNameTaken):
+		return HTTPError{http.StatusConflict, MsgAttributeNameTaken}
+
+	case errors.Is(err, ErrInvalidAllowedValues):
+		return HTTPError{http.StatusBadRequest, MsgInvalidAllowedValues}
+
+	case errors.Is(err, ErrCategoryAttributeConflict):
+		return HTTPError{http.StatusConflict, MsgCategoryAttributeConflict}
+
+	case errors.Is(err, ErrAttributeInUse):
+		return HTTPError{http.StatusConflict, MsgAttributeInUse}
+
+	case errors.Is(err, ErrInvalidCatalogFormat):
+		return HTTPError{http.StatusBadRequest, MsgInvalidCatalogFormat}
+
+	case errors.Is(err, ErrInvalidImportFile):
+		return HTTPError{http.StatusBadRequest, MsgInvalidImportFile}
+
+	case errors.Is(err, ErrImportTooLarge):
+		return HTTPError{http.StatusRequestEntityTooLarge, MsgImportTooLarge}
+
+	case errors.Is(err, ErrImportJobNotFound):
+		return HTTPError{http.StatusNotFound, MsgImportJobNotFound}
+
 	case errors.Is(err, sql.ErrNoRows):
 		return HTTPError{http.StatusNotFound, MsgResourceNotFound}
 
 	default:
-		return HTTPError{http.StatusInternalServerError, MsgCheckoutFailed}
+		return HTTPError{http.StatusInternalServerError, MsgInternalError}
 	}
 }
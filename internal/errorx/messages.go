@@ -9,10 +9,64 @@ const (
 	MsgInvalidVariant     = "invalid variant"
 	MsgInsufficientStock  = "insufficient stock"
 	MsgCartNotFound       = "cart not found"
+	MsgCartItemNotFound   = "cart item not found"
+	MsgGuestContactRequired = "guest checkout requires an email and a shipping address (street, city, country)"
 	MsgCartEmpty          = "cart is empty"
 	MsgOutOfStock         = "item out of stock"
+	MsgItemUnavailable    = "cart contains an item that is no longer sold"
 	MsgResourceNotFound   = "resource not found"
 	MsgCheckoutFailed     = "checkout failed"
 	MsgAddItemFailed      = "failed to add item to cart"
 	MsgInvalidQuantity    = "quantity must be greater than zero"
+	MsgInvalidDateRange   = "invalid date range, expected from/to as YYYY-MM-DD"
+	MsgInternalError      = "internal server error"
+	MsgOrderNotFound      = "order not found"
+	MsgInvalidOrderStatus = "invalid order status"
+	MsgInvalidStatusTransition = "order cannot move to the requested status"
+	MsgUnsupportedPaymentMethod = "unsupported payment method"
+	MsgNoPaymentProvider       = "no payment provider is configured, checkout is unavailable"
+	MsgPaymentDeclined         = "payment was declined"
+	MsgPaymentNotFound         = "payment not found"
+	MsgInvalidWebhookSignature = "invalid webhook signature"
+	MsgInvalidRefund           = "unknown order item or refund quantity exceeds what is left to refund"
+	MsgOrderNotRefundable      = "order has no captured payment left to refund"
+	MsgRefundFailed            = "payment provider rejected the refund"
+	MsgShipmentNotFound          = "shipment not found"
+	MsgInvalidShipment           = "unknown order item or quantity exceeds what is left to ship"
+	MsgInvalidShipmentStatus     = "invalid shipment status"
+	MsgInvalidShipmentTransition = "shipment cannot move to the requested status"
+	MsgOrderNotShippable         = "order has nothing left to ship"
+	MsgStoreNotFound             = "store not found"
+	MsgInvalidEvent              = "unknown event type, missing product_id or occurred_at outside the last 24 hours"
+	MsgProductNotFound           = "product not found"
+	MsgInvalidProduct            = "product name cannot be empty"
+	MsgInvalidCategory           = "category is not available in this store"
+	MsgSlugTaken                 = "another product in this store already uses this slug"
+	MsgCategoryAttributeMismatch = "variant attributes do not satisfy the category's attributes"
+	MsgVariantNotFound           = "variant not found"
+	MsgInvalidVariantUpdate      = "price must be positive, sku cannot be empty and attributes cannot repeat"
+	MsgSKUTaken                  = "another variant in this store already uses this sku"
+	MsgVariantExists             = "another variant of this product already has these attributes"
+	MsgInvalidThreshold          = "low stock threshold cannot be negative"
+	MsgStockAlertNotFound        = "stock alert not found or already resolved"
+	MsgInvalidSnooze             = "snooze must end in the future and within 30 days"
+	MsgInvalidAlertView          = "view must be active, snoozed or resolved"
+	MsgInvalidSort               = "sort must be newest, price_asc, price_desc, name, best_selling or relevance (with q)"
+	MsgInvalidCursor             = "cursor is malformed or belongs to another sort"
+	MsgInvalidAttributeFilter    = "attribute filter value does not match the attribute's type, or gives more than one range"
+	MsgCategoryNotFound          = "category not found"
+	MsgCategoryNotEditable       = "global categories can only be enabled, disabled or reordered"
+	MsgCategoryNameTaken         = "a global category or another category of this store already uses this name"
+	MsgCategoryInUse             = "category still has products or subcategories"
+	MsgInvalidCategoryParent     = "parent must be a category enabled in this store and cannot be the category itself or one of its subcategories"
+	MsgInvalidCategoryOrder      = "category_ids must list every enabled category exactly once"
+	MsgAttributeNotFound         = "attribute not found"
+	MsgAttributeNameTaken        = "a global attribute or another attribute of this store already uses this name"
+	MsgInvalidAllowedValues      = "allowed_values must be distinct, non-empty and match the attribute's type"
+	MsgCategoryAttributeConflict = "some of the category's variants lack this attribute or use a value that is not allowed"
+	MsgAttributeInUse            = "some of the category's variants still have a value for this attribute"
+	MsgInvalidCatalogFormat      = "format must be csv or ndjson"
+	MsgInvalidImportFile         = "import file is empty or malformed, or its CSV header lacks category, name, sku or price"
+	MsgImportTooLarge            = "import file is larger than 10 MB"
+	MsgImportJobNotFound         = "import job not found"
 )
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/services/analytics"
+	"github.com/gin-gonic/gin"
 )
 
 type AnalyticsHandler struct {
@@ -11,3 +17,67 @@ type AnalyticsHandler struct {
 func NewAnalyticsHandler(s *analytics.Service) *AnalyticsHandler {
 	return &AnalyticsHandler{service: s}
 }
+
+// GET /dashboard/stores/:store_id/analytics/overview?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AnalyticsHandler) Overview(c *gin.Context) {
+	h.respond(c, func(ctx context.Context, storeID int64, p analytics.Period) (any, error) {
+		return h.service.Overview(ctx, storeID, p)
+	})
+}
+
+// GET /dashboard/stores/:store_id/analytics/orders
+func (h *AnalyticsHandler) Orders(c *gin.Context) {
+	h.respond(c, func(ctx context.Context, storeID int64, p analytics.Period) (any, error) {
+		return h.service.Orders(ctx, storeID, p)
+	})
+}
+
+// GET /dashboard/stores/:store_id/analytics/customers
+func (h *AnalyticsHandler) Customers(c *gin.Context) {
+	h.respond(c, func(ctx context.Context, storeID int64, p analytics.Period) (any, error) {
+		return h.service.Customers(ctx, storeID, p)
+	})
+}
+
+// GET /dashboard/stores/:store_id/analytics/inventory
+func (h *AnalyticsHandler) Inventory(c *gin.Context) {
+	h.respond(c, func(ctx context.Context, storeID int64, p analytics.Period) (any, error) {
+		return h.service.Inventory(ctx, storeID, p)
+	})
+}
+
+// GET /dashboard/stores/:store_id/analytics/funnel
+func (h *AnalyticsHandler) Funnel(c *gin.Context) {
+	h.respond(c, func(ctx context.Context, storeID int64, p analytics.Period) (any, error) {
+		return h.service.Funnel(ctx, storeID, p)
+	})
+}
+
+// respond parses the store and date range shared by every analytics page
+// and writes the page DTO produced by load.
+func (h *AnalyticsHandler) respond(
+	c *gin.Context,
+	load func(ctx context.Context, storeID int64, p analytics.Period) (any, error),
+) {
+	ctx := c.Request.Context()
+
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	period, err := h.service.ResolvePeriod(ctx, storeID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	dto, err := load(ctx, storeID, period)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto)
+}
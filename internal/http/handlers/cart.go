@@ -9,6 +9,7 @@ import (
 
 	"github.com/Secure-Website-Builder/Backend/internal/services/cart"
 	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/types"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -42,9 +43,9 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 		return
 	}
 
-	cartDTO, err := h.Service.GetCart(ctx, storeID, sessionID)
+	cartDTO, err := h.Service.GetCart(ctx, storeID, sessionID, customerIDFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cart"})
+		c.Error(err)
 		return
 	}
 
@@ -87,6 +88,7 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 		ctx,
 		storeID,
 		sessionID,
+		customerIDFromContext(c),
 		req.VariantID,
 		req.Quantity,
 	)
@@ -123,6 +125,10 @@ func (h *CartHandler) handleAddItemError(c *gin.Context, err error) {
 
 type CheckoutRequest struct {
 	PaymentMethod string `json:"payment_method" binding:"required"`
+
+	// Required for guests, optional for logged-in customers
+	Email           string         `json:"email"`
+	ShippingAddress *types.Address `json:"shipping_address"`
 }
 
 func (h *CartHandler) Checkout(c *gin.Context) {
@@ -152,11 +158,123 @@ func (h *CartHandler) Checkout(c *gin.Context) {
 		return
 	}
 
-	err = h.Service.Checkout(ctx, storeID, sessionID, req.PaymentMethod)
+	dto, err := h.Service.Checkout(ctx, storeID, sessionID, customerIDFromContext(c), req.PaymentMethod, cart.Contact{
+		Email:           req.Email,
+		ShippingAddress: req.ShippingAddress,
+	})
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	c.JSON(http.StatusCreated, dto)
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int32 `json:"quantity" binding:"required"`
+}
+
+// UpdateItem handles PATCH /stores/:store_id/cart/items/:cart_item_id
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	storeID, sessionID, ok := cartScope(c)
+	if !ok {
+		return
+	}
+
+	cartItemID, err := strconv.ParseInt(c.Param("cart_item_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrCartItemNotFound)
+		return
+	}
+
+	var req UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.Service.UpdateItemQuantity(ctx, storeID, sessionID, customerIDFromContext(c), cartItemID, req.Quantity); err != nil {
+		c.Error(err)
+		return
+	}
+
+	cartDTO, err := h.Service.GetCart(ctx, storeID, sessionID, customerIDFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartDTO)
+}
+
+// RemoveItem handles DELETE /stores/:store_id/cart/items/:cart_item_id
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	storeID, sessionID, ok := cartScope(c)
+	if !ok {
+		return
+	}
+
+	cartItemID, err := strconv.ParseInt(c.Param("cart_item_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrCartItemNotFound)
+		return
+	}
+
+	if err := h.Service.RemoveItem(c.Request.Context(), storeID, sessionID, customerIDFromContext(c), cartItemID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClearCart handles DELETE /stores/:store_id/cart
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	storeID, sessionID, ok := cartScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.ClearCart(c.Request.Context(), storeID, sessionID, customerIDFromContext(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// cartScope reads the store id and X-Session-ID header every cart endpoint
+// works on, reporting failures through the error middleware.
+func cartScope(c *gin.Context) (int64, uuid.UUID, bool) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return 0, uuid.Nil, false
+	}
+
+	rawSessionID := c.GetHeader("X-Session-ID")
+	if rawSessionID == "" {
+		c.Error(errorx.ErrMissingSessionID)
+		return 0, uuid.Nil, false
+	}
+
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		c.Error(errorx.ErrInvalidSessionID)
+		return 0, uuid.Nil, false
+	}
+
+	return storeID, sessionID, true
+}
+
+// customerIDFromContext returns the logged-in customer's id, or nil for
+// guests and other roles.
+func customerIDFromContext(c *gin.Context) *int64 {
+	if c.GetString("role") != "customer" {
+		return nil
+	}
+	id := c.GetInt64("user_id")
+	return &id
 }
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
 	"github.com/Secure-Website-Builder/Backend/internal/services/category"
 	"github.com/gin-gonic/gin"
 )
@@ -34,9 +37,27 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
+// CategoryTree handles GET /stores/:store_id/categories/tree
+func (h *CategoryHandler) CategoryTree(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid store_id"})
+		return
+	}
+
+	tree, err := h.service.CategoryTree(c.Request.Context(), storeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": tree})
+}
+
 func (h *CategoryHandler) ListAttributes(c *gin.Context) {
 	storeParam := c.Param("store_id")
-	if _, err := strconv.ParseInt(storeParam, 10, 64); err != nil {
+	storeID, err := strconv.ParseInt(storeParam, 10, 64)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid store_id"})
 		return
 	}
@@ -48,7 +69,11 @@ func (h *CategoryHandler) ListAttributes(c *gin.Context) {
 		return
 	}
 
-	attributes, err := h.service.ListAttributesByCategory(c.Request.Context(), categoryID)
+	attributes, err := h.service.ListAttributesByCategory(c.Request.Context(), storeID, categoryID)
+	if errors.Is(err, errorx.ErrCategoryNotFound) {
+		c.Error(err)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch attributes"})
 		return
@@ -56,3 +81,242 @@ func (h *CategoryHandler) ListAttributes(c *gin.Context) {
 
 	c.JSON(http.StatusOK, attributes)
 }
+
+type CategoryOrderRequest struct {
+	CategoryIDs []int64 `json:"category_ids" binding:"required"`
+}
+
+// ListStoreCategories handles GET /dashboard/stores/:store_id/categories:
+// every global category plus the store's private ones, enabled or not.
+func (h *CategoryHandler) ListStoreCategories(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	categories, err := h.service.ListStoreOptions(c.Request.Context(), storeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// CreateCategory handles POST /dashboard/stores/:store_id/categories.
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	var req models.CategoryInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	category, err := h.service.CreatePrivate(c.Request.Context(), storeID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"category": category})
+}
+
+// UpdateCategory handles PUT /dashboard/stores/:store_id/categories/:category_id.
+// Only private categories can be renamed or moved.
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+
+	var req models.CategoryInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	category, err := h.service.UpdatePrivate(c.Request.Context(), storeID, categoryID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// DeleteCategory handles DELETE /dashboard/stores/:store_id/categories/:category_id.
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePrivate(c.Request.Context(), storeID, categoryID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EnableCategory handles PUT /dashboard/stores/:store_id/categories/:category_id/enabled.
+func (h *CategoryHandler) EnableCategory(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.service.Enable(c.Request.Context(), storeID, categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// DisableCategory handles DELETE /dashboard/stores/:store_id/categories/:category_id/enabled.
+func (h *CategoryHandler) DisableCategory(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Disable(c.Request.Context(), storeID, categoryID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderCategories handles PUT /dashboard/stores/:store_id/category-order.
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	var req CategoryOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	if err := h.service.Reorder(c.Request.Context(), storeID, req.CategoryIDs); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListStoreAttributes handles GET /dashboard/stores/:store_id/attributes:
+// every global attribute plus the store's private ones.
+func (h *CategoryHandler) ListStoreAttributes(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	attributes, err := h.service.ListStoreAttributes(c.Request.Context(), storeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attributes": attributes})
+}
+
+// CreateAttribute handles POST /dashboard/stores/:store_id/attributes.
+func (h *CategoryHandler) CreateAttribute(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	var req models.AttributeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	attribute, err := h.service.CreateAttribute(c.Request.Context(), storeID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attribute": attribute})
+}
+
+// AttachAttribute handles PUT /dashboard/stores/:store_id/categories/:category_id/attributes/:attribute_id.
+// Only private categories take attributes; attaching again replaces the rule.
+func (h *CategoryHandler) AttachAttribute(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+	attributeID, err := strconv.ParseInt(c.Param("attribute_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrAttributeNotFound)
+		return
+	}
+
+	var req models.CategoryAttributeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	attribute, err := h.service.AttachAttribute(c.Request.Context(), storeID, categoryID, attributeID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attribute": attribute})
+}
+
+// DetachAttribute handles DELETE /dashboard/stores/:store_id/categories/:category_id/attributes/:attribute_id.
+func (h *CategoryHandler) DetachAttribute(c *gin.Context) {
+	storeID, categoryID, ok := categoryScope(c)
+	if !ok {
+		return
+	}
+	attributeID, err := strconv.ParseInt(c.Param("attribute_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrAttributeNotFound)
+		return
+	}
+
+	if err := h.service.DetachAttribute(c.Request.Context(), storeID, categoryID, attributeID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func categoryScope(c *gin.Context) (storeID, categoryID int64, ok bool) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return 0, 0, false
+	}
+	categoryID, err = strconv.ParseInt(c.Param("category_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrCategoryNotFound)
+		return 0, 0, false
+	}
+	return storeID, categoryID, true
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	Service *inventory.Service
+}
+
+func NewInventoryHandler(s *inventory.Service) *InventoryHandler {
+	return &InventoryHandler{Service: s}
+}
+
+type StockAdjustmentRequest struct {
+	Quantity int32  `json:"quantity" binding:"required"`
+	Note     string `json:"note"`
+}
+
+// AdjustStock handles
+// POST /dashboard/stores/:store_id/products/:product_id/variants/:variant_id/stock-adjustments.
+// Quantity is signed: positive adds stock, negative removes it.
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	storeID, productID, variantID, ok := variantScope(c)
+	if !ok {
+		return
+	}
+
+	var req StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	movement, err := h.Service.Adjust(
+		c.Request.Context(),
+		storeID,
+		productID,
+		variantID,
+		req.Quantity,
+		req.Note,
+		actorFromContext(c),
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, movement)
+}
+
+type StoreThresholdRequest struct {
+	LowStockThreshold *int32 `json:"low_stock_threshold" binding:"required"`
+}
+
+type VariantThresholdRequest struct {
+	LowStockThreshold *int32 `json:"low_stock_threshold"`
+}
+
+type SnoozeAlertRequest struct {
+	Until time.Time `json:"until" binding:"required"`
+}
+
+// GetStoreThreshold handles GET /dashboard/stores/:store_id/low-stock-threshold.
+func (h *InventoryHandler) GetStoreThreshold(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	threshold, err := h.Service.StoreThreshold(c.Request.Context(), storeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"low_stock_threshold": threshold})
+}
+
+// SetStoreThreshold handles PUT /dashboard/stores/:store_id/low-stock-threshold.
+func (h *InventoryHandler) SetStoreThreshold(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	var req StoreThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	threshold, err := h.Service.SetStoreThreshold(c.Request.Context(), storeID, *req.LowStockThreshold)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"low_stock_threshold": threshold})
+}
+
+// SetVariantThreshold handles
+// PUT /dashboard/stores/:store_id/products/:product_id/variants/:variant_id/low-stock-threshold.
+// A null threshold makes the variant follow the store's threshold again.
+func (h *InventoryHandler) SetVariantThreshold(c *gin.Context) {
+	storeID, productID, variantID, ok := variantScope(c)
+	if !ok {
+		return
+	}
+
+	var req VariantThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	variant, err := h.Service.SetVariantThreshold(
+		c.Request.Context(),
+		storeID,
+		productID,
+		variantID,
+		req.LowStockThreshold,
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variant": variant})
+}
+
+// ListAlerts handles GET /dashboard/stores/:store_id/stock-alerts.
+// ?view= is active (default), snoozed or resolved.
+func (h *InventoryHandler) ListAlerts(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if v, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	alerts, total, err := h.Service.ListAlerts(c.Request.Context(), storeID, c.Query("view"), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": alerts,
+		"meta": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// AcknowledgeAlert handles
+// POST /dashboard/stores/:store_id/stock-alerts/:alert_id/acknowledge.
+func (h *InventoryHandler) AcknowledgeAlert(c *gin.Context) {
+	storeID, alertID, ok := alertScope(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.Service.AcknowledgeAlert(c.Request.Context(), storeID, alertID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// SnoozeAlert handles
+// POST /dashboard/stores/:store_id/stock-alerts/:alert_id/snooze.
+func (h *InventoryHandler) SnoozeAlert(c *gin.Context) {
+	storeID, alertID, ok := alertScope(c)
+	if !ok {
+		return
+	}
+
+	var req SnoozeAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	alert, err := h.Service.SnoozeAlert(c.Request.Context(), storeID, alertID, req.Until)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func alertScope(c *gin.Context) (storeID, alertID int64, ok bool) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return 0, 0, false
+	}
+	alertID, err = strconv.ParseInt(c.Param("alert_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrStockAlertNotFound)
+		return 0, 0, false
+	}
+	return storeID, alertID, true
+}
+
+// actorFromContext identifies the authenticated caller for the inventory
+// ledger; requests without a token are guests.
+func actorFromContext(c *gin.Context) inventory.Actor {
+	role := c.GetString("role")
+	if role == "" {
+		return inventory.Actor{Type: inventory.ActorGuest}
+	}
+	id := c.GetInt64("user_id")
+	return inventory.Actor{Type: role, ID: &id}
+}
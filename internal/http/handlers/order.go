@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/order"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	Service *order.Service
+}
+
+func NewOrderHandler(s *order.Service) *OrderHandler {
+	return &OrderHandler{Service: s}
+}
+
+// ListOrders handles GET /dashboard/stores/:store_id/orders
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	// pagination
+	page := 1
+	limit := 20
+
+	if v, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	filters := order.ListOrderFilters{
+		Page:  page,
+		Limit: limit,
+	}
+
+	if v := c.Query("status"); v != "" {
+		filters.Status = &v
+	}
+
+	if v := c.Query("customer_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer_id"})
+			return
+		}
+		filters.CustomerID = &id
+	}
+
+	// date range: "from" and "to" are inclusive calendar days in the store's
+	// timezone, resolved by the service
+	if v := c.Query("from"); v != "" {
+		filters.From = &v
+	}
+
+	if v := c.Query("to"); v != "" {
+		filters.To = &v
+	}
+
+	// amount range
+	if v := c.Query("min-amount"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min-amount"})
+			return
+		}
+		filters.MinAmount = &f
+	}
+
+	if v := c.Query("max-amount"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid max-amount"})
+			return
+		}
+		filters.MaxAmount = &f
+	}
+
+	orders, total, err := h.Service.ListOrders(c.Request.Context(), storeID, filters)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": orders,
+		"meta": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetOrder handles GET /dashboard/stores/:store_id/orders/:order_id
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	dto, err := h.Service.GetOrder(c.Request.Context(), storeID, orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto)
+}
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// UpdateOrderStatus handles PATCH /dashboard/stores/:store_id/orders/:order_id/status
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	dto, err := h.Service.UpdateStatus(c.Request.Context(), storeID, orderID, req.Status, actorFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto)
+}
+
+// ListCustomerOrders handles GET /stores/:store_id/orders for the logged-in customer
+func (h *OrderHandler) ListCustomerOrders(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	customerID, ok := customerFromContext(c)
+	if !ok {
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if v, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	orders, total, err := h.Service.ListCustomerOrders(c.Request.Context(), storeID, customerID, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": orders,
+		"meta": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetCustomerOrder handles GET /stores/:store_id/orders/:order_id for the logged-in customer
+func (h *OrderHandler) GetCustomerOrder(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	customerID, ok := customerFromContext(c)
+	if !ok {
+		return
+	}
+
+	dto, err := h.Service.GetCustomerOrder(c.Request.Context(), storeID, customerID, orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto)
+}
+
+// customerFromContext returns the customer id from the JWT. Order history is
+// strictly per customer, so the admin bypass of RequireRole does not apply here.
+func customerFromContext(c *gin.Context) (int64, bool) {
+	if c.GetString("role") != "customer" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return 0, false
+	}
+	return c.GetInt64("user_id"), true
+}
+
+type RefundLineRequest struct {
+	OrderItemID int64 `json:"order_item_id" binding:"required"`
+	Quantity    int32 `json:"quantity" binding:"required"`
+}
+
+type RefundOrderRequest struct {
+	Items   []RefundLineRequest `json:"items"`
+	Restock bool                `json:"restock"`
+}
+
+// RefundOrder handles POST /dashboard/stores/:store_id/orders/:order_id/refunds
+// An empty items list refunds everything not refunded yet.
+func (h *OrderHandler) RefundOrder(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	var req RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	refund := order.RefundRequest{Restock: req.Restock}
+	for _, it := range req.Items {
+		refund.Lines = append(refund.Lines, order.RefundLine{
+			OrderItemID: it.OrderItemID,
+			Quantity:    it.Quantity,
+		})
+	}
+
+	dto, err := h.Service.Refund(c.Request.Context(), storeID, orderID, refund, actorFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto)
+}
+
+type ShipmentLineRequest struct {
+	OrderItemID int64 `json:"order_item_id" binding:"required"`
+	Quantity    int32 `json:"quantity" binding:"required"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        *string               `json:"carrier"`
+	TrackingNumber *string               `json:"tracking_number"`
+	Items          []ShipmentLineRequest `json:"items"`
+}
+
+// CreateShipment handles POST /dashboard/stores/:store_id/orders/:order_id/shipments
+// An empty items list ships everything not shipped yet.
+func (h *OrderHandler) CreateShipment(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	var req CreateShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	shipment := order.NewShipment{
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+	}
+	for _, it := range req.Items {
+		shipment.Lines = append(shipment.Lines, order.ShipmentLine{
+			OrderItemID: it.OrderItemID,
+			Quantity:    it.Quantity,
+		})
+	}
+
+	dto, err := h.Service.CreateShipment(c.Request.Context(), storeID, orderID, shipment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto)
+}
+
+type UpdateShipmentStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// UpdateShipmentStatus handles PATCH /dashboard/stores/:store_id/orders/:order_id/shipments/:shipment_id/status
+func (h *OrderHandler) UpdateShipmentStatus(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	shipmentID, err := strconv.ParseInt(c.Param("shipment_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrShipmentNotFound)
+		return
+	}
+
+	var req UpdateShipmentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	dto, err := h.Service.UpdateShipmentStatus(c.Request.Context(), storeID, orderID, shipmentID, req.Status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto)
+}
+
+// ListCustomerShipments handles GET /stores/:store_id/orders/:order_id/shipments for the logged-in customer
+func (h *OrderHandler) ListCustomerShipments(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrOrderNotFound)
+		return
+	}
+
+	customerID, ok := customerFromContext(c)
+	if !ok {
+		return
+	}
+
+	shipments, err := h.Service.ListCustomerShipments(c.Request.Context(), storeID, customerID, orderID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shipments})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/cart"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds the callback body read into memory.
+const maxWebhookBody = 64 << 10
+
+type PaymentHandler struct {
+	Service *cart.Service
+}
+
+func NewPaymentHandler(s *cart.Service) *PaymentHandler {
+	return &PaymentHandler{Service: s}
+}
+
+// Webhook handles POST /payments/:method/webhook.
+// The raw body is verified against the X-Payment-Signature header by the
+// provider registered for :method.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	err = h.Service.ConfirmPayment(
+		c.Request.Context(),
+		c.Param("method"),
+		payload,
+		c.GetHeader("X-Payment-Signature"),
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
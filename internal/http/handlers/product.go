@@ -2,21 +2,26 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
 	"github.com/Secure-Website-Builder/Backend/internal/services/product"
+	"github.com/Secure-Website-Builder/Backend/internal/services/tracking"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ProductHandler struct {
-	Service *product.Service
+	Service  *product.Service
+	Tracking *tracking.Service
 }
 
-func NewProductHandler(s *product.Service) *ProductHandler {
-	return &ProductHandler{Service: s}
+func NewProductHandler(s *product.Service, t *tracking.Service) *ProductHandler {
+	return &ProductHandler{Service: s, Tracking: t}
 }
 
 func (h *ProductHandler) GetProduct(c *gin.Context) {
@@ -32,9 +37,30 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	h.recordView(c, storeID, productID)
+
 	c.JSON(http.StatusOK, product)
 }
 
+// recordView queues a product view for shoppers that identify their visitor
+// session. Store owners and admins browsing the store are not counted.
+func (h *ProductHandler) recordView(c *gin.Context, storeID, productID int64) {
+	if role := c.GetString("role"); role != "" && role != "customer" {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.GetHeader("X-Session-ID"))
+	if err != nil {
+		return
+	}
+
+	h.Tracking.RecordProductView(tracking.ProductView{
+		StoreID:   storeID,
+		ProductID: productID,
+		SessionID: sessionID,
+	})
+}
+
 // ListProducts handles GET /stores/:store_id/products
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -47,14 +73,13 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	// pagination
-	page := 1
+	// keyset pagination: ?cursor= is the previous page's next_cursor. The
+	// old ?page= is ignored, so clients still sending it get the first page
 	limit := 20
 
-	if p := c.DefaultQuery("page", "1"); p != "" {
-		if v, err := strconv.Atoi(p); err == nil && v > 0 {
-			page = v
-		}
+	var cursorPtr *string
+	if v := c.Query("cursor"); v != "" {
+		cursorPtr = &v
 	}
 	if l := c.DefaultQuery("limit", "20"); l != "" {
 		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 200 {
@@ -119,9 +144,20 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		instock = &b
 	}
 
+	// free-text search
+	var queryPtr *string
+	if v := c.Query("q"); v != "" {
+		queryPtr = &v
+	}
+
 	// reserved params
 	reserved := map[string]bool{
+		"q":         true,
+		"facets":    true,
+		"sort":      true,
+		"cursor":    true,
 		"page":      true,
+		"match":     true,
 		"limit":     true,
 		"category":  true,
 		"min-price": true,
@@ -130,10 +166,22 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		"instock":   true,
 	}
 
+	// ?match=any matches attributes on any in-stock variant instead of
+	// only the default one
+	var anyVariant bool
+	switch c.DefaultQuery("match", "default") {
+	case "default":
+	case "any":
+		anyVariant = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid match"})
+		return
+	}
+
 	// ---------------------------------------
-	// Attribute filters (grouped for IN logic)
+	// Attribute filters (grouped for IN logic, numeric ones accept min..max)
 	// ---------------------------------------
-	attrMap := make(map[int64][]string)
+	attrFilters := make([]database.AttributeFilter, 0)
 	q := c.Request.URL.Query()
 
 	for key, values := range q {
@@ -141,37 +189,39 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 			continue
 		}
 
-		attrID, err := h.Service.ResolveAttributeNameToID(ctx, storeID, key)
+		f, err := h.Service.ResolveAttributeFilter(ctx, storeID, key, values, anyVariant)
 		if err != nil {
+			c.Error(err)
+			return
+		}
+		if f == nil {
 			// unknown attribute name -> skip
 			continue
 		}
 
-		// append all values for this attribute
-		attrMap[attrID] = append(attrMap[attrID], values...)
-	}
-
-	attrFilters := make([]database.AttributeFilter, 0, len(attrMap))
-	for attrID, vals := range attrMap {
-		attrFilters = append(attrFilters, database.AttributeFilter{
-			AttributeID: attrID,
-			Values:      vals,
-		})
+		attrFilters = append(attrFilters, *f)
 	}
 
 	// build final filters object
 	filters := product.ListProductFilters{
-		Page:       page,
 		Limit:      limit,
+		Cursor:     cursorPtr,
+		Sort:       c.Query("sort"),
 		CategoryID: categoryID,
 		MinPrice:   minPricePtr,
 		MaxPrice:   maxPricePtr,
 		Brand:      brandPtr,
 		InStock:    instock,
+		Query:      queryPtr,
 		Attributes: attrFilters,
+		AnyVariant: anyVariant,
 	}
 
 	results, err := h.Service.ListProducts(ctx, storeID, filters)
+	if errors.Is(err, errorx.ErrInvalidSort) || errors.Is(err, errorx.ErrInvalidCursor) {
+		c.Error(err)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "failed to load products",
@@ -180,13 +230,29 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": results,
+	resp := gin.H{
+		"data": results.Products,
 		"meta": gin.H{
-			"page":  page,
-			"limit": limit,
+			"limit":       limit,
+			"total":       results.Total,
+			"next_cursor": results.NextCursor,
 		},
-	})
+	}
+
+	// ?facets=true adds the filter sidebar counts
+	if withFacets, _ := strconv.ParseBool(c.Query("facets")); withFacets {
+		facets, err := h.Service.ProductFacets(ctx, storeID, filters)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "failed to load facets",
+				"detail": err.Error(),
+			})
+			return
+		}
+		resp["facets"] = facets
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
@@ -213,6 +279,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		storeID,
 		req,
 		file,
+		actorFromContext(c),
 	)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
@@ -244,6 +311,7 @@ func (h *ProductHandler) AddVariant(c *gin.Context) {
 		productID,
 		req,
 		file,
+		actorFromContext(c),
 	)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
@@ -292,3 +360,110 @@ func (h *ProductHandler) UploadVariantImage(c *gin.Context) {
 		"is_primary": isPrimary,
 	})
 }
+
+// UpdateProduct handles PATCH /dashboard/stores/:store_id/products/:product_id.
+// Send "deleted": false to restore a soft-deleted product.
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrProductNotFound)
+		return
+	}
+
+	var req models.UpdateProductInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	product, err := h.Service.UpdateProduct(c.Request.Context(), storeID, productID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+// DeleteProduct handles DELETE /dashboard/stores/:store_id/products/:product_id.
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrProductNotFound)
+		return
+	}
+
+	if err := h.Service.DeleteProduct(c.Request.Context(), storeID, productID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateVariant handles PATCH /dashboard/stores/:store_id/products/:product_id/variants/:variant_id.
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	storeID, productID, variantID, ok := variantScope(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateVariantInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	variant, err := h.Service.UpdateVariant(c.Request.Context(), storeID, productID, variantID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variant": variant})
+}
+
+// RetireVariant handles DELETE /dashboard/stores/:store_id/products/:product_id/variants/:variant_id.
+func (h *ProductHandler) RetireVariant(c *gin.Context) {
+	storeID, productID, variantID, ok := variantScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.RetireVariant(c.Request.Context(), storeID, productID, variantID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// variantScope parses the store, product and variant ids of a variant route.
+func variantScope(c *gin.Context) (storeID, productID, variantID int64, ok bool) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return 0, 0, 0, false
+	}
+	productID, err = strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrProductNotFound)
+		return 0, 0, 0, false
+	}
+	variantID, err = strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrVariantNotFound)
+		return 0, 0, 0, false
+	}
+	return storeID, productID, variantID, true
+}
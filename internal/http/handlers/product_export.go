@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/product"
+	"github.com/gin-gonic/gin"
+)
+
+var exportContentTypes = map[string]string{
+	product.CatalogCSV:    "text/csv; charset=utf-8",
+	product.CatalogNDJSON: "application/x-ndjson",
+}
+
+// ExportCatalog handles GET /dashboard/stores/:store_id/product-export?format=csv|ndjson.
+// The file is streamed, so an error after the first rows can only cut it short.
+func (h *ProductHandler) ExportCatalog(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	format := c.DefaultQuery("format", product.CatalogCSV)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.Error(errorx.ErrInvalidCatalogFormat)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="store-%d-catalog.%s"`, storeID, format))
+
+	if err := h.Service.ExportCatalog(c.Request.Context(), storeID, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			log.Printf("catalog export for store %d: %v", storeID, err)
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		c.Error(err)
+	}
+}
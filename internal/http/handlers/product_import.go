@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/product"
+	"github.com/gin-gonic/gin"
+)
+
+// StartImport handles POST /dashboard/stores/:store_id/product-imports.
+// The multipart form carries the catalogue in "file", its "format" (csv or
+// ndjson, else taken from the file extension) and "dry_run". The rows are
+// imported in the background; poll the returned job for progress.
+func (h *ProductHandler) StartImport(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+	if fileHeader.Size > product.MaxImportSize {
+		c.Error(errorx.ErrImportTooLarge)
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = importFormatFromName(fileHeader.Filename)
+	}
+
+	dryRun := false
+	if v := c.PostForm("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.Error(errorx.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, product.MaxImportSize))
+	if err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	job, err := h.Service.StartImport(c.Request.Context(), storeID, format, data, dryRun, actorFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
+
+// GetImport handles GET /dashboard/stores/:store_id/product-imports/:job_id.
+func (h *ProductHandler) GetImport(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+	jobID, err := strconv.ParseInt(c.Param("job_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrImportJobNotFound)
+		return
+	}
+
+	job, err := h.Service.GetImport(c.Request.Context(), storeID, jobID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+func importFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return product.CatalogCSV
+	case ".ndjson", ".jsonl":
+		return product.CatalogNDJSON
+	}
+	return ""
+}
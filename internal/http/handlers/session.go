@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/session"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	Service *session.Service
+}
+
+func NewSessionHandler(s *session.Service) *SessionHandler {
+	return &SessionHandler{Service: s}
+}
+
+// Start handles POST /stores/:store_id/sessions.
+// It resumes the session named by X-Session-ID when possible and issues a new
+// one otherwise (201). A missing or malformed header just starts a new session.
+func (h *SessionHandler) Start(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	var sessionID *uuid.UUID
+	if id, err := uuid.Parse(c.GetHeader("X-Session-ID")); err == nil {
+		sessionID = &id
+	}
+
+	dto, created, err := h.Service.Start(
+		c.Request.Context(),
+		storeID,
+		sessionID,
+		customerIDFromContext(c),
+		session.Client{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		},
+	)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, dto)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/services/tracking"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrackingHandler struct {
+	Service *tracking.Service
+}
+
+func NewTrackingHandler(s *tracking.Service) *TrackingHandler {
+	return &TrackingHandler{Service: s}
+}
+
+type EventRequest struct {
+	Type       string     `json:"type" binding:"required"`
+	ProductID  int64      `json:"product_id"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+type IngestEventsRequest struct {
+	Events []EventRequest `json:"events" binding:"required,min=1,max=100,dive"`
+}
+
+// Ingest handles POST /stores/:store_id/events.
+// Events are queued for a background writer, so the response (202) only
+// confirms how many were accepted, not that they are stored yet.
+func (h *TrackingHandler) Ingest(c *gin.Context) {
+	storeID, err := strconv.ParseInt(c.Param("store_id"), 10, 64)
+	if err != nil {
+		c.Error(errorx.ErrInvalidStoreID)
+		return
+	}
+
+	rawSessionID := c.GetHeader("X-Session-ID")
+	if rawSessionID == "" {
+		c.Error(errorx.ErrMissingSessionID)
+		return
+	}
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		c.Error(errorx.ErrInvalidSessionID)
+		return
+	}
+
+	var req IngestEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errorx.ErrInvalidRequestBody)
+		return
+	}
+
+	events := make([]tracking.Event, len(req.Events))
+	for i, e := range req.Events {
+		events[i] = tracking.Event{
+			Type:       e.Type,
+			ProductID:  e.ProductID,
+			OccurredAt: e.OccurredAt,
+		}
+	}
+
+	accepted, err := h.Service.Ingest(c.Request.Context(), storeID, sessionID, events)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"accepted": accepted})
+}
@@ -16,25 +16,59 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		token, claims, err := utils.ParseJWT(tokenStr, secret)
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		if !setClaims(c, secret, authHeader) {
 			return
 		}
 
-		c.Set("user_id", int64(claims["user_id"].(float64)))
-		c.Set("role", claims["role"].(string))
+		c.Next()
+	}
+}
 
-		if storeID, ok := claims["store_id"]; ok {
-			id := int64(storeID.(float64))
-			c.Set("store_id", &id)
+// OptionalJWTAuth authenticates the request when it carries a bearer token
+// and lets it through as a guest (no role) when it does not.
+// A token that is present but invalid is still rejected.
+func OptionalJWTAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
+		}
+
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid token"})
+			return
+		}
+
+		if !setClaims(c, secret, authHeader) {
+			return
 		}
 
 		c.Next()
 	}
 }
 
+// setClaims parses the bearer token and stores its claims on the context.
+// It aborts the request and returns false when the token is invalid.
+func setClaims(c *gin.Context, secret, authHeader string) bool {
+	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+	token, claims, err := utils.ParseJWT(tokenStr, secret)
+	if err != nil || !token.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return false
+	}
+
+	c.Set("user_id", int64(claims["user_id"].(float64)))
+	c.Set("role", claims["role"].(string))
+
+	if storeID, ok := claims["store_id"]; ok {
+		id := int64(storeID.(float64))
+		c.Set("store_id", &id)
+	}
+
+	return true
+}
+
 // RequireRole middleware
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -48,3 +82,16 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 	}
 }
+
+// RequireRoleOrGuest lets unauthenticated (guest) requests through and
+// applies RequireRole to authenticated ones.
+func RequireRoleOrGuest(roles ...string) gin.HandlerFunc {
+	requireRole := RequireRole(roles...)
+	return func(c *gin.Context) {
+		if c.GetString("role") == "" {
+			c.Next()
+			return
+		}
+		requireRole(c)
+	}
+}
@@ -36,7 +36,8 @@ func RequireSameStore() gin.HandlerFunc {
 			return
 		}
 
-		if storeIDFromURL != storeIDFromToken.(int64) {
+		tokenStoreID, ok := storeIDFromToken.(*int64)
+		if !ok || tokenStoreID == nil || storeIDFromURL != *tokenStoreID {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "accessing another store is forbidden",
 			})
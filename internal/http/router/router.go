@@ -13,6 +13,12 @@ func SetupRouter(
 	cartHandler *handlers.CartHandler,
 	authHandler *handlers.AuthHandler,
 	storeHandler *handlers.StoreHandler,
+	analyticsHandler *handlers.AnalyticsHandler,
+	orderHandler *handlers.OrderHandler,
+	paymentHandler *handlers.PaymentHandler,
+	sessionHandler *handlers.SessionHandler,
+	trackingHandler *handlers.TrackingHandler,
+	inventoryHandler *handlers.InventoryHandler,
 	rateLimiter *middleware.RateLimiter,
 	storeOwnerChecker *middleware.StoreOwnerChecker,
 	jwtSecret string,
@@ -29,6 +35,9 @@ func SetupRouter(
 	r.POST("/auth/refresh", authHandler.RefreshToken)
 	r.POST("/admin/auth/login", authHandler.AdminLogin)
 
+	// Payment provider callbacks (public, authenticated by signature)
+	r.POST("/payments/:method/webhook", paymentHandler.Webhook)
+
 	auth := r.Group("/")
 	auth.Use(middleware.JWTAuth(jwtSecret))
 
@@ -51,22 +60,64 @@ func SetupRouter(
 
 		storeRoutes.GET("", storeHandler.GetStore)
 		storeRoutes.GET("/categories", categoryHandler.ListCategories)
+		storeRoutes.GET("/categories/tree", categoryHandler.CategoryTree)
 		storeRoutes.GET("/categories/:category_id/attributes", categoryHandler.ListAttributes)
 		storeRoutes.GET("/categories/:category_id/top-products", categoryProductHandler.GetTopProducts)
-		storeRoutes.GET("/products", productHandler.ListProducts)
-		storeRoutes.GET("/products/:product_id", productHandler.GetProduct)
 	}
 
-	// Cart endpoints
-	cartGroup := auth.Group("/stores/:store_id/cart")
+	// Product browsing (public; guests need no token)
+	productGroup := r.Group("/stores/:store_id/products")
+	productGroup.Use(
+		middleware.OptionalJWTAuth(jwtSecret),
+		middleware.RequireRoleOrGuest("customer", "store_owner", "admin"),
+		middleware.RequireSameStore(),
+		middleware.RequireStoreOwner(storeOwnerChecker),
+	)
+	productGroup.GET("", productHandler.ListProducts)
+	productGroup.GET("/:product_id", productHandler.GetProduct)
+
+	// Visitor sessions (public; a logged-in customer gets the session attached)
+	sessionGroup := r.Group("/stores/:store_id/sessions")
+	sessionGroup.Use(
+		middleware.OptionalJWTAuth(jwtSecret),
+		middleware.RequireRoleOrGuest("customer", "admin"),
+		middleware.RequireSameStore(),
+	)
+	sessionGroup.POST("", sessionHandler.Start)
+
+	// Batched storefront events, tied to the caller's X-Session-ID
+	eventGroup := r.Group("/stores/:store_id/events")
+	eventGroup.Use(
+		middleware.OptionalJWTAuth(jwtSecret),
+		middleware.RequireRoleOrGuest("customer", "admin"),
+		middleware.RequireSameStore(),
+	)
+	eventGroup.POST("", trackingHandler.Ingest)
+
+	// Cart endpoints (guests shop with X-Session-ID alone)
+	cartGroup := r.Group("/stores/:store_id/cart")
 	cartGroup.Use(
-		middleware.RequireRole("customer", "admin"),
+		middleware.OptionalJWTAuth(jwtSecret),
+		middleware.RequireRoleOrGuest("customer", "admin"),
 		middleware.RequireSameStore(),
 	)
 	cartGroup.GET("", cartHandler.GetCart)
 	cartGroup.POST("/items", cartHandler.AddItem)
+	cartGroup.PATCH("/items/:cart_item_id", cartHandler.UpdateItem)
+	cartGroup.DELETE("/items/:cart_item_id", cartHandler.RemoveItem)
+	cartGroup.DELETE("", cartHandler.ClearCart)
 	cartGroup.POST("/checkout", cartHandler.Checkout)
 
+	// Customer order history
+	customerOrders := auth.Group("/stores/:store_id/orders")
+	customerOrders.Use(
+		middleware.RequireRole("customer"),
+		middleware.RequireSameStore(),
+	)
+	customerOrders.GET("", orderHandler.ListCustomerOrders)
+	customerOrders.GET("/:order_id", orderHandler.GetCustomerOrder)
+	customerOrders.GET("/:order_id/shipments", orderHandler.ListCustomerShipments)
+
 	// Store owner dashboard routes
 	dashboard := auth.Group("/dashboard/stores/:store_id")
 	dashboard.Use(
@@ -74,9 +125,49 @@ func SetupRouter(
 		middleware.RequireStoreOwner(storeOwnerChecker),
 	)
 	{
+		dashboard.GET("/categories", categoryHandler.ListStoreCategories)
+		dashboard.POST("/categories", categoryHandler.CreateCategory)
+		dashboard.PUT("/categories/:category_id", categoryHandler.UpdateCategory)
+		dashboard.DELETE("/categories/:category_id", categoryHandler.DeleteCategory)
+		dashboard.PUT("/categories/:category_id/enabled", categoryHandler.EnableCategory)
+		dashboard.DELETE("/categories/:category_id/enabled", categoryHandler.DisableCategory)
+		dashboard.PUT("/category-order", categoryHandler.ReorderCategories)
+		dashboard.PUT("/categories/:category_id/attributes/:attribute_id", categoryHandler.AttachAttribute)
+		dashboard.DELETE("/categories/:category_id/attributes/:attribute_id", categoryHandler.DetachAttribute)
+		dashboard.GET("/attributes", categoryHandler.ListStoreAttributes)
+		dashboard.POST("/attributes", categoryHandler.CreateAttribute)
+
 		dashboard.POST("/products", productHandler.CreateProduct)
+		dashboard.PATCH("/products/:product_id", productHandler.UpdateProduct)
+		dashboard.DELETE("/products/:product_id", productHandler.DeleteProduct)
 		dashboard.POST("/products/:product_id/variants", productHandler.AddVariant)
+		dashboard.PATCH("/products/:product_id/variants/:variant_id", productHandler.UpdateVariant)
+		dashboard.DELETE("/products/:product_id/variants/:variant_id", productHandler.RetireVariant)
+		dashboard.POST("/products/:product_id/variants/:variant_id/stock-adjustments", inventoryHandler.AdjustStock)
+		dashboard.PUT("/products/:product_id/variants/:variant_id/low-stock-threshold", inventoryHandler.SetVariantThreshold)
 		dashboard.POST("/products/:product_id/variants/:variant_id/images", productHandler.UploadVariantImage)
+		dashboard.POST("/product-imports", productHandler.StartImport)
+		dashboard.GET("/product-imports/:job_id", productHandler.GetImport)
+		dashboard.GET("/product-export", productHandler.ExportCatalog)
+
+		dashboard.GET("/orders", orderHandler.ListOrders)
+		dashboard.GET("/orders/:order_id", orderHandler.GetOrder)
+		dashboard.PATCH("/orders/:order_id/status", orderHandler.UpdateOrderStatus)
+		dashboard.POST("/orders/:order_id/refunds", orderHandler.RefundOrder)
+		dashboard.POST("/orders/:order_id/shipments", orderHandler.CreateShipment)
+		dashboard.PATCH("/orders/:order_id/shipments/:shipment_id/status", orderHandler.UpdateShipmentStatus)
+
+		dashboard.GET("/low-stock-threshold", inventoryHandler.GetStoreThreshold)
+		dashboard.PUT("/low-stock-threshold", inventoryHandler.SetStoreThreshold)
+		dashboard.GET("/stock-alerts", inventoryHandler.ListAlerts)
+		dashboard.POST("/stock-alerts/:alert_id/acknowledge", inventoryHandler.AcknowledgeAlert)
+		dashboard.POST("/stock-alerts/:alert_id/snooze", inventoryHandler.SnoozeAlert)
+
+		dashboard.GET("/analytics/overview", analyticsHandler.Overview)
+		dashboard.GET("/analytics/orders", analyticsHandler.Orders)
+		dashboard.GET("/analytics/customers", analyticsHandler.Customers)
+		dashboard.GET("/analytics/inventory", analyticsHandler.Inventory)
+		dashboard.GET("/analytics/funnel", analyticsHandler.Funnel)
 	}
 
 	// Admin-only routes
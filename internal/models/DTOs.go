@@ -3,6 +3,9 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/types"
+	"github.com/google/uuid"
 )
 
 type ProductFullDetailsDTO struct {
@@ -36,6 +39,32 @@ type ProductDTO struct {
 	InStock     bool           `json:"in_stock"`
 }
 
+// ProductFacetsDTO counts the products per filter value. Each facet applies
+// every active filter except its own.
+type ProductFacetsDTO struct {
+	Brands     []FacetCountDTO     `json:"brands"`
+	Prices     []PriceBucketDTO    `json:"prices"`
+	Attributes []AttributeFacetDTO `json:"attributes"`
+}
+
+type FacetCountDTO struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucketDTO covers prices in [Min, Max); nil means unbounded.
+type PriceBucketDTO struct {
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+type AttributeFacetDTO struct {
+	AttributeID int64           `json:"attribute_id"`
+	Name        string          `json:"name"`
+	Values      []FacetCountDTO `json:"values"`
+}
+
 type AttributeDTO struct {
 	AttributeID    int64  `json:"attribute_id"`
 	AttributeName  string `json:"attribute_name"`
@@ -63,6 +92,15 @@ type CartItemDTO struct {
 	Subtotal   string  `json:"subtotal"`
 }
 
+type SessionDTO struct {
+	SessionID   uuid.UUID  `json:"session_id"`
+	StoreID     int64      `json:"store_id"`
+	CustomerID  *int64     `json:"customer_id"`
+	FirstSeenAt *time.Time `json:"first_seen_at"`
+	LastSeenAt  *time.Time `json:"last_seen_at"`
+	IsReturning bool       `json:"is_returning"`
+}
+
 type CartDTO struct {
 	CartID    int64         `json:"cart_id"`
 	StoreID   int64         `json:"store_id"`
@@ -84,6 +122,14 @@ type VariantInput struct {
 	Attributes []VariantAttributeInput `json:"attributes"`
 }
 
+// UpdateVariantInput is a partial variant update: nil fields are left
+// unchanged. Attributes, when given, replace the variant's attribute set.
+type UpdateVariantInput struct {
+	SKU        *string                 `json:"sku"`
+	Price      *float64                `json:"price"`
+	Attributes []VariantAttributeInput `json:"attributes"`
+}
+
 type CreateProductInput struct {
 	CategoryID  int64        `json:"category_id"`
 	Name        string       `json:"name"`
@@ -93,6 +139,137 @@ type CreateProductInput struct {
 	Variant     VariantInput `json:"variant"`
 }
 
+// UpdateProductInput is a partial update: nil fields are left unchanged and
+// an empty slug, description or brand clears it. Deleted soft-deletes (true)
+// or restores (false) the product.
+type UpdateProductInput struct {
+	CategoryID  *int64  `json:"category_id"`
+	Name        *string `json:"name"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+	Brand       *string `json:"brand"`
+	Deleted     *bool   `json:"deleted"`
+}
+
+type InventoryMovementDTO struct {
+	MovementID     int64     `json:"movement_id"`
+	VariantID      int64     `json:"variant_id"`
+	QuantityChange int32     `json:"quantity_change"`
+	Reason         string    `json:"reason"`
+	ActorType      string    `json:"actor_type"`
+	ActorID        *int64    `json:"actor_id"`
+	OrderID        *int64    `json:"order_id"`
+	Note           *string   `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type StockAlertDTO struct {
+	AlertID        int64      `json:"alert_id"`
+	VariantID      int64      `json:"variant_id"`
+	ProductID      int64      `json:"product_id"`
+	ProductName    string     `json:"product_name"`
+	SKU            string     `json:"sku"`
+	CurrentStock   int32      `json:"current_stock"`
+	StockQuantity  int32      `json:"stock_quantity"`
+	Threshold      int32      `json:"threshold"`
+	Status         string     `json:"status"`
+	SnoozedUntil   *time.Time `json:"snoozed_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+// CatalogRow is one variant in the catalogue import/export format, with its
+// product's fields repeated on every row. Category and attributes are named,
+// not referenced by ID, so a catalogue can move between stores.
+type CatalogRow struct {
+	Category    string            `json:"category"`
+	Name        string            `json:"name"`
+	Slug        string            `json:"slug,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Brand       string            `json:"brand,omitempty"`
+	SKU         string            `json:"sku"`
+	Price       float64           `json:"price"`
+	Stock       int32             `json:"stock"`
+	ImageURL    string            `json:"image_url,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+type ImportJobDTO struct {
+	JobID         int64               `json:"job_id"`
+	Format        string              `json:"format"`
+	DryRun        bool                `json:"dry_run"`
+	Status        string              `json:"status"`
+	TotalRows     int32               `json:"total_rows"`
+	ProcessedRows int32               `json:"processed_rows"`
+	FailedRows    int32               `json:"failed_rows"`
+	Error         *string             `json:"error,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	FinishedAt    *time.Time          `json:"finished_at"`
+	Errors        []ImportRowErrorDTO `json:"errors"`
+}
+
+// ImportRowErrorDTO is a problem with one row; Row counts data rows from 1.
+type ImportRowErrorDTO struct {
+	Row     int32   `json:"row"`
+	Field   *string `json:"field,omitempty"`
+	Message string  `json:"message"`
+}
+
+type StoreCategoryDTO struct {
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name"`
+	ParentID   *int64 `json:"parent_id"`
+	Private    bool   `json:"private"`
+	Enabled    bool   `json:"enabled"`
+	Position   int32  `json:"position"`
+}
+
+// CategoryNodeDTO is a category in the storefront tree. ProductCount counts
+// the in-stock products of the category and all its subcategories.
+type CategoryNodeDTO struct {
+	CategoryID   int64             `json:"category_id"`
+	Name         string            `json:"name"`
+	ProductCount int64             `json:"product_count"`
+	Children     []CategoryNodeDTO `json:"children"`
+}
+
+// CategoryInput is a store-private category; a nil ParentID makes it a root.
+type CategoryInput struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type AttributeDefinitionDTO struct {
+	AttributeID int64  `json:"attribute_id"`
+	Name        string `json:"name"`
+	DataType    string `json:"data_type"`
+	Private     bool   `json:"private"`
+}
+
+// AttributeInput is a store-private attribute. Its type cannot change later.
+type AttributeInput struct {
+	Name     string `json:"name" binding:"required"`
+	DataType string `json:"data_type" binding:"required,oneof=text numeric enum boolean"`
+}
+
+// CategoryAttributeDTO is an attribute as attached to a category. A nil
+// AllowedValues accepts any value of the attribute's type.
+type CategoryAttributeDTO struct {
+	AttributeID   int64    `json:"attribute_id"`
+	Name          string   `json:"name"`
+	DataType      string   `json:"data_type"`
+	IsRequired    bool     `json:"is_required"`
+	AllowedValues []string `json:"allowed_values"`
+}
+
+// CategoryAttributeInput attaches an attribute to a category; an empty
+// AllowedValues accepts any value of the attribute's type.
+type CategoryAttributeInput struct {
+	IsRequired    bool     `json:"is_required"`
+	AllowedValues []string `json:"allowed_values"`
+}
+
 type StoreDTO struct {
 	StoreID      int64     `json:"store_id"`
 	StoreOwnerID int64     `json:"store_owner_id"`
@@ -103,3 +280,195 @@ type StoreDTO struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+type AnalyticsPeriodDTO struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Timezone string    `json:"timezone"`
+}
+
+type RevenuePointDTO struct {
+	Date    time.Time `json:"date"`
+	Revenue string    `json:"revenue"`
+	Orders  int64     `json:"orders"`
+}
+
+type TopSellingProductDTO struct {
+	ProductName string `json:"product_name"`
+	UnitsSold   int64  `json:"units_sold"`
+	Revenue     string `json:"revenue"`
+}
+
+type AnalyticsOverviewDTO struct {
+	Period               AnalyticsPeriodDTO     `json:"period"`
+	TotalRevenue         string                 `json:"total_revenue"`
+	RevenueGrowthPercent *string                `json:"revenue_growth_percent"`
+	NewOrders            int64                  `json:"new_orders"`
+	OrdersGrowthPercent  *string                `json:"orders_growth_percent"`
+	TotalVisitors        int64                  `json:"total_visitors"`
+	PageViews            int64                  `json:"page_views"`
+	RegisteredCustomers  int64                  `json:"registered_customers"`
+	NewCustomers         int64                  `json:"new_customers"`
+	ConversionRate       float64                `json:"conversion_rate"`
+	RevenueOverTime      []RevenuePointDTO      `json:"revenue_over_time"`
+	TopSellingProducts   []TopSellingProductDTO `json:"top_selling_products"`
+}
+
+type AnalyticsOrdersDTO struct {
+	Period              AnalyticsPeriodDTO `json:"period"`
+	TotalOrders         int64              `json:"total_orders"`
+	PendingOrders       int64              `json:"pending_orders"`
+	ShippedOrders       int64              `json:"shipped_orders"`
+	CompletedOrders     int64              `json:"completed_orders"`
+	NewOrders           int64              `json:"new_orders"`
+	OrdersGrowthPercent *string            `json:"orders_growth_percent"`
+	AverageDeliveryDays string             `json:"average_delivery_days"`
+}
+
+type LoyalCustomerDTO struct {
+	CustomerName string `json:"customer_name"`
+	TotalSpent   string `json:"total_spent"`
+	OrdersCount  int64  `json:"orders_count"`
+}
+
+type AnalyticsCustomersDTO struct {
+	Period                           AnalyticsPeriodDTO `json:"period"`
+	RegisteredCustomers              int64              `json:"registered_customers"`
+	NewCustomers                     int64              `json:"new_customers"`
+	CustomersGrowthPercent           *string            `json:"customers_growth_percent"`
+	PurchasingCustomers              int64              `json:"purchasing_customers"`
+	NewPurchasingCustomers           int64              `json:"new_purchasing_customers"`
+	PurchasingCustomersGrowthPercent *string            `json:"purchasing_customers_growth_percent"`
+	LoyalCustomers                   []LoyalCustomerDTO `json:"loyal_customers"`
+}
+
+type LowStockProductDTO struct {
+	Name          string     `json:"name"`
+	StockQuantity int32      `json:"stock_quantity"`
+	StockStatus   string     `json:"stock_status"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+}
+
+type ProductPerformanceDTO struct {
+	ProductName          string  `json:"product_name"`
+	UnitsSold            string  `json:"units_sold"`
+	TotalViews           string  `json:"total_views"`
+	ViewsToPurchaseRatio *string `json:"views_to_purchase_ratio"`
+	StockStatus          string  `json:"stock_status"`
+}
+
+type ProductSalesDTO struct {
+	ProductName string `json:"product_name"`
+	UnitsSold   string `json:"units_sold"`
+	Revenue     string `json:"revenue"`
+}
+
+type PopularProductDTO struct {
+	ProductID  int64 `json:"product_id"`
+	Views      int64 `json:"views"`
+	SalesCount int64 `json:"sales_count"`
+}
+
+type AnalyticsInventoryDTO struct {
+	Period                   AnalyticsPeriodDTO      `json:"period"`
+	TotalProducts            int64                   `json:"total_products"`
+	LowAndOutOfStockProducts int64                   `json:"low_and_out_of_stock_products"`
+	LowStockProducts         []LowStockProductDTO    `json:"low_stock_products"`
+	Products                 []ProductPerformanceDTO `json:"products"`
+	NeedingAttention         []ProductSalesDTO       `json:"needing_attention"`
+	PopularButNotSelling     []PopularProductDTO     `json:"popular_but_not_selling"`
+}
+
+type ConversionPointDTO struct {
+	Date                  time.Time `json:"date"`
+	ConversionRatePercent string    `json:"conversion_rate_percent"`
+}
+
+type AnalyticsFunnelDTO struct {
+	Period                    AnalyticsPeriodDTO   `json:"period"`
+	SiteVisits                int64                `json:"site_visits"`
+	ProductViews              int64                `json:"product_views"`
+	AddedToCart               int64                `json:"added_to_cart"`
+	CheckoutStarted           int64                `json:"checkout_started"`
+	PurchaseComplete          int64                `json:"purchase_complete"`
+	DropSiteToViewPct         string               `json:"drop_site_to_view_pct"`
+	DropViewToCartPct         string               `json:"drop_view_to_cart_pct"`
+	DropCartToCheckoutPct     string               `json:"drop_cart_to_checkout_pct"`
+	DropCheckoutToPurchasePct string               `json:"drop_checkout_to_purchase_pct"`
+	ConversionOverTime        []ConversionPointDTO `json:"conversion_over_time"`
+}
+
+type OrderSummaryDTO struct {
+	OrderID       int64      `json:"order_id"`
+	CustomerID    *int64     `json:"customer_id"`
+	CustomerName  *string    `json:"customer_name"`
+	CustomerEmail *string    `json:"customer_email"`
+	TotalAmount   string     `json:"total_amount"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+}
+
+type OrderItemDTO struct {
+	OrderItemID int64   `json:"order_item_id"`
+	VariantID   int64   `json:"variant_id"`
+	ProductID   int64   `json:"product_id"`
+	Product     string  `json:"product_name"`
+	SKU         string  `json:"sku"`
+	ImageURL    *string `json:"image_url"`
+	Quantity    int32   `json:"quantity"`
+	UnitPrice   string  `json:"unit_price"`
+	Subtotal    string  `json:"subtotal"`
+	Refunded    int32   `json:"refunded_quantity"`
+}
+
+type PaymentDTO struct {
+	PaymentID      int64     `json:"payment_id"`
+	Method         string    `json:"method"`
+	Amount         string    `json:"amount"`
+	Status         string    `json:"status"`
+	TransactionRef *string   `json:"transaction_ref"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CheckoutDTO is returned when checkout reserves stock and opens a payment.
+// The order stays pending until the payment is confirmed or the reservation
+// expires at ReservedUntil.
+type CheckoutDTO struct {
+	OrderID       int64      `json:"order_id"`
+	Status        string     `json:"status"`
+	TotalAmount   string     `json:"total_amount"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReservedUntil time.Time  `json:"reserved_until"`
+	Payment       PaymentDTO `json:"payment"`
+}
+
+type ShipmentDTO struct {
+	ShipmentID     int64             `json:"shipment_id"`
+	TrackingNumber *string           `json:"tracking_number"`
+	Carrier        *string           `json:"carrier"`
+	ShippedAt      *time.Time        `json:"shipped_at"`
+	DeliveredAt    *time.Time        `json:"delivered_at"`
+	Status         string            `json:"status"`
+	Items          []ShipmentItemDTO `json:"items"`
+}
+
+type ShipmentItemDTO struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Quantity    int32 `json:"quantity"`
+}
+
+type OrderDetailDTO struct {
+	OrderID         int64          `json:"order_id"`
+	StoreID         int64          `json:"store_id"`
+	CustomerID      *int64         `json:"customer_id"`
+	TotalAmount     string         `json:"total_amount"`
+	Status          string         `json:"status"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       *time.Time     `json:"updated_at"`
+	Email           *string        `json:"email"`
+	ShippingAddress *types.Address `json:"shipping_address"`
+	Items           []OrderItemDTO `json:"items"`
+	Payments        []PaymentDTO   `json:"payments"`
+	Shipments       []ShipmentDTO  `json:"shipments"`
+}
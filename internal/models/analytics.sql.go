@@ -13,16 +13,25 @@ import (
 
 const getAverageDeliveryDays = `-- name: GetAverageDeliveryDays :one
 
-SELECT ROUND(AVG(EXTRACT(EPOCH
-                         FROM (s.delivered_at - s.shipped_at))/86400), 2) AS avg_delivery_days
+SELECT COALESCE(ROUND(AVG(EXTRACT(EPOCH
+                                  FROM (s.delivered_at - s.shipped_at))/86400), 2), 0)::NUMERIC AS avg_delivery_days
 FROM shipment s
 JOIN customer_order o ON o.order_id = s.order_id
 WHERE o.store_id = $1
-  AND s.delivered_at IS NOT NULL
+  AND s.shipped_at IS NOT NULL
+  AND s.delivered_at >= $2
+  AND s.delivered_at < $3
 `
 
-func (q *Queries) GetAverageDeliveryDays(ctx context.Context, storeID int64) (string, error) {
-	row := q.db.QueryRowContext(ctx, getAverageDeliveryDays, storeID)
+type GetAverageDeliveryDaysParams struct {
+	StoreID       int64
+	DeliveredAt   sql.NullTime
+	DeliveredAt_2 sql.NullTime
+}
+
+// Shipments delivered in the period
+func (q *Queries) GetAverageDeliveryDays(ctx context.Context, arg GetAverageDeliveryDaysParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getAverageDeliveryDays, arg.StoreID, arg.DeliveredAt, arg.DeliveredAt_2)
 	var avg_delivery_days string
 	err := row.Scan(&avg_delivery_days)
 	return avg_delivery_days, err
@@ -34,25 +43,32 @@ SELECT COUNT(*) AS completed_orders
 FROM customer_order co
 WHERE co.store_id = $1
   AND co.status = 'completed'
+  AND co.created_at >= $2
+  AND co.created_at < $3
 `
 
-func (q *Queries) GetCompletedOrders(ctx context.Context, storeID int64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getCompletedOrders, storeID)
+type GetCompletedOrdersParams struct {
+	StoreID     int64
+	CreatedAt   time.Time
+	CreatedAt_2 time.Time
+}
+
+func (q *Queries) GetCompletedOrders(ctx context.Context, arg GetCompletedOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getCompletedOrders, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
 	var completed_orders int64
 	err := row.Scan(&completed_orders)
 	return completed_orders, err
 }
 
 const getConversionOverTime = `-- name: GetConversionOverTime :many
-
-SELECT DATE(vs.first_seen_at) AS DAY,
+SELECT DATE(vs.first_seen_at AT TIME ZONE $1::TEXT) AS DAY,
        ROUND(COUNT(DISTINCT co.session_id)::numeric / NULLIF(COUNT(DISTINCT vs.session_id), 0) * 100, 2) AS conversion_rate_percent
 FROM visitor_session vs
 LEFT JOIN customer_order co ON vs.session_id = co.session_id
 AND co.created_at >= $2
 AND co.created_at < $3
-AND co.status = 'completed'
-WHERE vs.store_id = $1
+AND co.status IN ('completed', 'shipped', 'delivered')
+WHERE vs.store_id = $4
   AND vs.first_seen_at >= $2
   AND vs.first_seen_at < $3
 GROUP BY DAY
@@ -60,9 +76,10 @@ ORDER BY DAY
 `
 
 type GetConversionOverTimeParams struct {
-	StoreID     int64
-	CreatedAt   time.Time
-	CreatedAt_2 time.Time
+	Timezone string
+	FromTime time.Time
+	ToTime   time.Time
+	StoreID  int64
 }
 
 type GetConversionOverTimeRow struct {
@@ -70,8 +87,14 @@ type GetConversionOverTimeRow struct {
 	ConversionRatePercent string
 }
 
+// Days are calendar days in the given timezone, the store's.
 func (q *Queries) GetConversionOverTime(ctx context.Context, arg GetConversionOverTimeParams) ([]GetConversionOverTimeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getConversionOverTime, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
+	rows, err := q.db.QueryContext(ctx, getConversionOverTime,
+		arg.Timezone,
+		arg.FromTime,
+		arg.ToTime,
+		arg.StoreID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -95,14 +118,14 @@ func (q *Queries) GetConversionOverTime(ctx context.Context, arg GetConversionOv
 
 const getConversionRate = `-- name: GetConversionRate :one
 
-SELECT COUNT(DISTINCT co.order_id)::FLOAT / NULLIF(COUNT(DISTINCT vs.session_id), 0) AS conversion_rate
+SELECT COALESCE(COUNT(DISTINCT co.order_id)::FLOAT / NULLIF(COUNT(DISTINCT vs.session_id), 0), 0)::FLOAT AS conversion_rate
 FROM visitor_session vs
 LEFT JOIN customer_order co ON vs.customer_id = co.customer_id
 AND co.store_id = $1
-AND co.status = 'completed'
-AND co.created_at BETWEEN $2 AND $3
+AND co.status IN ('completed', 'shipped', 'delivered')
+AND co.created_at >= $2 AND co.created_at < $3
 WHERE vs.store_id = $1
-  AND vs.first_seen_at BETWEEN $2 AND $3
+  AND vs.first_seen_at >= $2 AND vs.first_seen_at < $3
 `
 
 type GetConversionRateParams struct {
@@ -111,9 +134,9 @@ type GetConversionRateParams struct {
 	CreatedAt_2 time.Time
 }
 
-func (q *Queries) GetConversionRate(ctx context.Context, arg GetConversionRateParams) (int32, error) {
+func (q *Queries) GetConversionRate(ctx context.Context, arg GetConversionRateParams) (float64, error) {
 	row := q.db.QueryRowContext(ctx, getConversionRate, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
-	var conversion_rate int32
+	var conversion_rate float64
 	err := row.Scan(&conversion_rate)
 	return conversion_rate, err
 }
@@ -122,9 +145,10 @@ const getCountOfLowAndOutOfStockProducts = `-- name: GetCountOfLowAndOutOfStockP
 
 SELECT COUNT(*) AS low_out_stock_products
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
-  AND p.stock_quantity <= 10
+  AND p.stock_quantity <= s.low_stock_threshold
 `
 
 func (q *Queries) GetCountOfLowAndOutOfStockProducts(ctx context.Context, storeID int64) (int64, error) {
@@ -211,7 +235,7 @@ WITH visits AS
   (SELECT vs.session_id
    FROM visitor_session vs
    WHERE vs.store_id = $1
-     AND vs.first_seen_at BETWEEN $2 AND $3),
+     AND vs.first_seen_at >= $2 AND vs.first_seen_at < $3),
      product_views AS
   (SELECT DISTINCT pv.session_id
    FROM product_view pv
@@ -225,13 +249,13 @@ WITH visits AS
   (SELECT DISTINCT co.session_id
    FROM customer_order co
    JOIN added_to_cart ac ON ac.session_id = co.session_id
-   WHERE co.created_at BETWEEN $2 AND $3),
+   WHERE co.created_at >= $2 AND co.created_at < $3),
      purchase_complete AS
   (SELECT DISTINCT co.session_id
    FROM customer_order co
    JOIN checkout_started cs ON cs.session_id = co.session_id
-   WHERE co.status = 'completed'
-     AND co.created_at BETWEEN $2 AND $3)
+   WHERE co.status IN ('completed', 'shipped', 'delivered')
+     AND co.created_at >= $2 AND co.created_at < $3)
 SELECT
   (SELECT COUNT(*)
    FROM visits) AS site_visits,
@@ -247,34 +271,34 @@ SELECT
 
   (SELECT COUNT(*)
    FROM purchase_complete) AS purchase_complete,
-       ROUND((
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM visits) -
                 (SELECT COUNT(*)
                  FROM product_views))::numeric / NULLIF(
                                                           (SELECT COUNT(*)
-                                                           FROM visits),0) * 100, 2) AS drop_site_to_view_pct,
-       ROUND((
+                                                           FROM visits),0) * 100, 2), 0)::NUMERIC AS drop_site_to_view_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM product_views) -
                 (SELECT COUNT(*)
                  FROM added_to_cart))::numeric / NULLIF(
                                                           (SELECT COUNT(*)
-                                                           FROM product_views),0) * 100, 2) AS drop_view_to_cart_pct,
-       ROUND((
+                                                           FROM product_views),0) * 100, 2), 0)::NUMERIC AS drop_view_to_cart_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM added_to_cart) -
                 (SELECT COUNT(*)
                  FROM checkout_started))::numeric / NULLIF(
                                                              (SELECT COUNT(*)
-                                                              FROM added_to_cart),0) * 100, 2) AS drop_cart_to_checkout_pct,
-       ROUND((
+                                                              FROM added_to_cart),0) * 100, 2), 0)::NUMERIC AS drop_cart_to_checkout_pct,
+       COALESCE(ROUND((
                 (SELECT COUNT(*)
                  FROM checkout_started) -
                 (SELECT COUNT(*)
                  FROM purchase_complete))::numeric / NULLIF(
                                                               (SELECT COUNT(*)
-                                                               FROM checkout_started),0) * 100, 2) AS drop_checkout_to_purchase_pct
+                                                               FROM checkout_started),0) * 100, 2), 0)::NUMERIC AS drop_checkout_to_purchase_pct
 `
 
 type GetFunnelMetricsParams struct {
@@ -315,12 +339,13 @@ func (q *Queries) GetFunnelMetrics(ctx context.Context, arg GetFunnelMetricsPara
 const getLoyalCustomers = `-- name: GetLoyalCustomers :many
 
 SELECT c.name AS customer_name,
-       SUM(co.total_amount) AS total_spent,
+       SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded'))::NUMERIC AS total_spent,
        COUNT(co.order_id) AS orders_count
 FROM customer c
 JOIN customer_order co ON c.customer_id = co.customer_id
 WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= $2 AND co.created_at < $3
 GROUP BY c.customer_id,
          c.name
 ORDER BY orders_count DESC
@@ -335,7 +360,7 @@ type GetLoyalCustomersParams struct {
 
 type GetLoyalCustomersRow struct {
 	CustomerName string
-	TotalSpent   int64
+	TotalSpent   string
 	OrdersCount  int64
 }
 
@@ -415,6 +440,7 @@ FROM visitor_session vs
 WHERE vs.store_id = $1
   AND vs.first_seen_at >= $2
   AND vs.first_seen_at < $3
+  AND NOT COALESCE(vs.is_returning, FALSE)
 `
 
 type GetNewVisitorsParams struct {
@@ -435,14 +461,14 @@ WITH current_period AS
   (SELECT COALESCE(COUNT(*), 0) AS orders_count
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= $2
      AND co.created_at < $3),
      previous_period AS
   (SELECT COALESCE(COUNT(*), 0) AS orders_count
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= ($2 - ($3 - $2))
      AND co.created_at < $2)
 SELECT CASE
@@ -471,7 +497,7 @@ const getPageViews = `-- name: GetPageViews :one
 SELECT COUNT(pv.product_view_id) AS total_page_views
 FROM product_view pv
 WHERE pv.store_id = $1
-  AND pv.viewed_at BETWEEN $2 AND $3
+  AND pv.viewed_at >= $2 AND pv.viewed_at < $3
 `
 
 type GetPageViewsParams struct {
@@ -493,10 +519,18 @@ SELECT COUNT(*) AS pending_orders
 FROM customer_order co
 WHERE co.store_id = $1
   AND co.status = 'pending'
+  AND co.created_at >= $2
+  AND co.created_at < $3
 `
 
-func (q *Queries) GetPendingOrders(ctx context.Context, storeID int64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getPendingOrders, storeID)
+type GetPendingOrdersParams struct {
+	StoreID     int64
+	CreatedAt   time.Time
+	CreatedAt_2 time.Time
+}
+
+func (q *Queries) GetPendingOrders(ctx context.Context, arg GetPendingOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getPendingOrders, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
 	var pending_orders int64
 	err := row.Scan(&pending_orders)
 	return pending_orders, err
@@ -509,7 +543,7 @@ WITH views AS (
         COUNT(*) AS views
     FROM product_view pv
     WHERE pv.store_id = $1
-      AND pv.viewed_at BETWEEN $2 AND $3
+      AND pv.viewed_at >= $2 AND pv.viewed_at < $3
     GROUP BY pv.product_id
 ),
 sales AS (
@@ -522,8 +556,8 @@ sales AS (
     JOIN customer_order
       ON customer_order.order_id = order_item.order_id
     WHERE product_variant.store_id = $1
-      AND customer_order.status = 'completed'
-      AND customer_order.created_at BETWEEN $2 AND $3
+      AND customer_order.status IN ('completed', 'shipped', 'delivered')
+      AND customer_order.created_at >= $2 AND customer_order.created_at < $3
     GROUP BY product_variant.product_id
 )
 SELECT
@@ -575,13 +609,13 @@ func (q *Queries) GetPopularButNotSellingProducts(ctx context.Context, arg GetPo
 const getProductsNeedingAttention = `-- name: GetProductsNeedingAttention :many
 WITH product_sales AS
   (SELECT pv.product_id,
-          COALESCE(SUM(oi.quantity), 0) AS units_sold,
-          COALESCE(SUM(oi.subtotal), 0) AS revenue
+          COALESCE(SUM(oi.quantity - oi.refunded_quantity), 0) AS units_sold,
+          COALESCE(SUM(oi.subtotal - oi.unit_price * oi.refunded_quantity), 0) AS revenue
    FROM product_variant pv
    LEFT JOIN order_item oi ON pv.variant_id = oi.variant_id
    LEFT JOIN customer_order co ON oi.order_id = co.order_id
    AND co.store_id = $1
-   AND co.created_at BETWEEN $2 AND $3
+   AND co.created_at >= $2 AND co.created_at < $3
    WHERE pv.store_id = $1
    GROUP BY pv.product_id)
 SELECT p.name AS product_name,
@@ -635,7 +669,7 @@ const getPurchasingCustomers = `-- name: GetPurchasingCustomers :one
 SELECT COUNT(DISTINCT co.customer_id) AS purchasing_customers
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'completed'
+  AND co.status IN ('completed', 'shipped', 'delivered')
   AND co.customer_id IS NOT NULL
 `
 
@@ -703,17 +737,17 @@ func (q *Queries) GetRegisteredCustomers(ctx context.Context, storeID int64) (in
 
 const getRevenueGrowthPercent = `-- name: GetRevenueGrowthPercent :one
 WITH current_period AS
-  (SELECT COALESCE(SUM(co.total_amount), 0) AS revenue
+  (SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS revenue
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= $2
      AND co.created_at < $3),
      previous_period AS
-  (SELECT COALESCE(SUM(co.total_amount), 0) AS revenue
+  (SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS revenue
    FROM customer_order co
    WHERE co.store_id = $1
-     AND co.status = 'completed'
+     AND co.status IN ('completed', 'shipped', 'delivered')
      AND co.created_at >= ($2 - ($3 - $2))
      AND co.created_at < $2)
 SELECT CASE
@@ -738,31 +772,39 @@ func (q *Queries) GetRevenueGrowthPercent(ctx context.Context, arg GetRevenueGro
 }
 
 const getRevenueOverTime = `-- name: GetRevenueOverTime :many
-
-SELECT DATE(co.created_at) AS order_date,
-       SUM(co.total_amount) AS revenue,
+SELECT DATE(co.created_at AT TIME ZONE $1::TEXT) AS order_date,
+       SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded'))::NUMERIC AS revenue,
        COUNT(DISTINCT co.order_id) AS total_orders
 FROM customer_order co
-WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
-GROUP BY DATE(co.created_at)
+WHERE co.store_id = $2
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= $3
+  AND co.created_at < $4
+GROUP BY order_date
 ORDER BY order_date
 `
 
 type GetRevenueOverTimeParams struct {
-	StoreID     int64
-	CreatedAt   time.Time
-	CreatedAt_2 time.Time
+	Timezone string
+	StoreID  int64
+	FromTime time.Time
+	ToTime   time.Time
 }
 
 type GetRevenueOverTimeRow struct {
 	OrderDate   time.Time
-	Revenue     int64
+	Revenue     string
 	TotalOrders int64
 }
 
+// Days are calendar days in the given timezone, the store's.
 func (q *Queries) GetRevenueOverTime(ctx context.Context, arg GetRevenueOverTimeParams) ([]GetRevenueOverTimeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRevenueOverTime, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
+	rows, err := q.db.QueryContext(ctx, getRevenueOverTime,
+		arg.Timezone,
+		arg.StoreID,
+		arg.FromTime,
+		arg.ToTime,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -790,10 +832,18 @@ SELECT COUNT(*) AS shipped_orders
 FROM customer_order co
 WHERE co.store_id = $1
   AND co.status = 'shipped'
+  AND co.created_at >= $2
+  AND co.created_at < $3
 `
 
-func (q *Queries) GetShippedOrders(ctx context.Context, storeID int64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getShippedOrders, storeID)
+type GetShippedOrdersParams struct {
+	StoreID     int64
+	CreatedAt   time.Time
+	CreatedAt_2 time.Time
+}
+
+func (q *Queries) GetShippedOrders(ctx context.Context, arg GetShippedOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getShippedOrders, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
 	var shipped_orders int64
 	err := row.Scan(&shipped_orders)
 	return shipped_orders, err
@@ -802,14 +852,15 @@ func (q *Queries) GetShippedOrders(ctx context.Context, storeID int64) (int64, e
 const getTopSellingProducts = `-- name: GetTopSellingProducts :many
 
 SELECT p.name AS product_name,
-       SUM(oi.quantity) AS units_sold,
-       SUM(oi.subtotal) AS revenue
+       SUM(oi.quantity - oi.refunded_quantity) AS units_sold,
+       SUM(oi.subtotal - oi.unit_price * oi.refunded_quantity)::NUMERIC AS revenue
 FROM order_item oi
 JOIN product_variant pv ON oi.variant_id = pv.variant_id
 JOIN product p ON pv.product_id = p.product_id
 JOIN customer_order co ON oi.order_id = co.order_id
 WHERE co.store_id = $1
-  AND co.created_at BETWEEN $2 AND $3
+  AND co.status IN ('completed', 'shipped', 'delivered')
+  AND co.created_at >= $2 AND co.created_at < $3
 GROUP BY p.product_id,
          p.name
 ORDER BY revenue DESC
@@ -825,7 +876,7 @@ type GetTopSellingProductsParams struct {
 type GetTopSellingProductsRow struct {
 	ProductName string
 	UnitsSold   int64
-	Revenue     int64
+	Revenue     string
 }
 
 func (q *Queries) GetTopSellingProducts(ctx context.Context, arg GetTopSellingProductsParams) ([]GetTopSellingProductsRow, error) {
@@ -856,11 +907,19 @@ const getTotalOrders = `-- name: GetTotalOrders :one
 SELECT COUNT(*) AS total_orders
 FROM customer_order co
 WHERE co.store_id = $1
+  AND co.created_at >= $2
+  AND co.created_at < $3
 `
 
-// Orders page
-func (q *Queries) GetTotalOrders(ctx context.Context, storeID int64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getTotalOrders, storeID)
+type GetTotalOrdersParams struct {
+	StoreID     int64
+	CreatedAt   time.Time
+	CreatedAt_2 time.Time
+}
+
+// Orders page; the status counters cover orders placed in the period
+func (q *Queries) GetTotalOrders(ctx context.Context, arg GetTotalOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getTotalOrders, arg.StoreID, arg.CreatedAt, arg.CreatedAt_2)
 	var total_orders int64
 	err := row.Scan(&total_orders)
 	return total_orders, err
@@ -883,10 +942,10 @@ func (q *Queries) GetTotalProducts(ctx context.Context, storeID int64) (int64, e
 
 const getTotalRevenueCurrentMonth = `-- name: GetTotalRevenueCurrentMonth :one
 
-SELECT COALESCE(SUM(co.total_amount), 0) AS total_revenue
+SELECT COALESCE(SUM(co.total_amount - (SELECT COALESCE(SUM(r.amount), 0) FROM payment r WHERE r.order_id = co.order_id AND r.status = 'refunded')), 0) AS total_revenue
 FROM customer_order co
 WHERE co.store_id = $1
-  AND co.status = 'completed'
+  AND co.status IN ('completed', 'shipped', 'delivered')
   AND co.created_at >= $2
   AND co.created_at < $3
 `
@@ -934,13 +993,14 @@ SELECT p.name,
        p.stock_quantity,
        CASE
            WHEN p.stock_quantity = 0 THEN 'OUT_OF_STOCK'
-           WHEN p.stock_quantity BETWEEN 1 AND 10 THEN 'LOW_STOCK'
+           WHEN p.stock_quantity <= s.low_stock_threshold THEN 'LOW_STOCK'
        END AS stock_status,
        p.updated_at
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
-  AND p.stock_quantity <= 10
+  AND p.stock_quantity <= s.low_stock_threshold
 ORDER BY p.stock_quantity ASC,
          p.updated_at DESC
 LIMIT $2
@@ -997,10 +1057,11 @@ SELECT p.name AS product_name,
        END AS views_to_purchase_ratio,
        CASE
            WHEN COALESCE(SUM(pv.stock_quantity), 0) = 0 THEN 'out of stock'
-           WHEN COALESCE(SUM(pv.stock_quantity), 0) <= 10 THEN 'low stock'
+           WHEN COALESCE(SUM(pv.stock_quantity), 0) <= s.low_stock_threshold THEN 'low stock'
            ELSE 'in stock'
        END AS stock_status
 FROM product p
+JOIN store s ON s.store_id = p.store_id
 LEFT JOIN product_variant pv ON pv.product_id = p.product_id
 LEFT JOIN order_item oi ON oi.variant_id = pv.variant_id
 LEFT JOIN customer_order o ON o.order_id = oi.order_id
@@ -1014,7 +1075,8 @@ LEFT JOIN
 WHERE p.store_id = $1
   AND p.deleted_at IS NULL
 GROUP BY p.product_id,
-         p.name
+         p.name,
+         s.low_stock_threshold
 ORDER BY p.name ASC
 `
 
@@ -23,6 +23,8 @@ type Admin struct {
 type AttributeDefinition struct {
 	AttributeID int64
 	Name        string
+	DataType    string
+	StoreID     sql.NullInt64
 }
 
 type Cart struct {
@@ -53,15 +55,17 @@ type CartItem struct {
 }
 
 type CategoryAttribute struct {
-	CategoryID  int64
-	AttributeID int64
-	IsRequired  bool
+	CategoryID    int64
+	AttributeID   int64
+	IsRequired    bool
+	AllowedValues []string
 }
 
 type CategoryDefinition struct {
 	CategoryID int64
 	Name       string
 	ParentID   sql.NullInt64
+	StoreID    sql.NullInt64
 }
 
 type Customer struct {
@@ -76,23 +80,39 @@ type Customer struct {
 }
 
 type CustomerOrder struct {
-	OrderID     int64
-	StoreID     int64
-	CustomerID  sql.NullInt64
-	SessionID   uuid.UUID
-	TotalAmount string
-	Status      sql.NullString
-	CreatedAt   time.Time
-	UpdatedAt   sql.NullTime
+	OrderID         int64
+	StoreID         int64
+	CustomerID      sql.NullInt64
+	SessionID       uuid.UUID
+	TotalAmount     string
+	Status          sql.NullString
+	CreatedAt       time.Time
+	UpdatedAt       sql.NullTime
+	Email           sql.NullString
+	ShippingAddress types.NullableAddress
+}
+
+type InventoryMovement struct {
+	MovementID     int64
+	StoreID        int64
+	VariantID      int64
+	QuantityChange int32
+	Reason         string
+	ActorType      string
+	ActorID        sql.NullInt64
+	OrderID        sql.NullInt64
+	Note           sql.NullString
+	CreatedAt      time.Time
 }
 
 type OrderItem struct {
-	OrderItemID int64
-	OrderID     int64
-	VariantID   int64
-	Quantity    int32
-	UnitPrice   string
-	Subtotal    string
+	OrderItemID      int64
+	OrderID          int64
+	VariantID        int64
+	Quantity         int32
+	UnitPrice        string
+	Subtotal         string
+	RefundedQuantity int32
 }
 
 type Payment struct {
@@ -121,18 +141,46 @@ type Product struct {
 	DefaultVariantID sql.NullInt64
 }
 
+type ProductImportError struct {
+	ErrorID   int64
+	JobID     int64
+	RowNumber int32
+	Field     sql.NullString
+	Message   string
+}
+
+type ProductImportJob struct {
+	JobID         int64
+	StoreID       int64
+	Format        string
+	DryRun        bool
+	Status        string
+	TotalRows     int32
+	ProcessedRows int32
+	FailedRows    int32
+	Error         sql.NullString
+	CreatedAt     time.Time
+	FinishedAt    sql.NullTime
+}
+
+type ProductSearch struct {
+	ProductID int64
+	Document  interface{}
+}
+
 type ProductVariant struct {
-	VariantID       int64
-	ProductID       int64
-	StoreID         int64
-	AttributeHash   string
-	Sku             string
-	Price           string
-	StockQuantity   int32
-	PrimaryImageUrl sql.NullString
-	CreatedAt       time.Time
-	UpdatedAt       sql.NullTime
-	DeletedAt       sql.NullTime
+	VariantID         int64
+	ProductID         int64
+	StoreID           int64
+	AttributeHash     string
+	Sku               string
+	Price             string
+	StockQuantity     int32
+	PrimaryImageUrl   sql.NullString
+	CreatedAt         time.Time
+	UpdatedAt         sql.NullTime
+	DeletedAt         sql.NullTime
+	LowStockThreshold sql.NullInt32
 }
 
 type ProductVariantImage struct {
@@ -171,21 +219,52 @@ type Shipment struct {
 	Status         sql.NullString
 }
 
+type ShipmentItem struct {
+	ShipmentID  int64
+	OrderItemID int64
+	Quantity    int32
+}
+
+type StockReservation struct {
+	ReservationID int64
+	OrderID       int64
+	VariantID     int64
+	Quantity      int32
+	Status        string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
 type Store struct {
+	StoreID           int64
+	StoreOwnerID      int64
+	Name              string
+	Domain            sql.NullString
+	DownloadStatus    string
+	Currency          sql.NullString
+	Timezone          sql.NullString
+	LowStockThreshold int32
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type StockAlert struct {
+	AlertID        int64
 	StoreID        int64
-	StoreOwnerID   int64
-	Name           string
-	Domain         sql.NullString
-	DownloadStatus string
-	Currency       sql.NullString
-	Timezone       sql.NullString
+	VariantID      int64
+	StockQuantity  int32
+	Threshold      int32
+	Status         string
+	SnoozedUntil   sql.NullTime
 	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	AcknowledgedAt sql.NullTime
+	ResolvedAt     sql.NullTime
 }
 
 type StoreCategory struct {
 	StoreID    int64
 	CategoryID int64
+	Position   int32
 }
 
 type StoreOwner struct {
@@ -12,8 +12,79 @@ import (
 
 	"github.com/Secure-Website-Builder/Backend/internal/types"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
+const acknowledgeStockAlert = `-- name: AcknowledgeStockAlert :execrows
+UPDATE stock_alert
+SET status = 'acknowledged',
+    acknowledged_at = COALESCE(acknowledged_at, NOW())
+WHERE alert_id = $1
+  AND store_id = $2
+  AND status <> 'resolved'
+`
+
+type AcknowledgeStockAlertParams struct {
+	AlertID int64
+	StoreID int64
+}
+
+func (q *Queries) AcknowledgeStockAlert(ctx context.Context, arg AcknowledgeStockAlertParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, acknowledgeStockAlert, arg.AlertID, arg.StoreID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addShipmentItem = `-- name: AddShipmentItem :exec
+INSERT INTO shipment_item (
+  shipment_id, order_item_id, quantity
+) VALUES (
+  $1, $2, $3
+)
+`
+
+type AddShipmentItemParams struct {
+	ShipmentID  int64
+	OrderItemID int64
+	Quantity    int32
+}
+
+func (q *Queries) AddShipmentItem(ctx context.Context, arg AddShipmentItemParams) error {
+	_, err := q.db.ExecContext(ctx, addShipmentItem, arg.ShipmentID, arg.OrderItemID, arg.Quantity)
+	return err
+}
+
+const adjustVariantStock = `-- name: AdjustVariantStock :one
+UPDATE product_variant
+SET stock_quantity = stock_quantity + $1,
+    updated_at = NOW()
+WHERE variant_id = $2
+  AND stock_quantity + $1 >= 0
+RETURNING product_id, stock_quantity, (deleted_at IS NOT NULL) AS retired
+`
+
+type AdjustVariantStockParams struct {
+	QuantityChange int32
+	VariantID      int64
+}
+
+type AdjustVariantStockRow struct {
+	ProductID     int64
+	StockQuantity int32
+	Retired       bool
+}
+
+// Applies a signed change; no row comes back when stock would go negative.
+func (q *Queries) AdjustVariantStock(ctx context.Context, arg AdjustVariantStockParams) (AdjustVariantStockRow, error) {
+	row := q.db.QueryRowContext(ctx, adjustVariantStock, arg.QuantityChange, arg.VariantID)
+	var i AdjustVariantStockRow
+	err := row.Scan(&i.ProductID, &i.StockQuantity, &i.Retired)
+	return i, err
+}
+
 const attachCartToCustomer = `-- name: AttachCartToCustomer :exec
 UPDATE cart
 SET customer_id = $1,
@@ -33,6 +104,79 @@ func (q *Queries) AttachCartToCustomer(ctx context.Context, arg AttachCartToCust
 	return err
 }
 
+const attachSessionCustomer = `-- name: AttachSessionCustomer :one
+UPDATE visitor_session
+SET customer_id = $1,
+    last_seen_at = NOW()
+WHERE session_id = $2
+  AND store_id = $3
+  AND (customer_id IS NULL OR customer_id = $1)
+RETURNING session_id
+`
+
+type AttachSessionCustomerParams struct {
+	CustomerID sql.NullInt64
+	SessionID  uuid.UUID
+	StoreID    int64
+}
+
+func (q *Queries) AttachSessionCustomer(ctx context.Context, arg AttachSessionCustomerParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, attachSessionCustomer, arg.CustomerID, arg.SessionID, arg.StoreID)
+	var session_id uuid.UUID
+	err := row.Scan(&session_id)
+	return session_id, err
+}
+
+const attributeNameTaken = `-- name: AttributeNameTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM attribute_definition
+  WHERE name = $1
+    AND (store_id IS NULL OR store_id = $2)
+)
+`
+
+type AttributeNameTakenParams struct {
+	Name    string
+	StoreID sql.NullInt64
+}
+
+// Private names may not repeat a global name or another private name of the store.
+func (q *Queries) AttributeNameTaken(ctx context.Context, arg AttributeNameTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, attributeNameTaken, arg.Name, arg.StoreID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const categoryAttributeInUse = `-- name: CategoryAttributeInUse :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product_variant v
+  JOIN product p
+    ON p.product_id = v.product_id
+  JOIN variant_attribute_value vav
+    ON vav.variant_id = v.variant_id
+  WHERE p.category_id = $1
+    AND vav.attribute_id = $2
+    AND p.deleted_at IS NULL
+    AND v.deleted_at IS NULL
+)
+`
+
+type CategoryAttributeInUseParams struct {
+	CategoryID  int64
+	AttributeID int64
+}
+
+// Whether a live variant of the category's products has a value for the attribute.
+func (q *Queries) CategoryAttributeInUse(ctx context.Context, arg CategoryAttributeInUseParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, categoryAttributeInUse, arg.CategoryID, arg.AttributeID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const categoryHasAttribute = `-- name: CategoryHasAttribute :one
 SELECT 1
 FROM category_attribute
@@ -52,6 +196,67 @@ func (q *Queries) CategoryHasAttribute(ctx context.Context, arg CategoryHasAttri
 	return column_1, err
 }
 
+const categoryHasChildren = `-- name: CategoryHasChildren :one
+SELECT EXISTS (
+  SELECT 1 FROM category_definition WHERE parent_id = $1
+)
+`
+
+func (q *Queries) CategoryHasChildren(ctx context.Context, parentID sql.NullInt64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, categoryHasChildren, parentID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const categoryHasProducts = `-- name: CategoryHasProducts :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product
+  WHERE store_id = $1
+    AND category_id = $2
+    AND (NOT $3::BOOLEAN OR deleted_at IS NULL)
+)
+`
+
+type CategoryHasProductsParams struct {
+	StoreID    int64
+	CategoryID int64
+	LiveOnly   bool
+}
+
+// live_only ignores soft-deleted products.
+func (q *Queries) CategoryHasProducts(ctx context.Context, arg CategoryHasProductsParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, categoryHasProducts, arg.StoreID, arg.CategoryID, arg.LiveOnly)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const categoryNameTaken = `-- name: CategoryNameTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM category_definition
+  WHERE name = $1
+    AND category_id <> $2
+    AND (store_id IS NULL OR store_id = $3)
+)
+`
+
+type CategoryNameTakenParams struct {
+	Name       string
+	CategoryID int64
+	StoreID    sql.NullInt64
+}
+
+// Private names may not repeat a global name or another private name of the store.
+func (q *Queries) CategoryNameTaken(ctx context.Context, arg CategoryNameTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, categoryNameTaken, arg.Name, arg.CategoryID, arg.StoreID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const clearCartItems = `-- name: ClearCartItems :exec
 DELETE FROM cart_item
 WHERE cart_id = $1
@@ -62,6 +267,151 @@ func (q *Queries) ClearCartItems(ctx context.Context, cartID int64) error {
 	return err
 }
 
+const countCategoryAttributeConflicts = `-- name: CountCategoryAttributeConflicts :one
+SELECT COUNT(*)
+FROM product_variant v
+JOIN product p
+  ON p.product_id = v.product_id
+LEFT JOIN variant_attribute_value vav
+  ON vav.variant_id = v.variant_id
+ AND vav.attribute_id = $1
+WHERE p.category_id = $2
+  AND p.deleted_at IS NULL
+  AND v.deleted_at IS NULL
+  AND (
+    ($3::BOOLEAN AND vav.value IS NULL)
+    OR (vav.value IS NOT NULL
+        AND $4::TEXT[] IS NOT NULL
+        AND NOT (vav.value = ANY($4::TEXT[])))
+  )
+`
+
+type CountCategoryAttributeConflictsParams struct {
+	AttributeID   int64
+	CategoryID    int64
+	IsRequired    bool
+	AllowedValues []string
+}
+
+// Live variants of the category's products that would break the rule: those
+// missing a required attribute, or holding a value outside allowed_values.
+func (q *Queries) CountCategoryAttributeConflicts(ctx context.Context, arg CountCategoryAttributeConflictsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCategoryAttributeConflicts,
+		arg.AttributeID,
+		arg.CategoryID,
+		arg.IsRequired,
+		pq.Array(arg.AllowedValues),
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCustomerOrders = `-- name: CountCustomerOrders :one
+SELECT COUNT(*)
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2
+`
+
+type CountCustomerOrdersParams struct {
+	StoreID    int64
+	CustomerID sql.NullInt64
+}
+
+func (q *Queries) CountCustomerOrders(ctx context.Context, arg CountCustomerOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCustomerOrders, arg.StoreID, arg.CustomerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStockAlerts = `-- name: CountStockAlerts :one
+SELECT COUNT(*)
+FROM stock_alert a
+WHERE a.store_id = $1
+  AND CASE $2::TEXT
+        WHEN 'snoozed' THEN a.status <> 'resolved' AND a.snoozed_until > NOW()
+        WHEN 'resolved' THEN a.status = 'resolved'
+        ELSE a.status <> 'resolved' AND (a.snoozed_until IS NULL OR a.snoozed_until <= NOW())
+      END
+`
+
+type CountStockAlertsParams struct {
+	StoreID int64
+	View    string
+}
+
+func (q *Queries) CountStockAlerts(ctx context.Context, arg CountStockAlertsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStockAlerts, arg.StoreID, arg.View)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStoreCategories = `-- name: CountStoreCategories :one
+SELECT COUNT(*)
+FROM store_category
+WHERE store_id = $1
+`
+
+func (q *Queries) CountStoreCategories(ctx context.Context, storeID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStoreCategories, storeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStoreOrders = `-- name: CountStoreOrders :one
+SELECT COUNT(*)
+FROM customer_order co
+WHERE co.store_id = $1
+  AND ($2::TEXT IS NULL OR co.status = $2)
+  AND ($3::BIGINT IS NULL OR co.customer_id = $3)
+  AND ($4::TIMESTAMPTZ IS NULL OR co.created_at >= $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR co.created_at < $5)
+  AND ($6::NUMERIC IS NULL OR co.total_amount >= $6)
+  AND ($7::NUMERIC IS NULL OR co.total_amount <= $7)
+`
+
+type CountStoreOrdersParams struct {
+	StoreID     int64
+	Status      sql.NullString
+	CustomerID  sql.NullInt64
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	MinAmount   sql.NullString
+	MaxAmount   sql.NullString
+}
+
+func (q *Queries) CountStoreOrders(ctx context.Context, arg CountStoreOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStoreOrders,
+		arg.StoreID,
+		arg.Status,
+		arg.CustomerID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnrefundedOrderItems = `-- name: CountUnrefundedOrderItems :one
+SELECT COUNT(*)
+FROM order_item
+WHERE order_id = $1
+  AND refunded_quantity < quantity
+`
+
+func (q *Queries) CountUnrefundedOrderItems(ctx context.Context, orderID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnrefundedOrderItems, orderID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCart = `-- name: CreateCart :one
 INSERT INTO cart (store_id, session_id, customer_id)
 VALUES ($1, $2, $3)
@@ -88,6 +438,31 @@ func (q *Queries) CreateCart(ctx context.Context, arg CreateCartParams) (Cart, e
 	return i, err
 }
 
+const createCartEvent = `-- name: CreateCartEvent :exec
+INSERT INTO cart_event (
+  session_id, product_id, variant_id, event_type
+) VALUES (
+  $1, $2, $3, $4
+)
+`
+
+type CreateCartEventParams struct {
+	SessionID uuid.UUID
+	ProductID int64
+	VariantID sql.NullInt64
+	EventType sql.NullString
+}
+
+func (q *Queries) CreateCartEvent(ctx context.Context, arg CreateCartEventParams) error {
+	_, err := q.db.ExecContext(ctx, createCartEvent,
+		arg.SessionID,
+		arg.ProductID,
+		arg.VariantID,
+		arg.EventType,
+	)
+	return err
+}
+
 const createCustomer = `-- name: CreateCustomer :one
 INSERT INTO customer (
   store_id,
@@ -139,23 +514,110 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 	return i, err
 }
 
+const createImportJob = `-- name: CreateImportJob :one
+INSERT INTO product_import_job (store_id, format, dry_run, total_rows)
+VALUES ($1, $2, $3, $4)
+RETURNING job_id, store_id, format, dry_run, status, total_rows, processed_rows, failed_rows, error, created_at, finished_at
+`
+
+type CreateImportJobParams struct {
+	StoreID   int64
+	Format    string
+	DryRun    bool
+	TotalRows int32
+}
+
+func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams) (ProductImportJob, error) {
+	row := q.db.QueryRowContext(ctx, createImportJob,
+		arg.StoreID,
+		arg.Format,
+		arg.DryRun,
+		arg.TotalRows,
+	)
+	var i ProductImportJob
+	err := row.Scan(
+		&i.JobID,
+		&i.StoreID,
+		&i.Format,
+		&i.DryRun,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.FailedRows,
+		&i.Error,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createInventoryMovement = `-- name: CreateInventoryMovement :one
+INSERT INTO inventory_movement (
+  store_id, variant_id, quantity_change, reason, actor_type, actor_id, order_id, note
+)
+SELECT v.store_id, v.variant_id, $2, $3, $4, $5, $6, $7
+FROM product_variant v
+WHERE v.variant_id = $1
+RETURNING movement_id, store_id, variant_id, quantity_change, reason, actor_type, actor_id, order_id, note, created_at
+`
+
+type CreateInventoryMovementParams struct {
+	VariantID      int64
+	QuantityChange int32
+	Reason         string
+	ActorType      string
+	ActorID        sql.NullInt64
+	OrderID        sql.NullInt64
+	Note           sql.NullString
+}
+
+func (q *Queries) CreateInventoryMovement(ctx context.Context, arg CreateInventoryMovementParams) (InventoryMovement, error) {
+	row := q.db.QueryRowContext(ctx, createInventoryMovement,
+		arg.VariantID,
+		arg.QuantityChange,
+		arg.Reason,
+		arg.ActorType,
+		arg.ActorID,
+		arg.OrderID,
+		arg.Note,
+	)
+	var i InventoryMovement
+	err := row.Scan(
+		&i.MovementID,
+		&i.StoreID,
+		&i.VariantID,
+		&i.QuantityChange,
+		&i.Reason,
+		&i.ActorType,
+		&i.ActorID,
+		&i.OrderID,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO customer_order (
   store_id,
   customer_id,
   session_id,
-  total_amount
+  total_amount,
+  email,
+  shipping_address
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at
+RETURNING order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at, email, shipping_address
 `
 
 type CreateOrderParams struct {
-	StoreID     int64
-	CustomerID  sql.NullInt64
-	SessionID   uuid.UUID
-	TotalAmount string
+	StoreID         int64
+	CustomerID      sql.NullInt64
+	SessionID       uuid.UUID
+	TotalAmount     string
+	Email           sql.NullString
+	ShippingAddress types.NullableAddress
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (CustomerOrder, error) {
@@ -164,6 +626,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Custo
 		arg.CustomerID,
 		arg.SessionID,
 		arg.TotalAmount,
+		arg.Email,
+		arg.ShippingAddress,
 	)
 	var i CustomerOrder
 	err := row.Scan(
@@ -175,6 +639,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Custo
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Email,
+		&i.ShippingAddress,
 	)
 	return i, err
 }
@@ -210,7 +676,7 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 	return err
 }
 
-const createPayment = `-- name: CreatePayment :exec
+const createPayment = `-- name: CreatePayment :one
 INSERT INTO payment (
   order_id,
   method,
@@ -220,6 +686,7 @@ INSERT INTO payment (
 ) VALUES (
   $1, $2, $3, $4, $5
 )
+RETURNING payment_id, order_id, method, amount, status, transaction_ref, created_at
 `
 
 type CreatePaymentParams struct {
@@ -230,22 +697,32 @@ type CreatePaymentParams struct {
 	TransactionRef sql.NullString
 }
 
-func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) error {
-	_, err := q.db.ExecContext(ctx, createPayment,
+func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
+	row := q.db.QueryRowContext(ctx, createPayment,
 		arg.OrderID,
 		arg.Method,
 		arg.Amount,
 		arg.Status,
 		arg.TransactionRef,
 	)
-	return err
+	var i Payment
+	err := row.Scan(
+		&i.PaymentID,
+		&i.OrderID,
+		&i.Method,
+		&i.Amount,
+		&i.Status,
+		&i.TransactionRef,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
 const createProduct = `-- name: CreateProduct :one
 INSERT INTO product (
-  store_id, category_id, name, slug, description, brand
+  store_id, category_id, name, slug, description, brand, in_stock
 )
-VALUES ($1, $2, $3, $4, $5, $6)
+VALUES ($1, $2, $3, $4, $5, $6, FALSE)
 RETURNING product_id, store_id, category_id, name, slug, description, brand, stock_quantity, created_at, updated_at, in_stock, deleted_at, default_variant_id
 `
 
@@ -310,6 +787,61 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 	return err
 }
 
+const createShipment = `-- name: CreateShipment :one
+INSERT INTO shipment (
+  order_id, carrier, tracking_number
+) VALUES (
+  $1, $2, $3
+)
+RETURNING shipment_id, order_id, tracking_number, carrier, shipped_at, delivered_at, status
+`
+
+type CreateShipmentParams struct {
+	OrderID        int64
+	Carrier        sql.NullString
+	TrackingNumber sql.NullString
+}
+
+func (q *Queries) CreateShipment(ctx context.Context, arg CreateShipmentParams) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, createShipment, arg.OrderID, arg.Carrier, arg.TrackingNumber)
+	var i Shipment
+	err := row.Scan(
+		&i.ShipmentID,
+		&i.OrderID,
+		&i.TrackingNumber,
+		&i.Carrier,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const createStockReservation = `-- name: CreateStockReservation :exec
+INSERT INTO stock_reservation (
+  order_id, variant_id, quantity, expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+`
+
+type CreateStockReservationParams struct {
+	OrderID   int64
+	VariantID int64
+	Quantity  int32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateStockReservation(ctx context.Context, arg CreateStockReservationParams) error {
+	_, err := q.db.ExecContext(ctx, createStockReservation,
+		arg.OrderID,
+		arg.VariantID,
+		arg.Quantity,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
 const createStore = `-- name: CreateStore :one
 INSERT INTO store (
     store_owner_id,
@@ -318,7 +850,7 @@ INSERT INTO store (
     currency,
     timezone
 ) VALUES ($1, $2, $3, $4, $5)
-RETURNING store_id, store_owner_id, name, domain, download_status, currency, timezone, created_at, updated_at
+RETURNING store_id, store_owner_id, name, domain, download_status, currency, timezone, low_stock_threshold, created_at, updated_at
 `
 
 type CreateStoreParams struct {
@@ -346,17 +878,66 @@ func (q *Queries) CreateStore(ctx context.Context, arg CreateStoreParams) (Store
 		&i.DownloadStatus,
 		&i.Currency,
 		&i.Timezone,
+		&i.LowStockThreshold,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const createStoreOwner = `-- name: CreateStoreOwner :one
-INSERT INTO store_owner (
-  name,
-  email,
-  password_hash,
+const createStoreAttribute = `-- name: CreateStoreAttribute :one
+INSERT INTO attribute_definition (name, data_type, store_id)
+VALUES ($1, $2, $3)
+RETURNING attribute_id, name, data_type, store_id
+`
+
+type CreateStoreAttributeParams struct {
+	Name     string
+	DataType string
+	StoreID  sql.NullInt64
+}
+
+func (q *Queries) CreateStoreAttribute(ctx context.Context, arg CreateStoreAttributeParams) (AttributeDefinition, error) {
+	row := q.db.QueryRowContext(ctx, createStoreAttribute, arg.Name, arg.DataType, arg.StoreID)
+	var i AttributeDefinition
+	err := row.Scan(
+		&i.AttributeID,
+		&i.Name,
+		&i.DataType,
+		&i.StoreID,
+	)
+	return i, err
+}
+
+const createStoreCategoryDefinition = `-- name: CreateStoreCategoryDefinition :one
+INSERT INTO category_definition (name, parent_id, store_id)
+VALUES ($1, $2, $3)
+RETURNING category_id, name, parent_id, store_id
+`
+
+type CreateStoreCategoryDefinitionParams struct {
+	Name     string
+	ParentID sql.NullInt64
+	StoreID  sql.NullInt64
+}
+
+func (q *Queries) CreateStoreCategoryDefinition(ctx context.Context, arg CreateStoreCategoryDefinitionParams) (CategoryDefinition, error) {
+	row := q.db.QueryRowContext(ctx, createStoreCategoryDefinition, arg.Name, arg.ParentID, arg.StoreID)
+	var i CategoryDefinition
+	err := row.Scan(
+		&i.CategoryID,
+		&i.Name,
+		&i.ParentID,
+		&i.StoreID,
+	)
+	return i, err
+}
+
+const createStoreOwner = `-- name: CreateStoreOwner :one
+INSERT INTO store_owner (
+  name,
+  email,
+  password_hash,
   phone,
   address
 ) VALUES (
@@ -404,7 +985,7 @@ INSERT INTO product_variant (
   sku, price, stock_quantity
 )
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at
+RETURNING variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
 `
 
 type CreateVariantParams struct {
@@ -438,25 +1019,43 @@ func (q *Queries) CreateVariant(ctx context.Context, arg CreateVariantParams) (P
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.LowStockThreshold,
 	)
 	return i, err
 }
 
-const decreaseVariantStock = `-- name: DecreaseVariantStock :exec
-UPDATE product_variant
-SET stock_quantity = stock_quantity - $2,
-    updated_at = NOW()
-WHERE variant_id = $1
+const createVisitorSession = `-- name: CreateVisitorSession :one
+INSERT INTO visitor_session (store_id, customer_id, ip_address, user_agent)
+VALUES ($1, $2, $3, $4)
+RETURNING session_id, store_id, customer_id, ip_address, user_agent, first_seen_at, last_seen_at, is_returning
 `
 
-type DecreaseVariantStockParams struct {
-	VariantID    int64
-	CartQuantity int32
+type CreateVisitorSessionParams struct {
+	StoreID    int64
+	CustomerID sql.NullInt64
+	IpAddress  pqtype.Inet
+	UserAgent  sql.NullString
 }
 
-func (q *Queries) DecreaseVariantStock(ctx context.Context, arg DecreaseVariantStockParams) error {
-	_, err := q.db.ExecContext(ctx, decreaseVariantStock, arg.VariantID, arg.CartQuantity)
-	return err
+func (q *Queries) CreateVisitorSession(ctx context.Context, arg CreateVisitorSessionParams) (VisitorSession, error) {
+	row := q.db.QueryRowContext(ctx, createVisitorSession,
+		arg.StoreID,
+		arg.CustomerID,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
+	var i VisitorSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.IsReturning,
+	)
+	return i, err
 }
 
 const deleteCart = `-- name: DeleteCart :exec
@@ -469,6 +1068,49 @@ func (q *Queries) DeleteCart(ctx context.Context, cartID int64) error {
 	return err
 }
 
+const deleteCartItem = `-- name: DeleteCartItem :exec
+DELETE FROM cart_item
+WHERE cart_item_id = $1
+`
+
+func (q *Queries) DeleteCartItem(ctx context.Context, cartItemID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteCartItem, cartItemID)
+	return err
+}
+
+const deleteCategoryAttribute = `-- name: DeleteCategoryAttribute :execrows
+DELETE FROM category_attribute
+WHERE category_id = $1
+  AND attribute_id = $2
+`
+
+type DeleteCategoryAttributeParams struct {
+	CategoryID  int64
+	AttributeID int64
+}
+
+func (q *Queries) DeleteCategoryAttribute(ctx context.Context, arg DeleteCategoryAttributeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCategoryAttribute, arg.CategoryID, arg.AttributeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProductCartItems = `-- name: DeleteProductCartItems :exec
+DELETE FROM cart_item
+WHERE variant_id IN (
+  SELECT variant_id
+  FROM product_variant
+  WHERE product_id = $1
+)
+`
+
+func (q *Queries) DeleteProductCartItems(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteProductCartItems, productID)
+	return err
+}
+
 const deleteStore = `-- name: DeleteStore :exec
 DELETE FROM store
 WHERE store_id = $1
@@ -479,6 +1121,113 @@ func (q *Queries) DeleteStore(ctx context.Context, storeID int64) error {
 	return err
 }
 
+const deleteStoreCategoryDefinition = `-- name: DeleteStoreCategoryDefinition :exec
+DELETE FROM category_definition
+WHERE category_id = $1
+  AND store_id = $2
+`
+
+type DeleteStoreCategoryDefinitionParams struct {
+	CategoryID int64
+	StoreID    sql.NullInt64
+}
+
+func (q *Queries) DeleteStoreCategoryDefinition(ctx context.Context, arg DeleteStoreCategoryDefinitionParams) error {
+	_, err := q.db.ExecContext(ctx, deleteStoreCategoryDefinition, arg.CategoryID, arg.StoreID)
+	return err
+}
+
+const deleteVariantAttributes = `-- name: DeleteVariantAttributes :exec
+DELETE FROM variant_attribute_value
+WHERE variant_id = $1
+`
+
+func (q *Queries) DeleteVariantAttributes(ctx context.Context, variantID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteVariantAttributes, variantID)
+	return err
+}
+
+const deleteVariantCartItems = `-- name: DeleteVariantCartItems :exec
+DELETE FROM cart_item
+WHERE variant_id = $1
+`
+
+func (q *Queries) DeleteVariantCartItems(ctx context.Context, variantID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteVariantCartItems, variantID)
+	return err
+}
+
+const disableStoreCategory = `-- name: DisableStoreCategory :exec
+DELETE FROM store_category
+WHERE store_id = $1
+  AND category_id = $2
+`
+
+type DisableStoreCategoryParams struct {
+	StoreID    int64
+	CategoryID int64
+}
+
+func (q *Queries) DisableStoreCategory(ctx context.Context, arg DisableStoreCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, disableStoreCategory, arg.StoreID, arg.CategoryID)
+	return err
+}
+
+const enableStoreCategory = `-- name: EnableStoreCategory :exec
+INSERT INTO store_category (store_id, category_id, position)
+SELECT $1, $2, COALESCE(MAX(position) + 1, 0)
+FROM store_category
+WHERE store_id = $1
+ON CONFLICT (store_id, category_id) DO NOTHING
+`
+
+type EnableStoreCategoryParams struct {
+	StoreID    int64
+	CategoryID int64
+}
+
+// Newly enabled categories go last.
+func (q *Queries) EnableStoreCategory(ctx context.Context, arg EnableStoreCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, enableStoreCategory, arg.StoreID, arg.CategoryID)
+	return err
+}
+
+const failInterruptedImportJobs = `-- name: FailInterruptedImportJobs :execrows
+UPDATE product_import_job
+SET status = 'failed',
+    error = 'interrupted by a server restart',
+    finished_at = NOW()
+WHERE status IN ('pending', 'running')
+`
+
+// Jobs run in the server process; any still open at startup were cut off.
+func (q *Queries) FailInterruptedImportJobs(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failInterruptedImportJobs)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishImportJob = `-- name: FinishImportJob :exec
+UPDATE product_import_job
+SET status = $2,
+    error = $3,
+    finished_at = NOW()
+WHERE job_id = $1
+`
+
+type FinishImportJobParams struct {
+	JobID  int64
+	Status string
+	Error  sql.NullString
+}
+
+func (q *Queries) FinishImportJob(ctx context.Context, arg FinishImportJobParams) error {
+	_, err := q.db.ExecContext(ctx, finishImportJob, arg.JobID, arg.Status, arg.Error)
+	return err
+}
+
 const getAdminByEmail = `-- name: GetAdminByEmail :one
 SELECT admin_id, email, password_hash
 FROM admin
@@ -498,6 +1247,31 @@ func (q *Queries) GetAdminByEmail(ctx context.Context, email string) (GetAdminBy
 	return i, err
 }
 
+const getAttributeByName = `-- name: GetAttributeByName :one
+SELECT attribute_id, name, data_type, store_id
+FROM attribute_definition
+WHERE name = $1
+  AND (store_id IS NULL OR store_id = $2)
+`
+
+type GetAttributeByNameParams struct {
+	Name    string
+	StoreID sql.NullInt64
+}
+
+// A global attribute or one of the store's private ones.
+func (q *Queries) GetAttributeByName(ctx context.Context, arg GetAttributeByNameParams) (AttributeDefinition, error) {
+	row := q.db.QueryRowContext(ctx, getAttributeByName, arg.Name, arg.StoreID)
+	var i AttributeDefinition
+	err := row.Scan(
+		&i.AttributeID,
+		&i.Name,
+		&i.DataType,
+		&i.StoreID,
+	)
+	return i, err
+}
+
 const getCartByCustomerForUpdate = `-- name: GetCartByCustomerForUpdate :one
 SELECT cart_id, store_id, session_id, customer_id, created_at, updated_at
 FROM cart
@@ -605,6 +1379,46 @@ func (q *Queries) GetCartForSession(ctx context.Context, arg GetCartForSessionPa
 	return i, err
 }
 
+const getCartItemForUpdate = `-- name: GetCartItemForUpdate :one
+SELECT
+  ci.cart_item_id,
+  ci.variant_id,
+  ci.quantity,
+  v.product_id,
+  v.stock_quantity AS available_stock
+FROM cart_item ci
+JOIN product_variant v ON v.variant_id = ci.variant_id
+WHERE ci.cart_item_id = $1
+  AND ci.cart_id = $2
+FOR UPDATE OF ci
+`
+
+type GetCartItemForUpdateParams struct {
+	CartItemID int64
+	CartID     int64
+}
+
+type GetCartItemForUpdateRow struct {
+	CartItemID     int64
+	VariantID      int64
+	Quantity       int32
+	ProductID      int64
+	AvailableStock int32
+}
+
+func (q *Queries) GetCartItemForUpdate(ctx context.Context, arg GetCartItemForUpdateParams) (GetCartItemForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, getCartItemForUpdate, arg.CartItemID, arg.CartID)
+	var i GetCartItemForUpdateRow
+	err := row.Scan(
+		&i.CartItemID,
+		&i.VariantID,
+		&i.Quantity,
+		&i.ProductID,
+		&i.AvailableStock,
+	)
+	return i, err
+}
+
 const getCartItems = `-- name: GetCartItems :many
 SELECT
 	ci.cart_item_id,
@@ -675,11 +1489,13 @@ SELECT
   ci.quantity AS cart_quantity,
   ci.unit_price,
   v.stock_quantity AS available_stock,
-  (v.unit_price * ci.quantity)::NUMERIC AS subtotal
+  (ci.unit_price * ci.quantity)::NUMERIC AS subtotal,
+  (v.deleted_at IS NULL AND p.deleted_at IS NULL) AS purchasable
 FROM cart_item ci
 JOIN product_variant v ON v.variant_id = ci.variant_id
+JOIN product p ON p.product_id = v.product_id
 WHERE ci.cart_id = $1
-FOR UPDATE
+FOR UPDATE OF ci, v
 `
 
 type GetCartItemsForUpdateRow struct {
@@ -689,6 +1505,7 @@ type GetCartItemsForUpdateRow struct {
 	UnitPrice      string
 	AvailableStock int32
 	Subtotal       string
+	Purchasable    bool
 }
 
 func (q *Queries) GetCartItemsForUpdate(ctx context.Context, cartID int64) ([]GetCartItemsForUpdateRow, error) {
@@ -707,6 +1524,7 @@ func (q *Queries) GetCartItemsForUpdate(ctx context.Context, cartID int64) ([]Ge
 			&i.UnitPrice,
 			&i.AvailableStock,
 			&i.Subtotal,
+			&i.Purchasable,
 		); err != nil {
 			return nil, err
 		}
@@ -776,6 +1594,148 @@ func (q *Queries) GetCustomerByEmail(ctx context.Context, arg GetCustomerByEmail
 	return i, err
 }
 
+const getCustomerOrder = `-- name: GetCustomerOrder :one
+SELECT order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at, email, shipping_address
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2 AND order_id = $3
+`
+
+type GetCustomerOrderParams struct {
+	StoreID    int64
+	CustomerID sql.NullInt64
+	OrderID    int64
+}
+
+func (q *Queries) GetCustomerOrder(ctx context.Context, arg GetCustomerOrderParams) (CustomerOrder, error) {
+	row := q.db.QueryRowContext(ctx, getCustomerOrder, arg.StoreID, arg.CustomerID, arg.OrderID)
+	var i CustomerOrder
+	err := row.Scan(
+		&i.OrderID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.SessionID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.ShippingAddress,
+	)
+	return i, err
+}
+
+const getImportJob = `-- name: GetImportJob :one
+SELECT job_id, store_id, format, dry_run, status, total_rows, processed_rows, failed_rows, error, created_at, finished_at
+FROM product_import_job
+WHERE job_id = $1
+  AND store_id = $2
+`
+
+type GetImportJobParams struct {
+	JobID   int64
+	StoreID int64
+}
+
+func (q *Queries) GetImportJob(ctx context.Context, arg GetImportJobParams) (ProductImportJob, error) {
+	row := q.db.QueryRowContext(ctx, getImportJob, arg.JobID, arg.StoreID)
+	var i ProductImportJob
+	err := row.Scan(
+		&i.JobID,
+		&i.StoreID,
+		&i.Format,
+		&i.DryRun,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.FailedRows,
+		&i.Error,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at, email, shipping_address
+FROM customer_order
+WHERE order_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderForUpdate(ctx context.Context, orderID int64) (CustomerOrder, error) {
+	row := q.db.QueryRowContext(ctx, getOrderForUpdate, orderID)
+	var i CustomerOrder
+	err := row.Scan(
+		&i.OrderID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.SessionID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.ShippingAddress,
+	)
+	return i, err
+}
+
+const getOrderShipmentProgress = `-- name: GetOrderShipmentProgress :one
+SELECT
+  (SELECT COALESCE(SUM(oi.quantity - oi.refunded_quantity), 0)
+   FROM order_item oi
+   WHERE oi.order_id = $1)::BIGINT AS ordered,
+  (SELECT COALESCE(SUM(si.quantity), 0)
+   FROM shipment_item si
+   JOIN shipment s ON s.shipment_id = si.shipment_id
+   WHERE s.order_id = $1 AND s.status IN ('shipped', 'delivered'))::BIGINT AS shipped,
+  (SELECT COALESCE(SUM(si.quantity), 0)
+   FROM shipment_item si
+   JOIN shipment s ON s.shipment_id = si.shipment_id
+   WHERE s.order_id = $1 AND s.status = 'delivered')::BIGINT AS delivered
+`
+
+type GetOrderShipmentProgressRow struct {
+	Ordered   int64
+	Shipped   int64
+	Delivered int64
+}
+
+func (q *Queries) GetOrderShipmentProgress(ctx context.Context, orderID int64) (GetOrderShipmentProgressRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderShipmentProgress, orderID)
+	var i GetOrderShipmentProgressRow
+	err := row.Scan(&i.Ordered, &i.Shipped, &i.Delivered)
+	return i, err
+}
+
+const getPaymentByTransactionRef = `-- name: GetPaymentByTransactionRef :one
+SELECT payment_id, order_id, method, amount, status, transaction_ref, created_at
+FROM payment
+WHERE method = $1 AND transaction_ref = $2
+ORDER BY payment_id
+LIMIT 1
+`
+
+type GetPaymentByTransactionRefParams struct {
+	Method         string
+	TransactionRef sql.NullString
+}
+
+func (q *Queries) GetPaymentByTransactionRef(ctx context.Context, arg GetPaymentByTransactionRefParams) (Payment, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentByTransactionRef, arg.Method, arg.TransactionRef)
+	var i Payment
+	err := row.Scan(
+		&i.PaymentID,
+		&i.OrderID,
+		&i.Method,
+		&i.Amount,
+		&i.Status,
+		&i.TransactionRef,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getProductBase = `-- name: GetProductBase :one
 SELECT
   p.product_id,
@@ -847,7 +1807,7 @@ FROM product
 WHERE store_id = $1
   AND name = $2
   AND category_id = $3
-  AND brand = $4
+  AND brand IS NOT DISTINCT FROM $4
   AND deleted_at IS NULL
 LIMIT 1
 `
@@ -1080,31 +2040,148 @@ func (q *Queries) GetSession(ctx context.Context, arg GetSessionParams) (GetSess
 	return i, err
 }
 
-const getStore = `-- name: GetStore :one
-SELECT store_id, store_owner_id, name, domain, download_status, currency, timezone, created_at, updated_at
-FROM store
-WHERE store_id = $1
+const getShipmentForUpdate = `-- name: GetShipmentForUpdate :one
+SELECT shipment_id, order_id, tracking_number, carrier, shipped_at, delivered_at, status
+FROM shipment
+WHERE shipment_id = $1 AND order_id = $2
+FOR UPDATE
 `
 
-func (q *Queries) GetStore(ctx context.Context, storeID int64) (Store, error) {
-	row := q.db.QueryRowContext(ctx, getStore, storeID)
-	var i Store
+type GetShipmentForUpdateParams struct {
+	ShipmentID int64
+	OrderID    int64
+}
+
+func (q *Queries) GetShipmentForUpdate(ctx context.Context, arg GetShipmentForUpdateParams) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, getShipmentForUpdate, arg.ShipmentID, arg.OrderID)
+	var i Shipment
 	err := row.Scan(
-		&i.StoreID,
-		&i.StoreOwnerID,
-		&i.Name,
-		&i.Domain,
+		&i.ShipmentID,
+		&i.OrderID,
+		&i.TrackingNumber,
+		&i.Carrier,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getStockAlert = `-- name: GetStockAlert :one
+SELECT
+  a.alert_id,
+  a.variant_id,
+  v.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.stock_quantity AS current_stock,
+  a.stock_quantity,
+  a.threshold,
+  a.status,
+  a.snoozed_until,
+  a.created_at,
+  a.acknowledged_at,
+  a.resolved_at
+FROM stock_alert a
+JOIN product_variant v ON v.variant_id = a.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE a.alert_id = $1
+  AND a.store_id = $2
+`
+
+type GetStockAlertParams struct {
+	AlertID int64
+	StoreID int64
+}
+
+type GetStockAlertRow struct {
+	AlertID        int64
+	VariantID      int64
+	ProductID      int64
+	ProductName    string
+	Sku            string
+	CurrentStock   int32
+	StockQuantity  int32
+	Threshold      int32
+	Status         string
+	SnoozedUntil   sql.NullTime
+	CreatedAt      time.Time
+	AcknowledgedAt sql.NullTime
+	ResolvedAt     sql.NullTime
+}
+
+func (q *Queries) GetStockAlert(ctx context.Context, arg GetStockAlertParams) (GetStockAlertRow, error) {
+	row := q.db.QueryRowContext(ctx, getStockAlert, arg.AlertID, arg.StoreID)
+	var i GetStockAlertRow
+	err := row.Scan(
+		&i.AlertID,
+		&i.VariantID,
+		&i.ProductID,
+		&i.ProductName,
+		&i.Sku,
+		&i.CurrentStock,
+		&i.StockQuantity,
+		&i.Threshold,
+		&i.Status,
+		&i.SnoozedUntil,
+		&i.CreatedAt,
+		&i.AcknowledgedAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const getStore = `-- name: GetStore :one
+SELECT store_id, store_owner_id, name, domain, download_status, currency, timezone, low_stock_threshold, created_at, updated_at
+FROM store
+WHERE store_id = $1
+`
+
+func (q *Queries) GetStore(ctx context.Context, storeID int64) (Store, error) {
+	row := q.db.QueryRowContext(ctx, getStore, storeID)
+	var i Store
+	err := row.Scan(
+		&i.StoreID,
+		&i.StoreOwnerID,
+		&i.Name,
+		&i.Domain,
 		&i.DownloadStatus,
 		&i.Currency,
 		&i.Timezone,
+		&i.LowStockThreshold,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getStoreAttribute = `-- name: GetStoreAttribute :one
+SELECT attribute_id, name, data_type, store_id
+FROM attribute_definition
+WHERE attribute_id = $1
+  AND (store_id IS NULL OR store_id = $2)
+`
+
+type GetStoreAttributeParams struct {
+	AttributeID int64
+	StoreID     sql.NullInt64
+}
+
+// A global attribute or one of the store's private ones.
+func (q *Queries) GetStoreAttribute(ctx context.Context, arg GetStoreAttributeParams) (AttributeDefinition, error) {
+	row := q.db.QueryRowContext(ctx, getStoreAttribute, arg.AttributeID, arg.StoreID)
+	var i AttributeDefinition
+	err := row.Scan(
+		&i.AttributeID,
+		&i.Name,
+		&i.DataType,
+		&i.StoreID,
+	)
+	return i, err
+}
+
 const getStoreByOwnerID = `-- name: GetStoreByOwnerID :one
-SELECT store_id, store_owner_id, name, domain, download_status, currency, timezone, created_at, updated_at
+SELECT store_id, store_owner_id, name, domain, download_status, currency, timezone, low_stock_threshold, created_at, updated_at
 FROM store
 WHERE store_owner_id = $1
 `
@@ -1120,8 +2197,93 @@ func (q *Queries) GetStoreByOwnerID(ctx context.Context, storeOwnerID int64) (St
 		&i.DownloadStatus,
 		&i.Currency,
 		&i.Timezone,
+		&i.LowStockThreshold,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getStoreCategoryDefinition = `-- name: GetStoreCategoryDefinition :one
+SELECT category_id, name, parent_id, store_id
+FROM category_definition
+WHERE category_id = $1
+  AND (store_id IS NULL OR store_id = $2)
+`
+
+type GetStoreCategoryDefinitionParams struct {
+	CategoryID int64
+	StoreID    sql.NullInt64
+}
+
+// A global category or one of the store's private ones.
+func (q *Queries) GetStoreCategoryDefinition(ctx context.Context, arg GetStoreCategoryDefinitionParams) (CategoryDefinition, error) {
+	row := q.db.QueryRowContext(ctx, getStoreCategoryDefinition, arg.CategoryID, arg.StoreID)
+	var i CategoryDefinition
+	err := row.Scan(
+		&i.CategoryID,
+		&i.Name,
+		&i.ParentID,
+		&i.StoreID,
+	)
+	return i, err
+}
+
+const getStoreOrder = `-- name: GetStoreOrder :one
+SELECT order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at, email, shipping_address
+FROM customer_order
+WHERE store_id = $1 AND order_id = $2
+`
+
+type GetStoreOrderParams struct {
+	StoreID int64
+	OrderID int64
+}
+
+func (q *Queries) GetStoreOrder(ctx context.Context, arg GetStoreOrderParams) (CustomerOrder, error) {
+	row := q.db.QueryRowContext(ctx, getStoreOrder, arg.StoreID, arg.OrderID)
+	var i CustomerOrder
+	err := row.Scan(
+		&i.OrderID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.SessionID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.ShippingAddress,
+	)
+	return i, err
+}
+
+const getStoreOrderForUpdate = `-- name: GetStoreOrderForUpdate :one
+SELECT order_id, store_id, customer_id, session_id, total_amount, status, created_at, updated_at, email, shipping_address
+FROM customer_order
+WHERE store_id = $1 AND order_id = $2
+FOR UPDATE
+`
+
+type GetStoreOrderForUpdateParams struct {
+	StoreID int64
+	OrderID int64
+}
+
+func (q *Queries) GetStoreOrderForUpdate(ctx context.Context, arg GetStoreOrderForUpdateParams) (CustomerOrder, error) {
+	row := q.db.QueryRowContext(ctx, getStoreOrderForUpdate, arg.StoreID, arg.OrderID)
+	var i CustomerOrder
+	err := row.Scan(
+		&i.OrderID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.SessionID,
+		&i.TotalAmount,
+		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Email,
+		&i.ShippingAddress,
 	)
 	return i, err
 }
@@ -1158,6 +2320,39 @@ func (q *Queries) GetStoreOwnerByEmail(ctx context.Context, email string) (GetSt
 	return i, err
 }
 
+const getStoreProductForUpdate = `-- name: GetStoreProductForUpdate :one
+SELECT product_id, store_id, category_id, name, slug, description, brand, stock_quantity, created_at, updated_at, in_stock, deleted_at, default_variant_id
+FROM product
+WHERE product_id = $1 AND store_id = $2
+FOR UPDATE
+`
+
+type GetStoreProductForUpdateParams struct {
+	ProductID int64
+	StoreID   int64
+}
+
+func (q *Queries) GetStoreProductForUpdate(ctx context.Context, arg GetStoreProductForUpdateParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, getStoreProductForUpdate, arg.ProductID, arg.StoreID)
+	var i Product
+	err := row.Scan(
+		&i.ProductID,
+		&i.StoreID,
+		&i.CategoryID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.Brand,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InStock,
+		&i.DeletedAt,
+		&i.DefaultVariantID,
+	)
+	return i, err
+}
+
 const getTopProductsByCategory = `-- name: GetTopProductsByCategory :many
 SELECT 
   p.product_id,
@@ -1246,7 +2441,7 @@ func (q *Queries) GetTopProductsByCategory(ctx context.Context, arg GetTopProduc
 }
 
 const getVariant = `-- name: GetVariant :one
-SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at
+SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
 FROM product_variant
 WHERE variant_id = $1
   AND deleted_at IS NULL
@@ -1267,17 +2462,19 @@ func (q *Queries) GetVariant(ctx context.Context, variantID int64) (ProductVaria
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.LowStockThreshold,
 	)
 	return i, err
 }
 
 const getVariantByAttributeHash = `-- name: GetVariantByAttributeHash :one
-SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at
+SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
 FROM product_variant
 WHERE product_id = $1
   AND attribute_hash = $2
   AND deleted_at IS NULL
 LIMIT 1
+FOR UPDATE
 `
 
 type GetVariantByAttributeHashParams struct {
@@ -1300,6 +2497,7 @@ func (q *Queries) GetVariantByAttributeHash(ctx context.Context, arg GetVariantB
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.LowStockThreshold,
 	)
 	return i, err
 }
@@ -1307,12 +2505,19 @@ func (q *Queries) GetVariantByAttributeHash(ctx context.Context, arg GetVariantB
 const getVariantForCart = `-- name: GetVariantForCart :one
 SELECT
   variant_id,
+  product_id,
   price,
   stock_quantity
 FROM product_variant
 WHERE variant_id = $1
   AND store_id = $2
   AND deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM product p
+    WHERE p.product_id = product_variant.product_id
+      AND p.deleted_at IS NULL
+  )
 FOR UPDATE
 `
 
@@ -1323,6 +2528,7 @@ type GetVariantForCartParams struct {
 
 type GetVariantForCartRow struct {
 	VariantID     int64
+	ProductID     int64
 	Price         string
 	StockQuantity int32
 }
@@ -1330,12 +2536,17 @@ type GetVariantForCartRow struct {
 func (q *Queries) GetVariantForCart(ctx context.Context, arg GetVariantForCartParams) (GetVariantForCartRow, error) {
 	row := q.db.QueryRowContext(ctx, getVariantForCart, arg.VariantID, arg.StoreID)
 	var i GetVariantForCartRow
-	err := row.Scan(&i.VariantID, &i.Price, &i.StockQuantity)
+	err := row.Scan(
+		&i.VariantID,
+		&i.ProductID,
+		&i.Price,
+		&i.StockQuantity,
+	)
 	return i, err
 }
 
 const getVariantForUpdate = `-- name: GetVariantForUpdate :one
-SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at
+SELECT variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
 FROM product_variant
 WHERE variant_id = $1
   AND deleted_at IS NULL
@@ -1357,24 +2568,88 @@ func (q *Queries) GetVariantForUpdate(ctx context.Context, variantID int64) (Pro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.LowStockThreshold,
 	)
 	return i, err
 }
 
-const increaseVariantStock = `-- name: IncreaseVariantStock :exec
-UPDATE product_variant
-SET stock_quantity = stock_quantity + $2,
-    updated_at = NOW()
-WHERE variant_id = $1
+const getVisitorSession = `-- name: GetVisitorSession :one
+SELECT session_id, store_id, customer_id, ip_address, user_agent, first_seen_at, last_seen_at, is_returning
+FROM visitor_session
+WHERE session_id = $1 AND store_id = $2
 `
 
-type IncreaseVariantStockParams struct {
-	VariantID     int64
-	StockQuantity int32
+type GetVisitorSessionParams struct {
+	SessionID uuid.UUID
+	StoreID   int64
 }
 
-func (q *Queries) IncreaseVariantStock(ctx context.Context, arg IncreaseVariantStockParams) error {
-	_, err := q.db.ExecContext(ctx, increaseVariantStock, arg.VariantID, arg.StockQuantity)
+func (q *Queries) GetVisitorSession(ctx context.Context, arg GetVisitorSessionParams) (VisitorSession, error) {
+	row := q.db.QueryRowContext(ctx, getVisitorSession, arg.SessionID, arg.StoreID)
+	var i VisitorSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.IsReturning,
+	)
+	return i, err
+}
+
+const insertImportError = `-- name: InsertImportError :exec
+INSERT INTO product_import_error (job_id, row_number, field, message)
+VALUES ($1, $2, $3, $4)
+`
+
+type InsertImportErrorParams struct {
+	JobID     int64
+	RowNumber int32
+	Field     sql.NullString
+	Message   string
+}
+
+func (q *Queries) InsertImportError(ctx context.Context, arg InsertImportErrorParams) error {
+	_, err := q.db.ExecContext(ctx, insertImportError,
+		arg.JobID,
+		arg.RowNumber,
+		arg.Field,
+		arg.Message,
+	)
+	return err
+}
+
+const insertProductViews = `-- name: InsertProductViews :exec
+INSERT INTO product_view (product_id, store_id, session_id, viewed_at)
+SELECT v.product_id, v.store_id, v.session_id, v.viewed_at
+FROM unnest(
+  $1::BIGINT[],
+  $2::BIGINT[],
+  $3::UUID[],
+  $4::TIMESTAMPTZ[]
+) AS v(product_id, store_id, session_id, viewed_at)
+JOIN product p ON p.product_id = v.product_id AND p.store_id = v.store_id
+JOIN visitor_session vs ON vs.session_id = v.session_id AND vs.store_id = v.store_id
+`
+
+type InsertProductViewsParams struct {
+	ProductIds []int64
+	StoreIds   []int64
+	SessionIds []uuid.UUID
+	ViewedAts  []time.Time
+}
+
+// Bulk insert; views for products or sessions outside the store are dropped.
+func (q *Queries) InsertProductViews(ctx context.Context, arg InsertProductViewsParams) error {
+	_, err := q.db.ExecContext(ctx, insertProductViews,
+		pq.Array(arg.ProductIds),
+		pq.Array(arg.StoreIds),
+		pq.Array(arg.SessionIds),
+		pq.Array(arg.ViewedAts),
+	)
 	return err
 }
 
@@ -1419,6 +2694,54 @@ func (q *Queries) InsertVariantImage(ctx context.Context, arg InsertVariantImage
 	return i, err
 }
 
+const isCategoryAncestor = `-- name: IsCategoryAncestor :one
+WITH RECURSIVE up AS (
+  SELECT category_id, parent_id
+  FROM category_definition
+  WHERE category_id = $1
+  UNION ALL
+  SELECT c.category_id, c.parent_id
+  FROM category_definition c
+  JOIN up ON c.category_id = up.parent_id
+)
+SELECT EXISTS (
+  SELECT 1 FROM up WHERE category_id = $2
+)
+`
+
+type IsCategoryAncestorParams struct {
+	CategoryID int64
+	AncestorID int64
+}
+
+// Whether ancestor_id is category_id itself or one of its ancestors.
+func (q *Queries) IsCategoryAncestor(ctx context.Context, arg IsCategoryAncestorParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isCategoryAncestor, arg.CategoryID, arg.AncestorID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isStoreCategory = `-- name: IsStoreCategory :one
+SELECT EXISTS (
+  SELECT 1
+  FROM store_category
+  WHERE store_id = $1 AND category_id = $2
+)
+`
+
+type IsStoreCategoryParams struct {
+	StoreID    int64
+	CategoryID int64
+}
+
+func (q *Queries) IsStoreCategory(ctx context.Context, arg IsStoreCategoryParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isStoreCategory, arg.StoreID, arg.CategoryID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const isStoreOwner = `-- name: IsStoreOwner :one
 SELECT EXISTS (
     SELECT 1
@@ -1440,6 +2763,44 @@ func (q *Queries) IsStoreOwner(ctx context.Context, arg IsStoreOwnerParams) (boo
 	return exists, err
 }
 
+const listActiveReservations = `-- name: ListActiveReservations :many
+SELECT reservation_id, order_id, variant_id, quantity, status, expires_at, created_at
+FROM stock_reservation
+WHERE order_id = $1 AND status = 'active'
+FOR UPDATE
+`
+
+func (q *Queries) ListActiveReservations(ctx context.Context, orderID int64) ([]StockReservation, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveReservations, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StockReservation
+	for rows.Next() {
+		var i StockReservation
+		if err := rows.Scan(
+			&i.ReservationID,
+			&i.OrderID,
+			&i.VariantID,
+			&i.Quantity,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCategoriesByStore = `-- name: ListCategoriesByStore :many
 SELECT c.category_id, c.name, pc.name as parent_name
 FROM store_category s
@@ -1448,7 +2809,7 @@ JOIN category_definition c
 JOIN category_definition pc
   ON c.parent_id = pc.category_id
 WHERE s.store_id = $1
-ORDER BY c.name
+ORDER BY s.position, c.name
 `
 
 type ListCategoriesByStoreRow struct {
@@ -1481,7 +2842,7 @@ func (q *Queries) ListCategoriesByStore(ctx context.Context, storeID int64) ([]L
 }
 
 const listCategoryAttributes = `-- name: ListCategoryAttributes :many
-SELECT a.attribute_id, a.name, ca.is_required
+SELECT a.attribute_id, a.name, a.data_type, ca.is_required, ca.allowed_values
 FROM category_attribute ca
 JOIN attribute_definition a 
 ON a.attribute_id = ca.attribute_id
@@ -1489,9 +2850,11 @@ WHERE ca.category_id = $1
 `
 
 type ListCategoryAttributesRow struct {
-	AttributeID int64
-	Name        string
-	IsRequired  bool
+	AttributeID   int64
+	Name          string
+	DataType      string
+	IsRequired    bool
+	AllowedValues []string
 }
 
 func (q *Queries) ListCategoryAttributes(ctx context.Context, categoryID int64) ([]ListCategoryAttributesRow, error) {
@@ -1503,7 +2866,13 @@ func (q *Queries) ListCategoryAttributes(ctx context.Context, categoryID int64)
 	var items []ListCategoryAttributesRow
 	for rows.Next() {
 		var i ListCategoryAttributesRow
-		if err := rows.Scan(&i.AttributeID, &i.Name, &i.IsRequired); err != nil {
+		if err := rows.Scan(
+			&i.AttributeID,
+			&i.Name,
+			&i.DataType,
+			&i.IsRequired,
+			pq.Array(&i.AllowedValues),
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1517,24 +2886,893 @@ func (q *Queries) ListCategoryAttributes(ctx context.Context, categoryID int64)
 	return items, nil
 }
 
-const mergeCartItems = `-- name: MergeCartItems :exec
-WITH updated AS (
-  UPDATE cart_item dst
-  SET quantity = dst.quantity + src.quantity,
-      updated_at = NOW()
-  FROM cart_item src
-  WHERE src.cart_id = $2
-    AND dst.cart_id = $1
-    AND src.variant_id = dst.variant_id
-  RETURNING src.cart_item_id
-)
-INSERT INTO cart_item (
-  cart_id,
-  variant_id,
-  quantity,
-  unit_price,
-  created_at,
-  updated_at
+const listCustomerOrders = `-- name: ListCustomerOrders :many
+SELECT
+  order_id,
+  total_amount,
+  status,
+  created_at,
+  updated_at
+FROM customer_order
+WHERE store_id = $1 AND customer_id = $2
+ORDER BY created_at DESC, order_id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListCustomerOrdersParams struct {
+	StoreID    int64
+	CustomerID sql.NullInt64
+	Limit      int32
+	Offset     int32
+}
+
+type ListCustomerOrdersRow struct {
+	OrderID     int64
+	TotalAmount string
+	Status      sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+}
+
+func (q *Queries) ListCustomerOrders(ctx context.Context, arg ListCustomerOrdersParams) ([]ListCustomerOrdersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomerOrders,
+		arg.StoreID,
+		arg.CustomerID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCustomerOrdersRow
+	for rows.Next() {
+		var i ListCustomerOrdersRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.TotalAmount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredReservationOrders = `-- name: ListExpiredReservationOrders :many
+SELECT DISTINCT r.order_id
+FROM stock_reservation r
+JOIN customer_order o ON o.order_id = r.order_id
+WHERE r.status = 'active'
+  AND r.expires_at <= $1
+  AND o.status = 'pending'
+LIMIT $2
+`
+
+type ListExpiredReservationOrdersParams struct {
+	ExpiresAt time.Time
+	Limit     int32
+}
+
+func (q *Queries) ListExpiredReservationOrders(ctx context.Context, arg ListExpiredReservationOrdersParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredReservationOrders, arg.ExpiresAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var order_id int64
+		if err := rows.Scan(&order_id); err != nil {
+			return nil, err
+		}
+		items = append(items, order_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportErrors = `-- name: ListImportErrors :many
+SELECT error_id, job_id, row_number, field, message
+FROM product_import_error
+WHERE job_id = $1
+ORDER BY row_number, error_id
+`
+
+func (q *Queries) ListImportErrors(ctx context.Context, jobID int64) ([]ProductImportError, error) {
+	rows, err := q.db.QueryContext(ctx, listImportErrors, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductImportError
+	for rows.Next() {
+		var i ProductImportError
+		if err := rows.Scan(
+			&i.ErrorID,
+			&i.JobID,
+			&i.RowNumber,
+			&i.Field,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderItems = `-- name: ListOrderItems :many
+SELECT
+  oi.order_item_id,
+  oi.variant_id,
+  p.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.primary_image_url,
+  oi.quantity,
+  oi.unit_price,
+  oi.subtotal,
+  oi.refunded_quantity
+FROM order_item oi
+JOIN product_variant v ON v.variant_id = oi.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE oi.order_id = $1
+ORDER BY oi.order_item_id
+`
+
+type ListOrderItemsRow struct {
+	OrderItemID      int64
+	VariantID        int64
+	ProductID        int64
+	ProductName      string
+	Sku              string
+	PrimaryImageUrl  sql.NullString
+	Quantity         int32
+	UnitPrice        string
+	Subtotal         string
+	RefundedQuantity int32
+}
+
+func (q *Queries) ListOrderItems(ctx context.Context, orderID int64) ([]ListOrderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderItems, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrderItemsRow
+	for rows.Next() {
+		var i ListOrderItemsRow
+		if err := rows.Scan(
+			&i.OrderItemID,
+			&i.VariantID,
+			&i.ProductID,
+			&i.ProductName,
+			&i.Sku,
+			&i.PrimaryImageUrl,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Subtotal,
+			&i.RefundedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderPayments = `-- name: ListOrderPayments :many
+SELECT payment_id, order_id, method, amount, status, transaction_ref, created_at
+FROM payment
+WHERE order_id = $1
+ORDER BY created_at, payment_id
+`
+
+func (q *Queries) ListOrderPayments(ctx context.Context, orderID int64) ([]Payment, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderPayments, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.PaymentID,
+			&i.OrderID,
+			&i.Method,
+			&i.Amount,
+			&i.Status,
+			&i.TransactionRef,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderShipmentItems = `-- name: ListOrderShipmentItems :many
+SELECT si.shipment_id, si.order_item_id, si.quantity
+FROM shipment_item si
+JOIN shipment s ON s.shipment_id = si.shipment_id
+WHERE s.order_id = $1
+ORDER BY si.shipment_id, si.order_item_id
+`
+
+func (q *Queries) ListOrderShipmentItems(ctx context.Context, orderID int64) ([]ShipmentItem, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderShipmentItems, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentItem
+	for rows.Next() {
+		var i ShipmentItem
+		if err := rows.Scan(&i.ShipmentID, &i.OrderItemID, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderShipments = `-- name: ListOrderShipments :many
+SELECT shipment_id, order_id, tracking_number, carrier, shipped_at, delivered_at, status
+FROM shipment
+WHERE order_id = $1
+ORDER BY shipment_id
+`
+
+func (q *Queries) ListOrderShipments(ctx context.Context, orderID int64) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderShipments, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.ShipmentID,
+			&i.OrderID,
+			&i.TrackingNumber,
+			&i.Carrier,
+			&i.ShippedAt,
+			&i.DeliveredAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductStockMismatches = `-- name: ListProductStockMismatches :many
+SELECT
+  p.product_id,
+  p.store_id,
+  p.stock_quantity,
+  COALESCE(SUM(v.stock_quantity), 0)::INT AS variant_stock
+FROM product p
+LEFT JOIN product_variant v
+  ON v.product_id = p.product_id
+ AND v.deleted_at IS NULL
+GROUP BY p.product_id
+HAVING p.stock_quantity <> COALESCE(SUM(v.stock_quantity), 0)
+ORDER BY p.product_id
+`
+
+type ListProductStockMismatchesRow struct {
+	ProductID     int64
+	StoreID       int64
+	StockQuantity int32
+	VariantStock  int32
+}
+
+func (q *Queries) ListProductStockMismatches(ctx context.Context) ([]ListProductStockMismatchesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProductStockMismatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProductStockMismatchesRow
+	for rows.Next() {
+		var i ListProductStockMismatchesRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.StoreID,
+			&i.StockQuantity,
+			&i.VariantStock,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductVariantAttributes = `-- name: ListProductVariantAttributes :many
+SELECT
+  v.variant_id,
+  vav.attribute_id,
+  vav.value
+FROM product_variant v
+JOIN variant_attribute_value vav ON vav.variant_id = v.variant_id
+WHERE v.product_id = $1
+  AND v.deleted_at IS NULL
+ORDER BY v.variant_id, vav.attribute_id
+`
+
+type ListProductVariantAttributesRow struct {
+	VariantID   int64
+	AttributeID int64
+	Value       string
+}
+
+func (q *Queries) ListProductVariantAttributes(ctx context.Context, productID int64) ([]ListProductVariantAttributesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProductVariantAttributes, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProductVariantAttributesRow
+	for rows.Next() {
+		var i ListProductVariantAttributesRow
+		if err := rows.Scan(&i.VariantID, &i.AttributeID, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockAlerts = `-- name: ListStockAlerts :many
+SELECT
+  a.alert_id,
+  a.variant_id,
+  v.product_id,
+  p.name AS product_name,
+  v.sku,
+  v.stock_quantity AS current_stock,
+  a.stock_quantity,
+  a.threshold,
+  a.status,
+  a.snoozed_until,
+  a.created_at,
+  a.acknowledged_at,
+  a.resolved_at
+FROM stock_alert a
+JOIN product_variant v ON v.variant_id = a.variant_id
+JOIN product p ON p.product_id = v.product_id
+WHERE a.store_id = $1
+  AND CASE $2::TEXT
+        WHEN 'snoozed' THEN a.status <> 'resolved' AND a.snoozed_until > NOW()
+        WHEN 'resolved' THEN a.status = 'resolved'
+        ELSE a.status <> 'resolved' AND (a.snoozed_until IS NULL OR a.snoozed_until <= NOW())
+      END
+ORDER BY a.created_at DESC, a.alert_id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListStockAlertsParams struct {
+	StoreID    int64
+	View       string
+	PageLimit  int32
+	PageOffset int32
+}
+
+type ListStockAlertsRow struct {
+	AlertID        int64
+	VariantID      int64
+	ProductID      int64
+	ProductName    string
+	Sku            string
+	CurrentStock   int32
+	StockQuantity  int32
+	Threshold      int32
+	Status         string
+	SnoozedUntil   sql.NullTime
+	CreatedAt      time.Time
+	AcknowledgedAt sql.NullTime
+	ResolvedAt     sql.NullTime
+}
+
+// view is 'active' (unresolved and not snoozed), 'snoozed' or 'resolved'.
+func (q *Queries) ListStockAlerts(ctx context.Context, arg ListStockAlertsParams) ([]ListStockAlertsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStockAlerts,
+		arg.StoreID,
+		arg.View,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStockAlertsRow
+	for rows.Next() {
+		var i ListStockAlertsRow
+		if err := rows.Scan(
+			&i.AlertID,
+			&i.VariantID,
+			&i.ProductID,
+			&i.ProductName,
+			&i.Sku,
+			&i.CurrentStock,
+			&i.StockQuantity,
+			&i.Threshold,
+			&i.Status,
+			&i.SnoozedUntil,
+			&i.CreatedAt,
+			&i.AcknowledgedAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStoreAttributes = `-- name: ListStoreAttributes :many
+SELECT
+  attribute_id,
+  name,
+  data_type,
+  (store_id IS NOT NULL)::BOOLEAN AS is_private
+FROM attribute_definition
+WHERE store_id IS NULL
+   OR store_id = $1
+ORDER BY name
+`
+
+type ListStoreAttributesRow struct {
+	AttributeID int64
+	Name        string
+	DataType    string
+	IsPrivate   bool
+}
+
+// Every attribute a store can use: all global ones and its private ones.
+func (q *Queries) ListStoreAttributes(ctx context.Context, storeID sql.NullInt64) ([]ListStoreAttributesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStoreAttributes, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStoreAttributesRow
+	for rows.Next() {
+		var i ListStoreAttributesRow
+		if err := rows.Scan(
+			&i.AttributeID,
+			&i.Name,
+			&i.DataType,
+			&i.IsPrivate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStoreCategoryOptions = `-- name: ListStoreCategoryOptions :many
+SELECT
+  c.category_id,
+  c.name,
+  c.parent_id,
+  (c.store_id IS NOT NULL)::BOOLEAN AS is_private,
+  (sc.category_id IS NOT NULL)::BOOLEAN AS enabled,
+  COALESCE(sc.position, 0)::INT AS position
+FROM category_definition c
+LEFT JOIN store_category sc
+  ON sc.category_id = c.category_id
+ AND sc.store_id = $1
+WHERE c.store_id IS NULL
+   OR c.store_id = $1
+ORDER BY enabled DESC, position, c.name
+`
+
+type ListStoreCategoryOptionsRow struct {
+	CategoryID int64
+	Name       string
+	ParentID   sql.NullInt64
+	IsPrivate  bool
+	Enabled    bool
+	Position   int32
+}
+
+// Every category a store can use: all global ones and its private ones,
+// enabled first in display order.
+func (q *Queries) ListStoreCategoryOptions(ctx context.Context, storeID int64) ([]ListStoreCategoryOptionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStoreCategoryOptions, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStoreCategoryOptionsRow
+	for rows.Next() {
+		var i ListStoreCategoryOptionsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Name,
+			&i.ParentID,
+			&i.IsPrivate,
+			&i.Enabled,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStoreCategoryTree = `-- name: ListStoreCategoryTree :many
+WITH RECURSIVE enabled AS (
+  SELECT c.category_id, c.name, c.parent_id, sc.position
+  FROM store_category sc
+  JOIN category_definition c
+    ON c.category_id = sc.category_id
+  WHERE sc.store_id = $1
+),
+subtree AS (
+  SELECT e.category_id AS root_id, e.category_id
+  FROM enabled e
+  UNION ALL
+  SELECT s.root_id, c.category_id
+  FROM subtree s
+  JOIN category_definition c
+    ON c.parent_id = s.category_id
+  WHERE c.store_id IS NULL OR c.store_id = $1
+),
+counts AS (
+  SELECT p.category_id, COUNT(*) AS n
+  FROM product p
+  WHERE p.store_id = $1
+    AND p.deleted_at IS NULL
+    AND p.in_stock
+  GROUP BY p.category_id
+)
+SELECT
+  e.category_id,
+  e.name,
+  e.parent_id,
+  COALESCE((
+    SELECT SUM(ct.n)
+    FROM subtree s
+    JOIN counts ct ON ct.category_id = s.category_id
+    WHERE s.root_id = e.category_id
+  ), 0)::BIGINT AS product_count
+FROM enabled e
+ORDER BY e.position, e.name
+`
+
+type ListStoreCategoryTreeRow struct {
+	CategoryID   int64
+	Name         string
+	ParentID     sql.NullInt64
+	ProductCount int64
+}
+
+// The store's enabled categories with the number of in-stock products in
+// each one and all of its subcategories.
+func (q *Queries) ListStoreCategoryTree(ctx context.Context, storeID int64) ([]ListStoreCategoryTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStoreCategoryTree, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStoreCategoryTreeRow
+	for rows.Next() {
+		var i ListStoreCategoryTreeRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Name,
+			&i.ParentID,
+			&i.ProductCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStoreExportAttributeNames = `-- name: ListStoreExportAttributeNames :many
+SELECT DISTINCT ad.name
+FROM variant_attribute_value vav
+JOIN attribute_definition ad
+  ON ad.attribute_id = vav.attribute_id
+JOIN product_variant v
+  ON v.variant_id = vav.variant_id
+JOIN product p
+  ON p.product_id = v.product_id
+WHERE p.store_id = $1
+  AND p.deleted_at IS NULL
+  AND v.deleted_at IS NULL
+ORDER BY ad.name
+`
+
+// Attribute names used by the store's live variants, the attribute columns
+// of a catalogue export.
+func (q *Queries) ListStoreExportAttributeNames(ctx context.Context, storeID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listStoreExportAttributeNames, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStoreOrders = `-- name: ListStoreOrders :many
+SELECT
+  co.order_id,
+  co.customer_id,
+  c.name AS customer_name,
+  c.email AS customer_email,
+  co.total_amount,
+  co.status,
+  co.created_at,
+  co.updated_at
+FROM customer_order co
+LEFT JOIN customer c
+  ON c.customer_id = co.customer_id
+WHERE co.store_id = $1
+  AND ($2::TEXT IS NULL OR co.status = $2)
+  AND ($3::BIGINT IS NULL OR co.customer_id = $3)
+  AND ($4::TIMESTAMPTZ IS NULL OR co.created_at >= $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR co.created_at < $5)
+  AND ($6::NUMERIC IS NULL OR co.total_amount >= $6)
+  AND ($7::NUMERIC IS NULL OR co.total_amount <= $7)
+ORDER BY co.created_at DESC, co.order_id DESC
+LIMIT $8 OFFSET $9
+`
+
+type ListStoreOrdersParams struct {
+	StoreID     int64
+	Status      sql.NullString
+	CustomerID  sql.NullInt64
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	MinAmount   sql.NullString
+	MaxAmount   sql.NullString
+	PageLimit   int32
+	PageOffset  int32
+}
+
+type ListStoreOrdersRow struct {
+	OrderID       int64
+	CustomerID    sql.NullInt64
+	CustomerName  sql.NullString
+	CustomerEmail sql.NullString
+	TotalAmount   string
+	Status        sql.NullString
+	CreatedAt     time.Time
+	UpdatedAt     sql.NullTime
+}
+
+func (q *Queries) ListStoreOrders(ctx context.Context, arg ListStoreOrdersParams) ([]ListStoreOrdersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStoreOrders,
+		arg.StoreID,
+		arg.Status,
+		arg.CustomerID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStoreOrdersRow
+	for rows.Next() {
+		var i ListStoreOrdersRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.CustomerID,
+			&i.CustomerName,
+			&i.CustomerEmail,
+			&i.TotalAmount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnshippedOrderItems = `-- name: ListUnshippedOrderItems :many
+SELECT
+  oi.order_item_id,
+  (oi.quantity - oi.refunded_quantity - COALESCE(SUM(si.quantity), 0))::INT AS unshipped
+FROM order_item oi
+LEFT JOIN shipment_item si ON si.order_item_id = oi.order_item_id
+WHERE oi.order_id = $1
+GROUP BY oi.order_item_id
+ORDER BY oi.order_item_id
+`
+
+type ListUnshippedOrderItemsRow struct {
+	OrderItemID int64
+	Unshipped   int32
+}
+
+func (q *Queries) ListUnshippedOrderItems(ctx context.Context, orderID int64) ([]ListUnshippedOrderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnshippedOrderItems, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnshippedOrderItemsRow
+	for rows.Next() {
+		var i ListUnshippedOrderItemsRow
+		if err := rows.Scan(&i.OrderItemID, &i.Unshipped); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markShipmentDelivered = `-- name: MarkShipmentDelivered :exec
+UPDATE shipment
+SET status = 'delivered',
+    delivered_at = NOW()
+WHERE shipment_id = $1
+`
+
+func (q *Queries) MarkShipmentDelivered(ctx context.Context, shipmentID int64) error {
+	_, err := q.db.ExecContext(ctx, markShipmentDelivered, shipmentID)
+	return err
+}
+
+const markShipmentShipped = `-- name: MarkShipmentShipped :exec
+UPDATE shipment
+SET status = 'shipped',
+    shipped_at = NOW()
+WHERE shipment_id = $1
+`
+
+func (q *Queries) MarkShipmentShipped(ctx context.Context, shipmentID int64) error {
+	_, err := q.db.ExecContext(ctx, markShipmentShipped, shipmentID)
+	return err
+}
+
+const mergeCartItems = `-- name: MergeCartItems :exec
+WITH updated AS (
+  UPDATE cart_item dst
+  SET quantity = dst.quantity + src.quantity,
+      updated_at = NOW()
+  FROM cart_item src
+  WHERE src.cart_id = $2
+    AND dst.cart_id = $1
+    AND src.variant_id = dst.variant_id
+  RETURNING src.cart_item_id
+)
+INSERT INTO cart_item (
+  cart_id,
+  variant_id,
+  quantity,
+  unit_price,
+  created_at,
+  updated_at
 )
 SELECT
   $1,
@@ -1552,25 +3790,191 @@ WHERE src.cart_id = $2
   )
 `
 
-type MergeCartItemsParams struct {
-	ToCartID   int64
-	FromCartID int64
+type MergeCartItemsParams struct {
+	ToCartID   int64
+	FromCartID int64
+}
+
+func (q *Queries) MergeCartItems(ctx context.Context, arg MergeCartItemsParams) error {
+	_, err := q.db.ExecContext(ctx, mergeCartItems, arg.ToCartID, arg.FromCartID)
+	return err
+}
+
+const openStockAlerts = `-- name: OpenStockAlerts :execrows
+INSERT INTO stock_alert (store_id, variant_id, stock_quantity, threshold)
+SELECT v.store_id,
+       v.variant_id,
+       v.stock_quantity,
+       COALESCE(v.low_stock_threshold, s.low_stock_threshold)
+FROM product_variant v
+JOIN product p ON p.product_id = v.product_id
+JOIN store s ON s.store_id = v.store_id
+WHERE v.deleted_at IS NULL
+  AND p.deleted_at IS NULL
+  AND v.stock_quantity <= COALESCE(v.low_stock_threshold, s.low_stock_threshold)
+ON CONFLICT (variant_id) WHERE status <> 'resolved' DO NOTHING
+`
+
+// Opens an alert for every live variant at or below its threshold
+// (the variant's own, else the store's) that has no unresolved alert yet.
+func (q *Queries) OpenStockAlerts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, openStockAlerts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const productSlugTaken = `-- name: ProductSlugTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product
+  WHERE store_id = $1 AND slug = $2 AND product_id <> $3
+)
+`
+
+type ProductSlugTakenParams struct {
+	StoreID   int64
+	Slug      sql.NullString
+	ProductID int64
+}
+
+func (q *Queries) ProductSlugTaken(ctx context.Context, arg ProductSlugTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, productSlugTaken, arg.StoreID, arg.Slug, arg.ProductID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const recalculateProductStock = `-- name: RecalculateProductStock :exec
+UPDATE product p
+SET stock_quantity = s.total,
+    in_stock = s.total > 0,
+    updated_at = NOW()
+FROM (
+  SELECT COALESCE(SUM(v.stock_quantity), 0)::INT AS total
+  FROM product_variant v
+  WHERE v.product_id = $1
+    AND v.deleted_at IS NULL
+) s
+WHERE p.product_id = $1
+`
+
+func (q *Queries) RecalculateProductStock(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, recalculateProductStock, productID)
+	return err
+}
+
+const refreshDefaultVariant = `-- name: RefreshDefaultVariant :exec
+UPDATE product p
+SET default_variant_id = (
+  SELECT v.variant_id
+  FROM product_variant v
+  WHERE v.product_id = p.product_id
+    AND v.deleted_at IS NULL
+  ORDER BY (v.stock_quantity > 0) DESC,
+           (v.variant_id = p.default_variant_id) DESC,
+           v.variant_id
+  LIMIT 1
+)
+WHERE p.product_id = $1
+`
+
+// Keeps the current default while it is live and in stock, otherwise fails
+// over to the first live variant with stock (or any live one, or none).
+func (q *Queries) RefreshDefaultVariant(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, refreshDefaultVariant, productID)
+	return err
+}
+
+const refreshProductSearch = `-- name: RefreshProductSearch :exec
+INSERT INTO product_search (product_id, document)
+SELECT p.product_id,
+       setweight(to_tsvector('simple', p.name), 'A') ||
+       setweight(to_tsvector('simple', COALESCE(p.brand, '')), 'B') ||
+       setweight(to_tsvector('simple', COALESCE((
+         SELECT string_agg(DISTINCT vav.value, ' ')
+         FROM variant_attribute_value vav
+         JOIN product_variant v ON v.variant_id = vav.variant_id
+         WHERE v.product_id = p.product_id
+           AND v.deleted_at IS NULL
+       ), '')), 'C') ||
+       setweight(to_tsvector('simple', COALESCE(p.description, '')), 'D')
+FROM product p
+WHERE p.product_id = $1
+ON CONFLICT (product_id) DO UPDATE
+SET document = EXCLUDED.document
+`
+
+func (q *Queries) RefreshProductSearch(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, refreshProductSearch, productID)
+	return err
+}
+
+const refundOrderItem = `-- name: RefundOrderItem :one
+UPDATE order_item
+SET refunded_quantity = refunded_quantity + $1::INT
+WHERE order_item_id = $2
+  AND order_id = $3
+  AND refunded_quantity + $1::INT <= quantity
+RETURNING variant_id, (unit_price * $1::INT)::NUMERIC AS amount
+`
+
+type RefundOrderItemParams struct {
+	Quantity    int32
+	OrderItemID int64
+	OrderID     int64
 }
 
-func (q *Queries) MergeCartItems(ctx context.Context, arg MergeCartItemsParams) error {
-	_, err := q.db.ExecContext(ctx, mergeCartItems, arg.ToCartID, arg.FromCartID)
-	return err
+type RefundOrderItemRow struct {
+	VariantID int64
+	Amount    string
+}
+
+func (q *Queries) RefundOrderItem(ctx context.Context, arg RefundOrderItemParams) (RefundOrderItemRow, error) {
+	row := q.db.QueryRowContext(ctx, refundOrderItem, arg.Quantity, arg.OrderItemID, arg.OrderID)
+	var i RefundOrderItemRow
+	err := row.Scan(&i.VariantID, &i.Amount)
+	return i, err
+}
+
+const reorderStoreCategories = `-- name: ReorderStoreCategories :execrows
+UPDATE store_category sc
+SET position = o.ord - 1
+FROM unnest($1::BIGINT[]) WITH ORDINALITY AS o(category_id, ord)
+WHERE sc.store_id = $2
+  AND sc.category_id = o.category_id
+`
+
+type ReorderStoreCategoriesParams struct {
+	CategoryIds []int64
+	StoreID     int64
+}
+
+// Positions follow the order of category_ids.
+func (q *Queries) ReorderStoreCategories(ctx context.Context, arg ReorderStoreCategoriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reorderStoreCategories, pq.Array(arg.CategoryIds), arg.StoreID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const resolveAttributeIDByName = `-- name: ResolveAttributeIDByName :one
 SELECT attribute_id
 FROM attribute_definition
 WHERE name = $1
+  AND (store_id IS NULL OR store_id = $2)
 LIMIT 1
 `
 
-func (q *Queries) ResolveAttributeIDByName(ctx context.Context, name string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, resolveAttributeIDByName, name)
+type ResolveAttributeIDByNameParams struct {
+	Name    string
+	StoreID sql.NullInt64
+}
+
+func (q *Queries) ResolveAttributeIDByName(ctx context.Context, arg ResolveAttributeIDByNameParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, resolveAttributeIDByName, arg.Name, arg.StoreID)
 	var attribute_id int64
 	err := row.Scan(&attribute_id)
 	return attribute_id, err
@@ -1597,6 +4001,58 @@ func (q *Queries) ResolveCategoryIDByName(ctx context.Context, arg ResolveCatego
 	return category_id, err
 }
 
+const resolveStockAlerts = `-- name: ResolveStockAlerts :execrows
+UPDATE stock_alert a
+SET status = 'resolved',
+    resolved_at = NOW()
+FROM product_variant v
+JOIN product p ON p.product_id = v.product_id
+JOIN store s ON s.store_id = v.store_id
+WHERE a.variant_id = v.variant_id
+  AND a.status <> 'resolved'
+  AND (v.deleted_at IS NOT NULL
+       OR p.deleted_at IS NOT NULL
+       OR v.stock_quantity > COALESCE(v.low_stock_threshold, s.low_stock_threshold))
+`
+
+// Resolves unresolved alerts whose variant is back above its threshold
+// or no longer sold.
+func (q *Queries) ResolveStockAlerts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resolveStockAlerts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retireVariant = `-- name: RetireVariant :exec
+UPDATE product_variant
+SET deleted_at = NOW(),
+    updated_at = NOW()
+WHERE variant_id = $1
+`
+
+func (q *Queries) RetireVariant(ctx context.Context, variantID int64) error {
+	_, err := q.db.ExecContext(ctx, retireVariant, variantID)
+	return err
+}
+
+const revertOrderItemRefund = `-- name: RevertOrderItemRefund :exec
+UPDATE order_item
+SET refunded_quantity = refunded_quantity - $2
+WHERE order_item_id = $1
+`
+
+type RevertOrderItemRefundParams struct {
+	OrderItemID      int64
+	RefundedQuantity int32
+}
+
+func (q *Queries) RevertOrderItemRefund(ctx context.Context, arg RevertOrderItemRefundParams) error {
+	_, err := q.db.ExecContext(ctx, revertOrderItemRefund, arg.OrderItemID, arg.RefundedQuantity)
+	return err
+}
+
 const revokeRefreshToken = `-- name: RevokeRefreshToken :exec
 UPDATE refresh_token
 SET revoked = TRUE
@@ -1608,6 +4064,22 @@ func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) error {
 	return err
 }
 
+const setCartItemQuantity = `-- name: SetCartItemQuantity :exec
+UPDATE cart_item
+SET quantity = $2
+WHERE cart_item_id = $1
+`
+
+type SetCartItemQuantityParams struct {
+	CartItemID int64
+	Quantity   int32
+}
+
+func (q *Queries) SetCartItemQuantity(ctx context.Context, arg SetCartItemQuantityParams) error {
+	_, err := q.db.ExecContext(ctx, setCartItemQuantity, arg.CartItemID, arg.Quantity)
+	return err
+}
+
 const setDefaultVariant = `-- name: SetDefaultVariant :exec
 UPDATE product
 SET default_variant_id = $2
@@ -1624,6 +4096,22 @@ func (q *Queries) SetDefaultVariant(ctx context.Context, arg SetDefaultVariantPa
 	return err
 }
 
+const setOrderReservationsStatus = `-- name: SetOrderReservationsStatus :exec
+UPDATE stock_reservation
+SET status = $2
+WHERE order_id = $1 AND status = 'active'
+`
+
+type SetOrderReservationsStatusParams struct {
+	OrderID int64
+	Status  string
+}
+
+func (q *Queries) SetOrderReservationsStatus(ctx context.Context, arg SetOrderReservationsStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setOrderReservationsStatus, arg.OrderID, arg.Status)
+	return err
+}
+
 const setPrimaryVariantImage = `-- name: SetPrimaryVariantImage :exec
 UPDATE product_variant
 SET primary_image_url = $2
@@ -1640,6 +4128,39 @@ func (q *Queries) SetPrimaryVariantImage(ctx context.Context, arg SetPrimaryVari
 	return err
 }
 
+const snoozeStockAlert = `-- name: SnoozeStockAlert :execrows
+UPDATE stock_alert
+SET snoozed_until = $3
+WHERE alert_id = $1
+  AND store_id = $2
+  AND status <> 'resolved'
+`
+
+type SnoozeStockAlertParams struct {
+	AlertID      int64
+	StoreID      int64
+	SnoozedUntil sql.NullTime
+}
+
+func (q *Queries) SnoozeStockAlert(ctx context.Context, arg SnoozeStockAlertParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, snoozeStockAlert, arg.AlertID, arg.StoreID, arg.SnoozedUntil)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const startImportJob = `-- name: StartImportJob :exec
+UPDATE product_import_job
+SET status = 'running'
+WHERE job_id = $1
+`
+
+func (q *Queries) StartImportJob(ctx context.Context, jobID int64) error {
+	_, err := q.db.ExecContext(ctx, startImportJob, jobID)
+	return err
+}
+
 const touchCart = `-- name: TouchCart :exec
 UPDATE cart SET updated_at = NOW() WHERE cart_id = $1
 `
@@ -1649,6 +4170,69 @@ func (q *Queries) TouchCart(ctx context.Context, cartID int64) error {
 	return err
 }
 
+const touchVisitorSession = `-- name: TouchVisitorSession :one
+UPDATE visitor_session
+SET last_seen_at = NOW(),
+    is_returning = COALESCE(is_returning, FALSE) OR last_seen_at < $1,
+    ip_address = COALESCE($2, ip_address),
+    user_agent = COALESCE($3, user_agent),
+    customer_id = COALESCE(customer_id, $4)
+WHERE session_id = $5 AND store_id = $6
+RETURNING session_id, store_id, customer_id, ip_address, user_agent, first_seen_at, last_seen_at, is_returning
+`
+
+type TouchVisitorSessionParams struct {
+	VisitStartedBefore sql.NullTime
+	IpAddress          pqtype.Inet
+	UserAgent          sql.NullString
+	CustomerID         sql.NullInt64
+	SessionID          uuid.UUID
+	StoreID            int64
+}
+
+// A session seen again after visit_started_before starts a new visit,
+// which makes the visitor a returning one.
+func (q *Queries) TouchVisitorSession(ctx context.Context, arg TouchVisitorSessionParams) (VisitorSession, error) {
+	row := q.db.QueryRowContext(ctx, touchVisitorSession,
+		arg.VisitStartedBefore,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.CustomerID,
+		arg.SessionID,
+		arg.StoreID,
+	)
+	var i VisitorSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.StoreID,
+		&i.CustomerID,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.IsReturning,
+	)
+	return i, err
+}
+
+const updateImportJobProgress = `-- name: UpdateImportJobProgress :exec
+UPDATE product_import_job
+SET processed_rows = $2,
+    failed_rows = $3
+WHERE job_id = $1
+`
+
+type UpdateImportJobProgressParams struct {
+	JobID         int64
+	ProcessedRows int32
+	FailedRows    int32
+}
+
+func (q *Queries) UpdateImportJobProgress(ctx context.Context, arg UpdateImportJobProgressParams) error {
+	_, err := q.db.ExecContext(ctx, updateImportJobProgress, arg.JobID, arg.ProcessedRows, arg.FailedRows)
+	return err
+}
+
 const updateOrderStatus = `-- name: UpdateOrderStatus :exec
 UPDATE customer_order
 SET status = $2,
@@ -1666,6 +4250,78 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 	return err
 }
 
+const updatePaymentStatus = `-- name: UpdatePaymentStatus :exec
+UPDATE payment
+SET status = $2,
+    transaction_ref = COALESCE($3, transaction_ref)
+WHERE payment_id = $1
+`
+
+type UpdatePaymentStatusParams struct {
+	PaymentID      int64
+	Status         string
+	TransactionRef sql.NullString
+}
+
+func (q *Queries) UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updatePaymentStatus, arg.PaymentID, arg.Status, arg.TransactionRef)
+	return err
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE product
+SET name = $3,
+    slug = $4,
+    description = $5,
+    brand = $6,
+    category_id = $7,
+    deleted_at = $8,
+    updated_at = NOW()
+WHERE product_id = $1 AND store_id = $2
+RETURNING product_id, store_id, category_id, name, slug, description, brand, stock_quantity, created_at, updated_at, in_stock, deleted_at, default_variant_id
+`
+
+type UpdateProductParams struct {
+	ProductID   int64
+	StoreID     int64
+	Name        string
+	Slug        sql.NullString
+	Description sql.NullString
+	Brand       sql.NullString
+	CategoryID  int64
+	DeletedAt   sql.NullTime
+}
+
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, updateProduct,
+		arg.ProductID,
+		arg.StoreID,
+		arg.Name,
+		arg.Slug,
+		arg.Description,
+		arg.Brand,
+		arg.CategoryID,
+		arg.DeletedAt,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ProductID,
+		&i.StoreID,
+		&i.CategoryID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.Brand,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InStock,
+		&i.DeletedAt,
+		&i.DefaultVariantID,
+	)
+	return i, err
+}
+
 const updateProductStock = `-- name: UpdateProductStock :exec
 UPDATE product
 SET stock_quantity = stock_quantity + $2,
@@ -1684,6 +4340,39 @@ func (q *Queries) UpdateProductStock(ctx context.Context, arg UpdateProductStock
 	return err
 }
 
+const updateStoreCategoryDefinition = `-- name: UpdateStoreCategoryDefinition :one
+UPDATE category_definition
+SET name = $3,
+    parent_id = $4
+WHERE category_id = $1
+  AND store_id = $2
+RETURNING category_id, name, parent_id, store_id
+`
+
+type UpdateStoreCategoryDefinitionParams struct {
+	CategoryID int64
+	StoreID    sql.NullInt64
+	Name       string
+	ParentID   sql.NullInt64
+}
+
+func (q *Queries) UpdateStoreCategoryDefinition(ctx context.Context, arg UpdateStoreCategoryDefinitionParams) (CategoryDefinition, error) {
+	row := q.db.QueryRowContext(ctx, updateStoreCategoryDefinition,
+		arg.CategoryID,
+		arg.StoreID,
+		arg.Name,
+		arg.ParentID,
+	)
+	var i CategoryDefinition
+	err := row.Scan(
+		&i.CategoryID,
+		&i.Name,
+		&i.ParentID,
+		&i.StoreID,
+	)
+	return i, err
+}
+
 const updateStoreDownloadStatus = `-- name: UpdateStoreDownloadStatus :exec
 UPDATE store
 SET download_status = $2,
@@ -1701,6 +4390,102 @@ func (q *Queries) UpdateStoreDownloadStatus(ctx context.Context, arg UpdateStore
 	return err
 }
 
+const updateStoreLowStockThreshold = `-- name: UpdateStoreLowStockThreshold :one
+UPDATE store
+SET low_stock_threshold = $2,
+    updated_at = NOW()
+WHERE store_id = $1
+RETURNING low_stock_threshold
+`
+
+type UpdateStoreLowStockThresholdParams struct {
+	StoreID           int64
+	LowStockThreshold int32
+}
+
+func (q *Queries) UpdateStoreLowStockThreshold(ctx context.Context, arg UpdateStoreLowStockThresholdParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, updateStoreLowStockThreshold, arg.StoreID, arg.LowStockThreshold)
+	var low_stock_threshold int32
+	err := row.Scan(&low_stock_threshold)
+	return low_stock_threshold, err
+}
+
+const updateVariant = `-- name: UpdateVariant :one
+UPDATE product_variant
+SET sku = $2,
+    price = $3,
+    attribute_hash = $4,
+    updated_at = NOW()
+WHERE variant_id = $1
+RETURNING variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
+`
+
+type UpdateVariantParams struct {
+	VariantID     int64
+	Sku           string
+	Price         string
+	AttributeHash string
+}
+
+func (q *Queries) UpdateVariant(ctx context.Context, arg UpdateVariantParams) (ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, updateVariant,
+		arg.VariantID,
+		arg.Sku,
+		arg.Price,
+		arg.AttributeHash,
+	)
+	var i ProductVariant
+	err := row.Scan(
+		&i.VariantID,
+		&i.ProductID,
+		&i.StoreID,
+		&i.AttributeHash,
+		&i.Sku,
+		&i.Price,
+		&i.StockQuantity,
+		&i.PrimaryImageUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowStockThreshold,
+	)
+	return i, err
+}
+
+const updateVariantLowStockThreshold = `-- name: UpdateVariantLowStockThreshold :one
+UPDATE product_variant
+SET low_stock_threshold = $1,
+    updated_at = NOW()
+WHERE variant_id = $2
+RETURNING variant_id, product_id, store_id, attribute_hash, sku, price, stock_quantity, primary_image_url, created_at, updated_at, deleted_at, low_stock_threshold
+`
+
+type UpdateVariantLowStockThresholdParams struct {
+	LowStockThreshold sql.NullInt32
+	VariantID         int64
+}
+
+// A NULL threshold falls back to the store's.
+func (q *Queries) UpdateVariantLowStockThreshold(ctx context.Context, arg UpdateVariantLowStockThresholdParams) (ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, updateVariantLowStockThreshold, arg.LowStockThreshold, arg.VariantID)
+	var i ProductVariant
+	err := row.Scan(
+		&i.VariantID,
+		&i.ProductID,
+		&i.StoreID,
+		&i.AttributeHash,
+		&i.Sku,
+		&i.Price,
+		&i.StockQuantity,
+		&i.PrimaryImageUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.LowStockThreshold,
+	)
+	return i, err
+}
+
 const upsertCartItem = `-- name: UpsertCartItem :exec
 INSERT INTO cart_item (cart_id, variant_id, quantity, unit_price)
 VALUES ($1, $2, $3, $4)
@@ -1725,3 +4510,49 @@ func (q *Queries) UpsertCartItem(ctx context.Context, arg UpsertCartItemParams)
 	)
 	return err
 }
+
+const upsertCategoryAttribute = `-- name: UpsertCategoryAttribute :exec
+INSERT INTO category_attribute (category_id, attribute_id, is_required, allowed_values)
+VALUES ($1, $2, $3, $4::TEXT[])
+ON CONFLICT (category_id, attribute_id) DO UPDATE
+SET is_required = EXCLUDED.is_required,
+    allowed_values = EXCLUDED.allowed_values
+`
+
+type UpsertCategoryAttributeParams struct {
+	CategoryID    int64
+	AttributeID   int64
+	IsRequired    bool
+	AllowedValues []string
+}
+
+func (q *Queries) UpsertCategoryAttribute(ctx context.Context, arg UpsertCategoryAttributeParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCategoryAttribute,
+		arg.CategoryID,
+		arg.AttributeID,
+		arg.IsRequired,
+		pq.Array(arg.AllowedValues),
+	)
+	return err
+}
+
+const variantSKUTaken = `-- name: VariantSKUTaken :one
+SELECT EXISTS (
+  SELECT 1
+  FROM product_variant
+  WHERE store_id = $1 AND sku = $2 AND variant_id <> $3
+)
+`
+
+type VariantSKUTakenParams struct {
+	StoreID   int64
+	Sku       string
+	VariantID int64
+}
+
+func (q *Queries) VariantSKUTaken(ctx context.Context, arg VariantSKUTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, variantSKUTaken, arg.StoreID, arg.Sku, arg.VariantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
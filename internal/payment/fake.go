@@ -0,0 +1,128 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeMethod is the payment_method the fake provider is registered under.
+const FakeMethod = "fake"
+
+// FakeDeclineSuffix makes the fake provider decline any amount ending in it,
+// so failure paths can be exercised without a real gateway.
+const FakeDeclineSuffix = ".02"
+
+// FakeProvider is a deterministic in-process Provider for development and
+// tests only. Transaction references are derived from the order and amount,
+// so the same request always yields the same reference, and webhooks are
+// signed with HMAC-SHA256 over the raw body using the configured secret. Its
+// authorizations live in memory and are lost on restart, after which it can
+// no longer capture, void or refund them; it must never take real orders.
+type FakeProvider struct {
+	secret []byte
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	amount string
+	status Status
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   []byte(secret),
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, req ChargeRequest) (Result, error) {
+	ref := fakeReference(req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasSuffix(req.Amount, FakeDeclineSuffix) {
+		f.payments[ref] = &fakePayment{amount: req.Amount, status: StatusFailed}
+		return Result{TransactionRef: ref, Status: StatusFailed, Message: "card declined"}, ErrDeclined
+	}
+
+	f.payments[ref] = &fakePayment{amount: req.Amount, status: StatusPending}
+	return Result{TransactionRef: ref, Status: StatusPending, Message: "authorized"}, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, transactionRef string, amount string) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[transactionRef]
+	if !ok {
+		return Result{}, ErrUnknownReference
+	}
+	if p.status != StatusCompleted && p.status != StatusRefunded {
+		return Result{TransactionRef: transactionRef, Status: p.status}, fmt.Errorf("cannot refund %s payment", p.status)
+	}
+
+	p.status = StatusRefunded
+	return Result{TransactionRef: transactionRef, Status: StatusRefunded, Message: "refunded"}, nil
+}
+
+func (f *FakeProvider) Void(ctx context.Context, transactionRef string) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[transactionRef]
+	if !ok {
+		return Result{}, ErrUnknownReference
+	}
+	if p.status != StatusPending {
+		return Result{TransactionRef: transactionRef, Status: p.status}, fmt.Errorf("cannot void %s payment", p.status)
+	}
+
+	// a voided authorization never settles, which the schema records as failed
+	p.status = StatusFailed
+	return Result{TransactionRef: transactionRef, Status: StatusFailed, Message: "voided"}, nil
+}
+
+func (f *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected := f.Sign(payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	if event.TransactionRef == "" {
+		return nil, ErrUnknownReference
+	}
+
+	// Mirror the outcome, as a real gateway would have settled it on its side
+	f.mu.Lock()
+	if p, ok := f.payments[event.TransactionRef]; ok {
+		p.status = event.Status
+	}
+	f.mu.Unlock()
+
+	return &event, nil
+}
+
+// Sign returns the hex HMAC-SHA256 signature VerifyWebhook expects for payload.
+func (f *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func fakeReference(req ChargeRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%s", req.StoreID, req.OrderID, req.Amount)))
+	return "fake_" + hex.EncodeToString(sum[:12])
+}
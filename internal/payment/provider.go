@@ -0,0 +1,61 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// Status mirrors the values allowed in payment.status.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusRefunded  Status = "refunded"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrUnknownReference = errors.New("unknown transaction reference")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// ChargeRequest describes the amount to authorize for an order.
+// Amount is a decimal string, as stored in the database.
+type ChargeRequest struct {
+	StoreID  int64
+	OrderID  int64
+	Amount   string
+	Currency string
+}
+
+// Result is the provider's answer to a payment operation. Status is what the
+// payment row should be set to; TransactionRef identifies the payment at the
+// provider and is used for every follow-up call.
+type Result struct {
+	TransactionRef string
+	Status         Status
+	Message        string
+}
+
+// WebhookEvent is a verified asynchronous notification from a provider.
+type WebhookEvent struct {
+	TransactionRef string `json:"transaction_ref"`
+	Status         Status `json:"status"`
+}
+
+// Provider is implemented by every payment gateway integration.
+//
+// Authorize starts an authorize-and-capture payment and returns a pending
+// result; the provider settles it on its own and reports the outcome through
+// a webhook, so a completed event means the funds were taken. Refund returns
+// captured funds (fully or partially) and Void releases an authorization that
+// has not settled yet. VerifyWebhook checks the signature of a raw callback
+// body and decodes it.
+type Provider interface {
+	Authorize(ctx context.Context, req ChargeRequest) (Result, error)
+	Refund(ctx context.Context, transactionRef string, amount string) (Result, error)
+	Void(ctx context.Context, transactionRef string) (Result, error)
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
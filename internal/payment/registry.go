@@ -0,0 +1,41 @@
+package payment
+
+import (
+	"sync"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+)
+
+// Registry maps the payment_method sent at checkout to a Provider.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds or replaces the provider used for method.
+func (r *Registry) Register(method string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[method] = p
+}
+
+// Get returns the provider for method, errorx.ErrNoPaymentProvider when none
+// is registered at all, or errorx.ErrUnsupportedPaymentMethod.
+func (r *Registry) Get(method string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.providers) == 0 {
+		return nil, errorx.ErrNoPaymentProvider
+	}
+	p, ok := r.providers[method]
+	if !ok {
+		return nil, errorx.ErrUnsupportedPaymentMethod
+	}
+	return p, nil
+}
@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+// maxPeriodDays caps the range a single dashboard request may cover.
+const maxPeriodDays = 366
+
+// Period is a half-open [From, To) interval expressed in the store's timezone.
+type Period struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// resolvePeriod turns the optional "from" / "to" query values (YYYY-MM-DD, both
+// inclusive) into period boundaries at midnight in the store's timezone.
+// When both are empty, the current calendar month of the store is used.
+func resolvePeriod(timezone sql.NullString, from, to string, now time.Time) (Period, error) {
+	loc := utils.StoreLocation(timezone)
+	local := now.In(loc)
+
+	if from == "" && to == "" {
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		return Period{From: start, To: start.AddDate(0, 1, 0), Location: loc}, nil
+	}
+
+	var (
+		start time.Time
+		end   time.Time
+		err   error
+	)
+
+	if from != "" {
+		if start, err = utils.ParseDay(from, loc); err != nil {
+			return Period{}, err
+		}
+	}
+
+	if to != "" {
+		if end, err = utils.ParseDay(to, loc); err != nil {
+			return Period{}, err
+		}
+	} else {
+		end = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	}
+
+	// "to" is inclusive for the caller, exclusive for the queries
+	end = end.AddDate(0, 0, 1)
+
+	if from == "" {
+		start = time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, loc)
+		if !start.Before(end) {
+			start = start.AddDate(0, -1, 0)
+		}
+	}
+
+	if !start.Before(end) || end.Sub(start) > maxPeriodDays*24*time.Hour {
+		return Period{}, errorx.ErrInvalidDateRange
+	}
+
+	return Period{From: start, To: end, Location: loc}, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// numericString converts an untyped sqlc column (numeric / bigint / NULL) into
+// its decimal string representation; NULL becomes "0".
+func numericString(v interface{}) string {
+	if s := nullableNumeric(v); s != nil {
+		return *s
+	}
+	return "0"
+}
+
+// nullableNumeric converts an untyped sqlc column into a decimal string,
+// keeping NULL (e.g. a growth percentage without a previous period) as nil.
+func nullableNumeric(v interface{}) *string {
+	var s string
+
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s = string(val)
+	case string:
+		s = val
+	case int64:
+		s = strconv.FormatInt(val, 10)
+	case float64:
+		s = strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	return &s
+}
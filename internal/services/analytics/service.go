@@ -1,10 +1,16 @@
 package analytics
 
 import (
+	"context"
+	"time"
 
 	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
 )
 
+// lowStockListLimit bounds the low-stock table on the inventory page.
+const lowStockListLimit = 20
+
 type Service struct {
 	db *database.DB
 }
@@ -13,4 +19,406 @@ func New(db *database.DB) *Service {
 	return &Service{db: db}
 }
 
+// ResolvePeriod loads the store timezone and computes the [from, to) boundaries
+// used by every dashboard query.
+func (s *Service) ResolvePeriod(ctx context.Context, storeID int64, from, to string) (Period, error) {
+	store, err := s.db.Queries.GetStore(ctx, storeID)
+	if err != nil {
+		return Period{}, err
+	}
+
+	return resolvePeriod(store.Timezone, from, to, time.Now())
+}
+
+func (p Period) dto() models.AnalyticsPeriodDTO {
+	return models.AnalyticsPeriodDTO{
+		From:     p.From,
+		To:       p.To,
+		Timezone: p.Location.String(),
+	}
+}
+
+// Overview assembles the KPI cards and charts shown on the dashboard home page.
+func (s *Service) Overview(ctx context.Context, storeID int64, p Period) (*models.AnalyticsOverviewDTO, error) {
+	q := s.db.Queries
+
+	revenue, err := q.GetTotalRevenueCurrentMonth(ctx, models.GetTotalRevenueCurrentMonthParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revenueGrowth, err := q.GetRevenueGrowthPercent(ctx, models.GetRevenueGrowthPercentParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newOrders, err := q.GetNewOrdersThisMonth(ctx, models.GetNewOrdersThisMonthParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ordersGrowth, err := q.GetOrdersGrowthPercent(ctx, models.GetOrdersGrowthPercentParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	visitors, err := q.GetTotalVisitors(ctx, models.GetTotalVisitorsParams{
+		StoreID: storeID, FirstSeenAt: nullTime(p.From), FirstSeenAt_2: nullTime(p.To),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pageViews, err := q.GetPageViews(ctx, models.GetPageViewsParams{
+		StoreID: storeID, ViewedAt: nullTime(p.From), ViewedAt_2: nullTime(p.To),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := q.GetRegisteredCustomers(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	newCustomers, err := q.GetNewRegisteredCustomers(ctx, models.GetNewRegisteredCustomersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := q.GetConversionRate(ctx, models.GetConversionRateParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revenueRows, err := q.GetRevenueOverTime(ctx, models.GetRevenueOverTimeParams{
+		Timezone: p.Location.String(), StoreID: storeID, FromTime: p.From, ToTime: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revenueOverTime := make([]models.RevenuePointDTO, 0, len(revenueRows))
+	for _, r := range revenueRows {
+		revenueOverTime = append(revenueOverTime, models.RevenuePointDTO{
+			Date:    r.OrderDate,
+			Revenue: r.Revenue,
+			Orders:  r.TotalOrders,
+		})
+	}
+
+	topRows, err := q.GetTopSellingProducts(ctx, models.GetTopSellingProductsParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	topProducts := make([]models.TopSellingProductDTO, 0, len(topRows))
+	for _, r := range topRows {
+		topProducts = append(topProducts, models.TopSellingProductDTO{
+			ProductName: r.ProductName,
+			UnitsSold:   r.UnitsSold,
+			Revenue:     r.Revenue,
+		})
+	}
+
+	return &models.AnalyticsOverviewDTO{
+		Period:               p.dto(),
+		TotalRevenue:         numericString(revenue),
+		RevenueGrowthPercent: nullableNumeric(revenueGrowth),
+		NewOrders:            newOrders,
+		OrdersGrowthPercent:  nullableNumeric(ordersGrowth),
+		TotalVisitors:        visitors,
+		PageViews:            pageViews,
+		RegisteredCustomers:  customers,
+		NewCustomers:         newCustomers,
+		ConversionRate:       conversion,
+		RevenueOverTime:      revenueOverTime,
+		TopSellingProducts:   topProducts,
+	}, nil
+}
+
+// Orders returns status counters for the orders placed in the period and the
+// delivery performance of the shipments delivered in it.
+func (s *Service) Orders(ctx context.Context, storeID int64, p Period) (*models.AnalyticsOrdersDTO, error) {
+	q := s.db.Queries
+
+	total, err := q.GetTotalOrders(ctx, models.GetTotalOrdersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := q.GetPendingOrders(ctx, models.GetPendingOrdersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shipped, err := q.GetShippedOrders(ctx, models.GetShippedOrdersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := q.GetCompletedOrders(ctx, models.GetCompletedOrdersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newOrders, err := q.GetNewOrdersThisMonth(ctx, models.GetNewOrdersThisMonthParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ordersGrowth, err := q.GetOrdersGrowthPercent(ctx, models.GetOrdersGrowthPercentParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	avgDelivery, err := q.GetAverageDeliveryDays(ctx, models.GetAverageDeliveryDaysParams{
+		StoreID: storeID, DeliveredAt: nullTime(p.From), DeliveredAt_2: nullTime(p.To),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnalyticsOrdersDTO{
+		Period:              p.dto(),
+		TotalOrders:         total,
+		PendingOrders:       pending,
+		ShippedOrders:       shipped,
+		CompletedOrders:     completed,
+		NewOrders:           newOrders,
+		OrdersGrowthPercent: nullableNumeric(ordersGrowth),
+		AverageDeliveryDays: avgDelivery,
+	}, nil
+}
+
+// Customers returns registration and purchasing-customer metrics.
+func (s *Service) Customers(ctx context.Context, storeID int64, p Period) (*models.AnalyticsCustomersDTO, error) {
+	q := s.db.Queries
+
+	registered, err := q.GetRegisteredCustomers(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	newCustomers, err := q.GetNewRegisteredCustomers(ctx, models.GetNewRegisteredCustomersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	customersGrowth, err := q.GetCustomersGrowthPercent(ctx, models.GetCustomersGrowthPercentParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	purchasing, err := q.GetPurchasingCustomers(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	newPurchasing, err := q.GetCountOfNewPurchasingCustomers(ctx, models.GetCountOfNewPurchasingCustomersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	purchasingGrowth, err := q.GetPurchasingCustomersGrowthPercent(ctx, models.GetPurchasingCustomersGrowthPercentParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loyalRows, err := q.GetLoyalCustomers(ctx, models.GetLoyalCustomersParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loyal := make([]models.LoyalCustomerDTO, 0, len(loyalRows))
+	for _, r := range loyalRows {
+		loyal = append(loyal, models.LoyalCustomerDTO{
+			CustomerName: r.CustomerName,
+			TotalSpent:   r.TotalSpent,
+			OrdersCount:  r.OrdersCount,
+		})
+	}
+
+	return &models.AnalyticsCustomersDTO{
+		Period:                           p.dto(),
+		RegisteredCustomers:              registered,
+		NewCustomers:                     newCustomers,
+		CustomersGrowthPercent:           nullableNumeric(customersGrowth),
+		PurchasingCustomers:              purchasing,
+		NewPurchasingCustomers:           newPurchasing,
+		PurchasingCustomersGrowthPercent: nullableNumeric(purchasingGrowth),
+		LoyalCustomers:                   loyal,
+	}, nil
+}
+
+// Inventory returns stock health and per-product performance.
+func (s *Service) Inventory(ctx context.Context, storeID int64, p Period) (*models.AnalyticsInventoryDTO, error) {
+	q := s.db.Queries
+
+	totalProducts, err := q.GetTotalProducts(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	lowCount, err := q.GetCountOfLowAndOutOfStockProducts(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	lowRows, err := q.ListLowStockProducts(ctx, models.ListLowStockProductsParams{
+		StoreID: storeID,
+		Limit:   lowStockListLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lowStock := make([]models.LowStockProductDTO, 0, len(lowRows))
+	for _, r := range lowRows {
+		var updatedAt *time.Time
+		if r.UpdatedAt.Valid {
+			updatedAt = &r.UpdatedAt.Time
+		}
+		lowStock = append(lowStock, models.LowStockProductDTO{
+			Name:          r.Name,
+			StockQuantity: r.StockQuantity,
+			StockStatus:   numericString(r.StockStatus),
+			UpdatedAt:     updatedAt,
+		})
+	}
+
+	tableRows, err := q.ListProductTable(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]models.ProductPerformanceDTO, 0, len(tableRows))
+	for _, r := range tableRows {
+		products = append(products, models.ProductPerformanceDTO{
+			ProductName:          r.ProductName,
+			UnitsSold:            numericString(r.UnitsSold),
+			TotalViews:           numericString(r.TotalViews),
+			ViewsToPurchaseRatio: nullableNumeric(r.ViewsToPurchaseRatio),
+			StockStatus:          r.StockStatus,
+		})
+	}
+
+	attentionRows, err := q.GetProductsNeedingAttention(ctx, models.GetProductsNeedingAttentionParams{
+		StoreID: storeID, CreatedAt: p.From, CreatedAt_2: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attention := make([]models.ProductSalesDTO, 0, len(attentionRows))
+	for _, r := range attentionRows {
+		attention = append(attention, models.ProductSalesDTO{
+			ProductName: r.ProductName,
+			UnitsSold:   numericString(r.UnitsSold),
+			Revenue:     numericString(r.Revenue),
+		})
+	}
+
+	popularRows, err := q.GetPopularButNotSellingProducts(ctx, models.GetPopularButNotSellingProductsParams{
+		StoreID: storeID, ViewedAt: nullTime(p.From), ViewedAt_2: nullTime(p.To),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	popular := make([]models.PopularProductDTO, 0, len(popularRows))
+	for _, r := range popularRows {
+		popular = append(popular, models.PopularProductDTO{
+			ProductID:  r.ProductID,
+			Views:      r.Views,
+			SalesCount: r.SalesCount,
+		})
+	}
+
+	return &models.AnalyticsInventoryDTO{
+		Period:                   p.dto(),
+		TotalProducts:            totalProducts,
+		LowAndOutOfStockProducts: lowCount,
+		LowStockProducts:         lowStock,
+		Products:                 products,
+		NeedingAttention:         attention,
+		PopularButNotSelling:     popular,
+	}, nil
+}
+
+// Funnel returns the visit -> purchase funnel and daily conversion.
+func (s *Service) Funnel(ctx context.Context, storeID int64, p Period) (*models.AnalyticsFunnelDTO, error) {
+	q := s.db.Queries
+
+	m, err := q.GetFunnelMetrics(ctx, models.GetFunnelMetricsParams{
+		StoreID: storeID, FirstSeenAt: nullTime(p.From), FirstSeenAt_2: nullTime(p.To),
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	conversionRows, err := q.GetConversionOverTime(ctx, models.GetConversionOverTimeParams{
+		Timezone: p.Location.String(), StoreID: storeID, FromTime: p.From, ToTime: p.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := make([]models.ConversionPointDTO, 0, len(conversionRows))
+	for _, r := range conversionRows {
+		conversion = append(conversion, models.ConversionPointDTO{
+			Date:                  r.Day,
+			ConversionRatePercent: r.ConversionRatePercent,
+		})
+	}
+
+	return &models.AnalyticsFunnelDTO{
+		Period:                    p.dto(),
+		SiteVisits:                m.SiteVisits,
+		ProductViews:              m.ProductViews,
+		AddedToCart:               m.AddedToCart,
+		CheckoutStarted:           m.CheckoutStarted,
+		PurchaseComplete:          m.PurchaseComplete,
+		DropSiteToViewPct:         m.DropSiteToViewPct,
+		DropViewToCartPct:         m.DropViewToCartPct,
+		DropCartToCheckoutPct:     m.DropCartToCheckoutPct,
+		DropCheckoutToPurchasePct: m.DropCheckoutToPurchasePct,
+		ConversionOverTime:        conversion,
+	}, nil
+}
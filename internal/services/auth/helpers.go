@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/Secure-Website-Builder/Backend/internal/models"
 	"github.com/google/uuid"
@@ -17,6 +18,20 @@ func (s *Service) mergeCustomerCartOnLogin(
 
 	return s.db.RunInTx(ctx, func(q *models.Queries) error {
 
+		// Claim the visitor session; leave carts alone when it is unknown
+		// or already belongs to another customer
+		_, err := q.AttachSessionCustomer(ctx, models.AttachSessionCustomerParams{
+			CustomerID: sql.NullInt64{Int64: customerID, Valid: true},
+			SessionID:  sessionID,
+			StoreID:    storeID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
 		var (
 			sessionCart  *models.Cart
 			customerCart *models.Cart
@@ -29,7 +44,7 @@ func (s *Service) mergeCustomerCartOnLogin(
 		})
 		if err == nil {
 			sessionCart = &cartBySession
-		} else if err != sql.ErrNoRows {
+		} else if !errors.Is(err, sql.ErrNoRows) {
 			return err
 		}
 
@@ -40,7 +55,7 @@ func (s *Service) mergeCustomerCartOnLogin(
 		})
 		if err == nil {
 			customerCart = &cartByCustomer
-		} else if err != sql.ErrNoRows {
+		} else if !errors.Is(err, sql.ErrNoRows) {
 			return err
 		}
 
@@ -58,9 +73,13 @@ func (s *Service) mergeCustomerCartOnLogin(
 			})
 		}
 
-		// Only customer cart - nothing to merge
+		// Only customer cart - nothing to merge, follow the customer to this session
 		if sessionCart == nil && customerCart != nil {
-			return nil
+			return q.AttachCartToCustomer(ctx, models.AttachCartToCustomerParams{
+				CustomerID: sql.NullInt64{Int64: customerID, Valid: true},
+				SessionID:  sessionID,
+				CartID:     customerCart.CartID,
+			})
 		}
 
 		// Both carts exist - merge items
@@ -77,6 +96,11 @@ func (s *Service) mergeCustomerCartOnLogin(
 			return err
 		}
 
-		return nil
+		// The merged cart now belongs to this session
+		return q.AttachCartToCustomer(ctx, models.AttachCartToCustomerParams{
+			CustomerID: sql.NullInt64{Int64: customerID, Valid: true},
+			SessionID:  sessionID,
+			CartID:     customerCart.CartID,
+		})
 	})
 }
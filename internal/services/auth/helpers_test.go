@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the database named by TEST_DATABASE_URL, which must
+// have internal/database/schema.sql applied, and skips the test without it.
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	return database.NewDB(conn)
+}
+
+// A customer whose cart was left on another device's session logs in on a
+// session without a cart: the cart follows them to the new session, so the
+// old session no longer finds it.
+func TestMergeCustomerCartOnLoginMovesCustomerCartToLoginSession(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	q := db.Queries
+	tag := fmt.Sprintf("merge-%d", time.Now().UnixNano())
+
+	owner, err := q.CreateStoreOwner(ctx, models.CreateStoreOwnerParams{
+		Name:         tag,
+		Email:        tag + "@owner.test",
+		PasswordHash: "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := q.CreateStore(ctx, models.CreateStoreParams{
+		StoreOwnerID: owner.StoreOwnerID,
+		Name:         tag,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := q.CreateCustomer(ctx, models.CreateCustomerParams{
+		StoreID:      store.StoreID,
+		Name:         tag,
+		Email:        tag + "@customer.test",
+		PasswordHash: "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customerID := sql.NullInt64{Int64: customer.CustomerID, Valid: true}
+
+	oldSession, err := q.CreateVisitorSession(ctx, models.CreateVisitorSessionParams{
+		StoreID:    store.StoreID,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loginSession, err := q.CreateVisitorSession(ctx, models.CreateVisitorSessionParams{
+		StoreID: store.StoreID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart, err := q.CreateCart(ctx, models.CreateCartParams{
+		StoreID:    store.StoreID,
+		SessionID:  oldSession.SessionID,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(db, "secret")
+	if err := s.mergeCustomerCartOnLogin(ctx, store.StoreID, customer.CustomerID, loginSession.SessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := q.GetCartBySession(ctx, models.GetCartBySessionParams{
+		StoreID:   store.StoreID,
+		SessionID: loginSession.SessionID,
+	})
+	if err != nil {
+		t.Fatalf("login session has no cart: %v", err)
+	}
+	if got.CartID != cart.CartID {
+		t.Fatalf("login session cart = %d, want the customer's cart %d", got.CartID, cart.CartID)
+	}
+
+	_, err = q.GetCartBySession(ctx, models.GetCartBySessionParams{
+		StoreID:   store.StoreID,
+		SessionID: oldSession.SessionID,
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("old session still finds a cart (err = %v)", err)
+	}
+}
@@ -0,0 +1,96 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/types"
+	"github.com/google/uuid"
+)
+
+// cart_event.event_type values, read by the funnel analytics.
+const (
+	cartEventAdd    = "add"
+	cartEventRemove = "remove"
+)
+
+// Contact is where a checkout's order is confirmed and delivered.
+// Guests must provide it; for customers it is optional.
+type Contact struct {
+	Email           string
+	ShippingAddress *types.Address
+}
+
+func (c Contact) complete() bool {
+	if !strings.Contains(c.Email, "@") || c.ShippingAddress == nil {
+		return false
+	}
+	a := c.ShippingAddress
+	return a.Street != "" && a.City != "" && a.Country != ""
+}
+
+// checkSession validates that the session belongs to the store. A session
+// attached to a customer can only be used by that customer; guest sessions
+// (no customer) are usable by whoever holds the session id.
+func checkSession(
+	ctx context.Context,
+	q *models.Queries,
+	storeID int64,
+	sessionID uuid.UUID,
+	customerID *int64,
+) (models.GetSessionRow, error) {
+	session, err := q.GetSession(ctx, models.GetSessionParams{
+		SessionID: sessionID,
+		StoreID:   storeID,
+	})
+	if err != nil {
+		return models.GetSessionRow{}, errorx.ErrInvalidSession
+	}
+
+	if session.CustomerID.Valid && (customerID == nil || *customerID != session.CustomerID.Int64) {
+		return models.GetSessionRow{}, errorx.ErrInvalidSession
+	}
+
+	return session, nil
+}
+
+// lockSessionCart validates the session the same way AddItem does and
+// returns its cart locked for update.
+func lockSessionCart(
+	ctx context.Context,
+	qtx *models.Queries,
+	storeID int64,
+	sessionID uuid.UUID,
+	customerID *int64,
+) (models.Cart, error) {
+	if _, err := checkSession(ctx, qtx, storeID, sessionID, customerID); err != nil {
+		return models.Cart{}, err
+	}
+
+	cart, err := qtx.GetCartForSession(ctx, models.GetCartForSessionParams{
+		StoreID:   storeID,
+		SessionID: sessionID,
+	})
+	if err == sql.ErrNoRows {
+		return models.Cart{}, errorx.ErrCartNotFound
+	}
+	return cart, err
+}
+
+func recordCartEvent(
+	ctx context.Context,
+	qtx *models.Queries,
+	sessionID uuid.UUID,
+	productID, variantID int64,
+	eventType string,
+) error {
+	return qtx.CreateCartEvent(ctx, models.CreateCartEventParams{
+		SessionID: sessionID,
+		ProductID: productID,
+		VariantID: sql.NullInt64{Int64: variantID, Valid: true},
+		EventType: sql.NullString{String: eventType, Valid: true},
+	})
+}
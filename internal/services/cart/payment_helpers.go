@@ -0,0 +1,151 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+)
+
+// releaseOrder cancels a pending order, returns its reserved stock, puts its
+// lines back in the cart it was placed from and marks its pending payments as
+// failed. Orders that are no longer pending are left
+// untouched. The payments it failed are returned so their authorizations can
+// be voided once the transaction has committed. The stock movements are
+// attributed to actor.
+func releaseOrder(ctx context.Context, qtx *models.Queries, orderID int64, actor inventory.Actor) ([]models.Payment, error) {
+	order, err := qtx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status.String != "pending" {
+		return nil, nil
+	}
+
+	reservations, err := qtx.ListActiveReservations(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reservations {
+		if _, err := inventory.Apply(ctx, qtx, inventory.Movement{
+			VariantID: r.VariantID,
+			Quantity:  r.Quantity,
+			Reason:    inventory.ReasonReservation,
+			Actor:     actor,
+			OrderID:   &orderID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := qtx.SetOrderReservationsStatus(ctx, models.SetOrderReservationsStatusParams{
+		OrderID: orderID,
+		Status:  "released",
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := qtx.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
+		OrderID: orderID,
+		Status:  sql.NullString{String: "cancelled", Valid: true},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := restoreCart(ctx, qtx, order); err != nil {
+		return nil, err
+	}
+
+	payments, err := qtx.ListOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []models.Payment
+	for _, p := range payments {
+		if p.Status != string(payment.StatusPending) {
+			continue
+		}
+		if err := qtx.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+			PaymentID: p.PaymentID,
+			Status:    string(payment.StatusFailed),
+		}); err != nil {
+			return nil, err
+		}
+		failed = append(failed, p)
+	}
+
+	return failed, nil
+}
+
+// restoreCart adds the lines of a released order back to its session's cart,
+// at the prices they were ordered at. Lines whose variant is gone are dropped,
+// and nothing is restored if the cart itself is gone.
+func restoreCart(ctx context.Context, qtx *models.Queries, order models.CustomerOrder) error {
+	cart, err := qtx.GetCartForSession(ctx, models.GetCartForSessionParams{
+		StoreID:   order.StoreID,
+		SessionID: order.SessionID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	items, err := qtx.ListOrderItems(ctx, order.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, it := range items {
+		_, err := qtx.GetVariantForCart(ctx, models.GetVariantForCartParams{
+			VariantID: it.VariantID,
+			StoreID:   order.StoreID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.UpsertCartItem(ctx, models.UpsertCartItemParams{
+			CartID:    cart.CartID,
+			VariantID: it.VariantID,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return qtx.TouchCart(ctx, cart.CartID)
+}
+
+// void releases an authorization that will never be captured. It is best
+// effort: failures are logged for manual follow-up.
+func void(ctx context.Context, p payment.Provider, transactionRef string) {
+	if transactionRef == "" {
+		return
+	}
+	if _, err := p.Void(ctx, transactionRef); err != nil {
+		log.Printf("payment: void %s failed: %v", transactionRef, err)
+	}
+}
+
+// refund is the compensation for a payment captured for an order that can no
+// longer be completed. It is best effort: failures are logged for manual
+// follow-up.
+func refund(ctx context.Context, p payment.Provider, transactionRef, amount string) bool {
+	if _, err := p.Refund(ctx, transactionRef, amount); err != nil {
+		log.Printf("payment: refund %s failed: %v", transactionRef, err)
+		return false
+	}
+	return true
+}
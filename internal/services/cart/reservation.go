@@ -0,0 +1,205 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+)
+
+// sweepBatchSize caps how many expired orders one sweep releases.
+const sweepBatchSize = 100
+
+// ConfirmPayment applies a payment callback sent by the provider registered
+// for method. A completed payment finalises its pending order and commits the
+// reserved stock; a failed one cancels the order, releases the stock and
+// puts the order's lines back in the cart. Callbacks are idempotent, and a
+// payment completed after its reservation expired is refunded.
+func (s *Service) ConfirmPayment(ctx context.Context, method string, payload []byte, signature string) error {
+	provider, err := s.payments.Get(method)
+	if err != nil {
+		return err
+	}
+
+	event, err := provider.VerifyWebhook(payload, signature)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		return errorx.ErrInvalidWebhookSignature
+	}
+	if err != nil {
+		return errorx.ErrInvalidRequestBody
+	}
+
+	ref := sql.NullString{String: event.TransactionRef, Valid: true}
+
+	pay, err := s.db.Queries.GetPaymentByTransactionRef(ctx, models.GetPaymentByTransactionRefParams{
+		Method:         method,
+		TransactionRef: ref,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorx.ErrPaymentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	lateCapture := false
+
+	err = s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		// The order lock serialises callbacks with checkout and the sweeper
+		order, err := qtx.GetOrderForUpdate(ctx, pay.OrderID)
+		if err != nil {
+			return err
+		}
+
+		// Re-read under the lock so duplicate callbacks are no-ops
+		pay, err = qtx.GetPaymentByTransactionRef(ctx, models.GetPaymentByTransactionRefParams{
+			Method:         method,
+			TransactionRef: ref,
+		})
+		if err != nil {
+			return err
+		}
+		// A payment failed by the sweeper may still settle at the provider;
+		// that completion is recorded so it gets refunded below
+		lateSettlement := pay.Status == string(payment.StatusFailed) && event.Status == payment.StatusCompleted
+		if pay.Status != string(payment.StatusPending) && !lateSettlement {
+			return nil
+		}
+
+		switch event.Status {
+		case payment.StatusCompleted:
+			if err := qtx.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+				PaymentID: pay.PaymentID,
+				Status:    string(payment.StatusCompleted),
+			}); err != nil {
+				return err
+			}
+
+			if order.Status.String != "pending" {
+				// Reservation already released; the money has to go back
+				lateCapture = true
+				return nil
+			}
+
+			return completeOrder(ctx, qtx, order)
+
+		case payment.StatusFailed:
+			if _, err := releaseOrder(ctx, qtx, order.OrderID, inventory.System()); err != nil {
+				return err
+			}
+			return qtx.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+				PaymentID: pay.PaymentID,
+				Status:    string(payment.StatusFailed),
+			})
+		}
+
+		// Intermediate statuses carry no decision
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if lateCapture && refund(ctx, provider, event.TransactionRef, pay.Amount) {
+		return s.db.Queries.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+			PaymentID: pay.PaymentID,
+			Status:    string(payment.StatusRefunded),
+		})
+	}
+
+	return nil
+}
+
+// completeOrder commits the reserved stock of a paid order. Its cart was
+// already emptied at checkout.
+func completeOrder(ctx context.Context, qtx *models.Queries, order models.CustomerOrder) error {
+	reservations, err := qtx.ListActiveReservations(ctx, order.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reservations {
+		if err := inventory.CommitReservation(ctx, qtx, r.VariantID, r.Quantity, order.OrderID, inventory.System()); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.SetOrderReservationsStatus(ctx, models.SetOrderReservationsStatusParams{
+		OrderID: order.OrderID,
+		Status:  "committed",
+	}); err != nil {
+		return err
+	}
+
+	return qtx.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
+		OrderID: order.OrderID,
+		Status:  sql.NullString{String: "completed", Valid: true},
+	})
+}
+
+// ReleaseExpiredReservations cancels pending orders whose reservation expired
+// before now, returns their stock and voids their authorizations.
+// It reports how many orders were released.
+func (s *Service) ReleaseExpiredReservations(ctx context.Context, now time.Time) (int, error) {
+	orderIDs, err := s.db.Queries.ListExpiredReservationOrders(ctx, models.ListExpiredReservationOrdersParams{
+		ExpiresAt: now,
+		Limit:     sweepBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, orderID := range orderIDs {
+		var failed []models.Payment
+
+		err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+			var err error
+			failed, err = releaseOrder(ctx, qtx, orderID, inventory.System())
+			return err
+		})
+		if err != nil {
+			log.Printf("reservation sweeper: release order %d: %v", orderID, err)
+			continue
+		}
+		released++
+
+		for _, p := range failed {
+			provider, err := s.payments.Get(p.Method)
+			if err != nil {
+				continue
+			}
+			void(ctx, provider, p.TransactionRef.String)
+		}
+	}
+
+	return released, nil
+}
+
+// RunReservationSweeper releases expired reservations every interval until
+// ctx is cancelled.
+func (s *Service) RunReservationSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.ReleaseExpiredReservations(ctx, now)
+			if err != nil {
+				log.Printf("reservation sweeper: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("reservation sweeper: released %d expired orders", n)
+			}
+		}
+	}
+}
@@ -3,28 +3,39 @@ package cart
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/Secure-Website-Builder/Backend/internal/database"
 	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/Secure-Website-Builder/Backend/internal/types"
 	"github.com/Secure-Website-Builder/Backend/internal/utils"
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	db *database.DB
+	db             *database.DB
+	payments       *payment.Registry
+	reservationTTL time.Duration
 }
 
-func New(db *database.DB) *Service {
-	return &Service{db: db}
+func New(db *database.DB, payments *payment.Registry, reservationTTL time.Duration) *Service {
+	return &Service{db: db, payments: payments, reservationTTL: reservationTTL}
 }
 
 func (s *Service) GetCart(
 	ctx context.Context,
 	storeID int64,
 	sessionID uuid.UUID,
+	customerID *int64,
 ) (*models.CartDTO, error) {
 
+	if _, err := checkSession(ctx, s.db.Queries, storeID, sessionID, customerID); err != nil {
+		return nil, err
+	}
+
 	cartRow, err := s.db.Queries.GetCartBySession(ctx, models.GetCartBySessionParams{
 		SessionID: sessionID,
 		StoreID:   storeID,
@@ -79,6 +90,7 @@ func (s *Service) AddItem(
 	ctx context.Context,
 	storeID int64,
 	sessionID uuid.UUID,
+	customerID *int64,
 	variantID int64,
 	qty int32,
 ) (err error) {
@@ -90,14 +102,10 @@ func (s *Service) AddItem(
 			return errorx.ErrInvalidQuantity
 		}
 
-		// Validate session
-		session, err := qtx.GetSession(ctx, models.GetSessionParams{
-			SessionID: sessionID,
-			StoreID:   storeID,
-		})
-		
-		if err != nil || !session.CustomerID.Valid{
-			return errorx.ErrInvalidSession
+		// Validate session (guest sessions have no customer)
+		session, err := checkSession(ctx, qtx, storeID, sessionID, customerID)
+		if err != nil {
+			return err
 		}
 
 		// Lock or create cart
@@ -142,6 +150,10 @@ func (s *Service) AddItem(
 			return err
 		}
 
+		if err = recordCartEvent(ctx, qtx, sessionID, variant.ProductID, variant.VariantID, cartEventAdd); err != nil {
+			return err
+		}
+
 		// Touch cart
 		if err = qtx.TouchCart(ctx, cart.CartID); err != nil {
 			return err
@@ -150,23 +162,179 @@ func (s *Service) AddItem(
 	})
 }
 
-func (s *Service) Checkout(
+// UpdateItemQuantity sets the absolute quantity of a cart line.
+func (s *Service) UpdateItemQuantity(
 	ctx context.Context,
 	storeID int64,
 	sessionID uuid.UUID,
-	paymentMethod string,
+	customerID *int64,
+	cartItemID int64,
+	qty int32,
 ) error {
+	if qty <= 0 {
+		return errorx.ErrInvalidQuantity
+	}
 
 	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		cart, err := lockSessionCart(ctx, qtx, storeID, sessionID, customerID)
+		if err != nil {
+			return err
+		}
 
-		// Validate session
-		session, err := qtx.GetSession(ctx, models.GetSessionParams{
-			SessionID: sessionID,
-			StoreID:   storeID,
+		item, err := qtx.GetCartItemForUpdate(ctx, models.GetCartItemForUpdateParams{
+			CartItemID: cartItemID,
+			CartID:     cart.CartID,
 		})
+		if err == sql.ErrNoRows {
+			return errorx.ErrCartItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if qty == item.Quantity {
+			return nil
+		}
+		if qty > item.AvailableStock {
+			return errorx.ErrInsufficientStock
+		}
+
+		if err := qtx.SetCartItemQuantity(ctx, models.SetCartItemQuantityParams{
+			CartItemID: cartItemID,
+			Quantity:   qty,
+		}); err != nil {
+			return err
+		}
+
+		eventType := cartEventAdd
+		if qty < item.Quantity {
+			eventType = cartEventRemove
+		}
+		if err := recordCartEvent(ctx, qtx, sessionID, item.ProductID, item.VariantID, eventType); err != nil {
+			return err
+		}
+
+		return qtx.TouchCart(ctx, cart.CartID)
+	})
+}
+
+// RemoveItem deletes one line from the session's cart.
+func (s *Service) RemoveItem(
+	ctx context.Context,
+	storeID int64,
+	sessionID uuid.UUID,
+	customerID *int64,
+	cartItemID int64,
+) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		cart, err := lockSessionCart(ctx, qtx, storeID, sessionID, customerID)
+		if err != nil {
+			return err
+		}
+
+		item, err := qtx.GetCartItemForUpdate(ctx, models.GetCartItemForUpdateParams{
+			CartItemID: cartItemID,
+			CartID:     cart.CartID,
+		})
+		if err == sql.ErrNoRows {
+			return errorx.ErrCartItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteCartItem(ctx, cartItemID); err != nil {
+			return err
+		}
+
+		if err := recordCartEvent(ctx, qtx, sessionID, item.ProductID, item.VariantID, cartEventRemove); err != nil {
+			return err
+		}
+
+		return qtx.TouchCart(ctx, cart.CartID)
+	})
+}
+
+// ClearCart removes every line from the session's cart. Clearing a cart
+// that does not exist is a no-op.
+func (s *Service) ClearCart(
+	ctx context.Context,
+	storeID int64,
+	sessionID uuid.UUID,
+	customerID *int64,
+) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		cart, err := lockSessionCart(ctx, qtx, storeID, sessionID, customerID)
+		if err == errorx.ErrCartNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		items, err := qtx.GetCartItems(ctx, cart.CartID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.ClearCartItems(ctx, cart.CartID); err != nil {
+			return err
+		}
+
+		for _, it := range items {
+			if err := recordCartEvent(ctx, qtx, sessionID, it.ProductID, it.VariantID, cartEventRemove); err != nil {
+				return err
+			}
+		}
+
+		return qtx.TouchCart(ctx, cart.CartID)
+	})
+}
+
+// Checkout reserves the cart's stock for s.reservationTTL and opens a
+// pending order and payment. The cart's lines move to the order, leaving the
+// cart empty until the order is released. The provider is only asked to
+// authorize the payment; the order is finalised by ConfirmPayment when the
+// provider calls back, or cancelled by the reservation sweeper once the
+// reservation expires. The provider is never called while a transaction is
+// open.
+func (s *Service) Checkout(
+	ctx context.Context,
+	storeID int64,
+	sessionID uuid.UUID,
+	customerID *int64,
+	paymentMethod string,
+	contact Contact,
+) (*models.CheckoutDTO, error) {
+
+	provider, err := s.payments.Get(paymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		order    models.CustomerOrder
+		pay      models.Payment
+		currency string
+	)
+	expiresAt := time.Now().Add(s.reservationTTL)
+
+	err = s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+
+		// Validate session
+		session, err := checkSession(ctx, qtx, storeID, sessionID, customerID)
 		if err != nil {
 			return err
 		}
+		if !session.CustomerID.Valid && !contact.complete() {
+			return errorx.ErrGuestContactRequired
+		}
+
+		store, err := qtx.GetStore(ctx, storeID)
+		if err != nil {
+			return err
+		}
+		currency = store.Currency.String
 
 		// Lock cart
 		cart, err := qtx.GetCartForSession(ctx, models.GetCartForSessionParams{
@@ -189,8 +357,11 @@ func (s *Service) Checkout(
 			return errorx.ErrCartEmpty
 		}
 
-		// Validate stock
+		// Validate availability and stock
 		for _, item := range items {
+			if !item.Purchasable {
+				return errorx.ErrItemUnavailable
+			}
 			if item.AvailableStock < item.CartQuantity {
 				return errorx.ErrOutOfStock
 			}
@@ -203,17 +374,21 @@ func (s *Service) Checkout(
 		}
 
 		// Create order with status 'pending'
-		order, err := qtx.CreateOrder(ctx, models.CreateOrderParams{
-			StoreID:    storeID,
-			CustomerID: session.CustomerID,
-			SessionID:  sessionID,
+		order, err = qtx.CreateOrder(ctx, models.CreateOrderParams{
+			StoreID:     storeID,
+			CustomerID:  session.CustomerID,
+			SessionID:   sessionID,
 			TotalAmount: total,
+			Email:       utils.PtrToNullString(&contact.Email),
+			ShippingAddress: types.NullableAddress{
+				Addr:  contact.ShippingAddress,
+				Valid: contact.ShippingAddress != nil,
+			},
 		})
 		if err != nil {
 			return err
 		}
 
-		// Create order items
 		for _, item := range items {
 
 			if err := qtx.CreateOrderItem(ctx, models.CreateOrderItemParams{
@@ -225,46 +400,89 @@ func (s *Service) Checkout(
 			}); err != nil {
 				return err
 			}
-		}
 
-		// Create payment (simulated success)
-		// TODO: Integrate with real payment gateway
-		if err := qtx.CreatePayment(ctx, models.CreatePaymentParams{
-			OrderID: order.OrderID,
-			Method:  paymentMethod,
-			Amount:  total,
-			Status:  "completed",
-			TransactionRef: sql.NullString{
-				String: uuid.NewString(), // Simulated transaction reference for third-party payment gateway
-				Valid:  true,
-			},
-		}); err != nil {
-			return err
-		}
+			// Reserve stock: taken off the variant now, given back on release
+			if _, err := inventory.Apply(ctx, qtx, inventory.Movement{
+				VariantID: item.VariantID,
+				Quantity:  -item.CartQuantity,
+				Reason:    inventory.ReasonReservation,
+				Actor:     inventory.Shopper(customerID),
+				OrderID:   &order.OrderID,
+			}); err != nil {
+				return err
+			}
 
-		// Deduct stock
-		for _, item := range items {
-			if err := qtx.DecreaseVariantStock(ctx, models.DecreaseVariantStockParams{
-				VariantID:  item.VariantID,
-				CartQuantity: item.CartQuantity,
+			if err := qtx.CreateStockReservation(ctx, models.CreateStockReservationParams{
+				OrderID:   order.OrderID,
+				VariantID: item.VariantID,
+				Quantity:  item.CartQuantity,
+				ExpiresAt: expiresAt,
 			}); err != nil {
 				return err
 			}
 		}
 
-		// Mark order as completed
-		if err := qtx.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
-			OrderID: order.OrderID,
-			Status:  sql.NullString{String: "completed", Valid: true},
-		}); err != nil {
+		// The order items are now the snapshot of the cart; emptying it keeps
+		// a second checkout from reserving the same lines again
+		if err := qtx.ClearCartItems(ctx, cart.CartID); err != nil {
 			return err
 		}
 
-		// Clear cart
-		if err := qtx.ClearCartItems(ctx, cart.CartID); err != nil {
+		pay, err = qtx.CreatePayment(ctx, models.CreatePaymentParams{
+			OrderID: order.OrderID,
+			Method:  paymentMethod,
+			Amount:  total,
+			Status:  string(payment.StatusPending),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// External call, outside any transaction
+	result, authErr := provider.Authorize(ctx, payment.ChargeRequest{
+		StoreID:  storeID,
+		OrderID:  order.OrderID,
+		Amount:   pay.Amount,
+		Currency: currency,
+	})
+	if authErr != nil {
+		// Nothing to wait for: give the stock back right away
+		if err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+			_, err := releaseOrder(ctx, qtx, order.OrderID, inventory.Shopper(customerID))
 			return err
+		}); err != nil {
+			return nil, err
 		}
+		return nil, errorx.ErrPaymentDeclined
+	}
 
-		return nil
-	})
+	pay.TransactionRef = sql.NullString{String: result.TransactionRef, Valid: result.TransactionRef != ""}
+	if err := s.db.Queries.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+		PaymentID:      pay.PaymentID,
+		Status:         pay.Status,
+		TransactionRef: pay.TransactionRef,
+	}); err != nil {
+		// Without the reference the callback cannot be matched; the sweeper
+		// releases the reservation once it expires.
+		void(ctx, provider, result.TransactionRef)
+		return nil, err
+	}
+
+	return &models.CheckoutDTO{
+		OrderID:       order.OrderID,
+		Status:        order.Status.String,
+		TotalAmount:   order.TotalAmount,
+		CreatedAt:     order.CreatedAt,
+		ReservedUntil: expiresAt,
+		Payment: models.PaymentDTO{
+			PaymentID:      pay.PaymentID,
+			Method:         pay.Method,
+			Amount:         pay.Amount,
+			Status:         pay.Status,
+			TransactionRef: utils.NullStringToPtr(pay.TransactionRef),
+			CreatedAt:      pay.CreatedAt,
+		},
+	}, nil
 }
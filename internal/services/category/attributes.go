@@ -0,0 +1,202 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+// ListStoreAttributes lists every attribute an owner can attach: the global
+// ones and the store's private ones.
+func (s *Service) ListStoreAttributes(ctx context.Context, storeID int64) ([]models.AttributeDefinitionDTO, error) {
+	rows, err := s.db.Queries.ListStoreAttributes(ctx, sql.NullInt64{Int64: storeID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.AttributeDefinitionDTO, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.AttributeDefinitionDTO{
+			AttributeID: r.AttributeID,
+			Name:        r.Name,
+			DataType:    r.DataType,
+			Private:     r.IsPrivate,
+		})
+	}
+
+	return out, nil
+}
+
+// CreateAttribute defines an attribute only this store sees. Its name may not
+// repeat a global attribute or another of the store's.
+func (s *Service) CreateAttribute(ctx context.Context, storeID int64, in models.AttributeInput) (*models.AttributeDefinitionDTO, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, errorx.ErrInvalidRequestBody
+	}
+
+	var attr models.AttributeDefinition
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		taken, err := qtx.AttributeNameTaken(ctx, models.AttributeNameTakenParams{
+			Name:    name,
+			StoreID: sql.NullInt64{Int64: storeID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errorx.ErrAttributeNameTaken
+		}
+
+		attr, err = qtx.CreateStoreAttribute(ctx, models.CreateStoreAttributeParams{
+			Name:     name,
+			DataType: in.DataType,
+			StoreID:  sql.NullInt64{Int64: storeID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AttributeDefinitionDTO{
+		AttributeID: attr.AttributeID,
+		Name:        attr.Name,
+		DataType:    attr.DataType,
+		Private:     true,
+	}, nil
+}
+
+// AttachAttribute adds a global or private attribute to one of the store's
+// private categories, or changes how it is attached. Live variants already in
+// the category must satisfy the new rule.
+func (s *Service) AttachAttribute(
+	ctx context.Context,
+	storeID, categoryID, attributeID int64,
+	in models.CategoryAttributeInput,
+) (*models.CategoryAttributeDTO, error) {
+
+	var dto models.CategoryAttributeDTO
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if _, err := getPrivate(ctx, qtx, storeID, categoryID); err != nil {
+			return err
+		}
+
+		attr, err := qtx.GetStoreAttribute(ctx, models.GetStoreAttributeParams{
+			AttributeID: attributeID,
+			StoreID:     sql.NullInt64{Int64: storeID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrAttributeNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		values, err := allowedValues(attr.DataType, in.AllowedValues)
+		if err != nil {
+			return err
+		}
+
+		conflicts, err := qtx.CountCategoryAttributeConflicts(ctx, models.CountCategoryAttributeConflictsParams{
+			AttributeID:   attributeID,
+			CategoryID:    categoryID,
+			IsRequired:    in.IsRequired,
+			AllowedValues: values,
+		})
+		if err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return errorx.ErrCategoryAttributeConflict
+		}
+
+		if err := qtx.UpsertCategoryAttribute(ctx, models.UpsertCategoryAttributeParams{
+			CategoryID:    categoryID,
+			AttributeID:   attributeID,
+			IsRequired:    in.IsRequired,
+			AllowedValues: values,
+		}); err != nil {
+			return err
+		}
+
+		dto = models.CategoryAttributeDTO{
+			AttributeID:   attr.AttributeID,
+			Name:          attr.Name,
+			DataType:      attr.DataType,
+			IsRequired:    in.IsRequired,
+			AllowedValues: values,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+// DetachAttribute removes an attribute from one of the store's private
+// categories. No live variant in the category may still have a value for it.
+func (s *Service) DetachAttribute(ctx context.Context, storeID, categoryID, attributeID int64) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if _, err := getPrivate(ctx, qtx, storeID, categoryID); err != nil {
+			return err
+		}
+
+		inUse, err := qtx.CategoryAttributeInUse(ctx, models.CategoryAttributeInUseParams{
+			CategoryID:  categoryID,
+			AttributeID: attributeID,
+		})
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return errorx.ErrAttributeInUse
+		}
+
+		n, err := qtx.DeleteCategoryAttribute(ctx, models.DeleteCategoryAttributeParams{
+			CategoryID:  categoryID,
+			AttributeID: attributeID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errorx.ErrAttributeNotFound
+		}
+		return nil
+	})
+}
+
+// allowedValues trims and checks an allowed value list against the
+// attribute's type. An empty list becomes nil: any value is allowed.
+func allowedValues(dataType string, in []string) ([]string, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+
+	out := make([]string, 0, len(in))
+	seen := make(map[string]bool, len(in))
+
+	for _, v := range in {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			return nil, errorx.ErrInvalidAllowedValues
+		}
+		if err := database.ValidateAttributeValue(dataType, v); err != nil {
+			return nil, errorx.ErrInvalidAllowedValues
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,302 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+// ListStoreOptions lists every category an owner can use: the global ones
+// and the store's private ones, with whether and where each is enabled.
+func (s *Service) ListStoreOptions(ctx context.Context, storeID int64) ([]models.StoreCategoryDTO, error) {
+	rows, err := s.db.Queries.ListStoreCategoryOptions(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.StoreCategoryDTO, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.StoreCategoryDTO{
+			CategoryID: r.CategoryID,
+			Name:       r.Name,
+			ParentID:   utils.NullInt64ToPtr(r.ParentID),
+			Private:    r.IsPrivate,
+			Enabled:    r.Enabled,
+			Position:   r.Position,
+		})
+	}
+
+	return out, nil
+}
+
+// CreatePrivate creates a category only this store sees, enabled and
+// placed last. Its parent, if any, must be enabled in the store.
+func (s *Service) CreatePrivate(ctx context.Context, storeID int64, in models.CategoryInput) (*models.StoreCategoryDTO, error) {
+	var category models.CategoryDefinition
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		name, err := checkPrivateCategory(ctx, qtx, storeID, 0, in)
+		if err != nil {
+			return err
+		}
+
+		category, err = qtx.CreateStoreCategoryDefinition(ctx, models.CreateStoreCategoryDefinitionParams{
+			Name:     name,
+			ParentID: toNullInt64(in.ParentID),
+			StoreID:  sql.NullInt64{Int64: storeID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return qtx.EnableStoreCategory(ctx, models.EnableStoreCategoryParams{
+			StoreID:    storeID,
+			CategoryID: category.CategoryID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storeCategory(ctx, storeID, category.CategoryID)
+}
+
+// UpdatePrivate renames and/or moves one of the store's private categories.
+func (s *Service) UpdatePrivate(ctx context.Context, storeID, categoryID int64, in models.CategoryInput) (*models.StoreCategoryDTO, error) {
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if _, err := getPrivate(ctx, qtx, storeID, categoryID); err != nil {
+			return err
+		}
+
+		name, err := checkPrivateCategory(ctx, qtx, storeID, categoryID, in)
+		if err != nil {
+			return err
+		}
+
+		_, err = qtx.UpdateStoreCategoryDefinition(ctx, models.UpdateStoreCategoryDefinitionParams{
+			CategoryID: categoryID,
+			StoreID:    sql.NullInt64{Int64: storeID, Valid: true},
+			Name:       name,
+			ParentID:   toNullInt64(in.ParentID),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storeCategory(ctx, storeID, categoryID)
+}
+
+// DeletePrivate removes one of the store's private categories. It must have
+// no subcategories and no products, not even soft-deleted ones.
+func (s *Service) DeletePrivate(ctx context.Context, storeID, categoryID int64) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if _, err := getPrivate(ctx, qtx, storeID, categoryID); err != nil {
+			return err
+		}
+
+		hasChildren, err := qtx.CategoryHasChildren(ctx, sql.NullInt64{Int64: categoryID, Valid: true})
+		if err != nil {
+			return err
+		}
+		hasProducts, err := qtx.CategoryHasProducts(ctx, models.CategoryHasProductsParams{
+			StoreID:    storeID,
+			CategoryID: categoryID,
+		})
+		if err != nil {
+			return err
+		}
+		if hasChildren || hasProducts {
+			return errorx.ErrCategoryInUse
+		}
+
+		return qtx.DeleteStoreCategoryDefinition(ctx, models.DeleteStoreCategoryDefinitionParams{
+			CategoryID: categoryID,
+			StoreID:    sql.NullInt64{Int64: storeID, Valid: true},
+		})
+	})
+}
+
+// Enable makes a global or private category available in the store,
+// placed last. Enabling an enabled category changes nothing.
+func (s *Service) Enable(ctx context.Context, storeID, categoryID int64) (*models.StoreCategoryDTO, error) {
+	_, err := s.db.Queries.GetStoreCategoryDefinition(ctx, models.GetStoreCategoryDefinitionParams{
+		CategoryID: categoryID,
+		StoreID:    sql.NullInt64{Int64: storeID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Queries.EnableStoreCategory(ctx, models.EnableStoreCategoryParams{
+		StoreID:    storeID,
+		CategoryID: categoryID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.storeCategory(ctx, storeID, categoryID)
+}
+
+// Disable hides a category from the store. Live products must be moved to
+// another category first.
+func (s *Service) Disable(ctx context.Context, storeID, categoryID int64) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		inUse, err := qtx.CategoryHasProducts(ctx, models.CategoryHasProductsParams{
+			StoreID:    storeID,
+			CategoryID: categoryID,
+			LiveOnly:   true,
+		})
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return errorx.ErrCategoryInUse
+		}
+
+		return qtx.DisableStoreCategory(ctx, models.DisableStoreCategoryParams{
+			StoreID:    storeID,
+			CategoryID: categoryID,
+		})
+	})
+}
+
+// Reorder sets the display order of the store's enabled categories;
+// categoryIDs must list each of them exactly once.
+func (s *Service) Reorder(ctx context.Context, storeID int64, categoryIDs []int64) error {
+	seen := make(map[int64]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		if seen[id] {
+			return errorx.ErrInvalidCategoryOrder
+		}
+		seen[id] = true
+	}
+
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		total, err := qtx.CountStoreCategories(ctx, storeID)
+		if err != nil {
+			return err
+		}
+		if total != int64(len(categoryIDs)) {
+			return errorx.ErrInvalidCategoryOrder
+		}
+
+		n, err := qtx.ReorderStoreCategories(ctx, models.ReorderStoreCategoriesParams{
+			CategoryIds: categoryIDs,
+			StoreID:     storeID,
+		})
+		if err != nil {
+			return err
+		}
+		if n != total {
+			return errorx.ErrInvalidCategoryOrder
+		}
+		return nil
+	})
+}
+
+// storeCategory reads one category as the owner's category list shows it.
+func (s *Service) storeCategory(ctx context.Context, storeID, categoryID int64) (*models.StoreCategoryDTO, error) {
+	all, err := s.ListStoreOptions(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if all[i].CategoryID == categoryID {
+			return &all[i], nil
+		}
+	}
+	return nil, errorx.ErrCategoryNotFound
+}
+
+// getPrivate loads one of the store's private categories; global categories
+// are reported as not editable.
+func getPrivate(ctx context.Context, qtx *models.Queries, storeID, categoryID int64) (models.CategoryDefinition, error) {
+	c, err := qtx.GetStoreCategoryDefinition(ctx, models.GetStoreCategoryDefinitionParams{
+		CategoryID: categoryID,
+		StoreID:    sql.NullInt64{Int64: storeID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, errorx.ErrCategoryNotFound
+	}
+	if err != nil {
+		return c, err
+	}
+	if !c.StoreID.Valid {
+		return c, errorx.ErrCategoryNotEditable
+	}
+	return c, nil
+}
+
+// checkPrivateCategory validates the name and parent of a private category
+// (categoryID is 0 for a new one) and returns the trimmed name.
+func checkPrivateCategory(
+	ctx context.Context,
+	qtx *models.Queries,
+	storeID, categoryID int64,
+	in models.CategoryInput,
+) (string, error) {
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return "", errorx.ErrInvalidRequestBody
+	}
+
+	taken, err := qtx.CategoryNameTaken(ctx, models.CategoryNameTakenParams{
+		Name:       name,
+		CategoryID: categoryID,
+		StoreID:    sql.NullInt64{Int64: storeID, Valid: true},
+	})
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", errorx.ErrCategoryNameTaken
+	}
+
+	if in.ParentID == nil {
+		return name, nil
+	}
+
+	enabled, err := qtx.IsStoreCategory(ctx, models.IsStoreCategoryParams{
+		StoreID:    storeID,
+		CategoryID: *in.ParentID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return "", errorx.ErrInvalidCategoryParent
+	}
+
+	if categoryID != 0 {
+		cycle, err := qtx.IsCategoryAncestor(ctx, models.IsCategoryAncestorParams{
+			CategoryID: *in.ParentID,
+			AncestorID: categoryID,
+		})
+		if err != nil {
+			return "", err
+		}
+		if cycle {
+			return "", errorx.ErrInvalidCategoryParent
+		}
+	}
+
+	return name, nil
+}
+
+func toNullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
 )
 
@@ -19,6 +20,60 @@ func (s *Service) ListCategoriesByStore(ctx context.Context, storeID int64) ([]m
 	return s.db.Queries.ListCategoriesByStore(ctx, storeID)
 }
 
-func (s *Service) ListAttributesByCategory(ctx context.Context, categoryID int64) ([]models.ListCategoryAttributesRow, error) {
+// ListAttributesByCategory lists the attributes of a category the store has
+// enabled; any other category is reported as not found.
+func (s *Service) ListAttributesByCategory(ctx context.Context, storeID, categoryID int64) ([]models.ListCategoryAttributesRow, error) {
+	enabled, err := s.db.Queries.IsStoreCategory(ctx, models.IsStoreCategoryParams{
+		StoreID:    storeID,
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, errorx.ErrCategoryNotFound
+	}
+
 	return s.db.Queries.ListCategoryAttributes(ctx, categoryID)
 }
+
+// CategoryTree nests the store's enabled categories under their parents, in
+// the store's display order. A category whose parent is not enabled in the
+// store becomes a root.
+func (s *Service) CategoryTree(ctx context.Context, storeID int64) ([]models.CategoryNodeDTO, error) {
+	rows, err := s.db.Queries.ListStoreCategoryTree(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[int64]bool, len(rows))
+	for _, r := range rows {
+		enabled[r.CategoryID] = true
+	}
+
+	children := make(map[int64][]models.ListStoreCategoryTreeRow)
+	roots := make([]models.ListStoreCategoryTreeRow, 0)
+	for _, r := range rows {
+		if r.ParentID.Valid && enabled[r.ParentID.Int64] && r.ParentID.Int64 != r.CategoryID {
+			children[r.ParentID.Int64] = append(children[r.ParentID.Int64], r)
+			continue
+		}
+		roots = append(roots, r)
+	}
+
+	var build func(level []models.ListStoreCategoryTreeRow) []models.CategoryNodeDTO
+	build = func(level []models.ListStoreCategoryTreeRow) []models.CategoryNodeDTO {
+		nodes := make([]models.CategoryNodeDTO, 0, len(level))
+		for _, r := range level {
+			nodes = append(nodes, models.CategoryNodeDTO{
+				CategoryID:   r.CategoryID,
+				Name:         r.Name,
+				ProductCount: r.ProductCount,
+				Children:     build(children[r.CategoryID]),
+			})
+		}
+		return nodes
+	}
+
+	return build(roots), nil
+}
@@ -0,0 +1,250 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+// Alert views accepted by ListAlerts.
+const (
+	AlertViewActive   = "active"
+	AlertViewSnoozed  = "snoozed"
+	AlertViewResolved = "resolved"
+)
+
+const maxSnooze = 30 * 24 * time.Hour
+
+// SetStoreThreshold sets the store-wide low-stock threshold used by variants
+// without one of their own, and by the product-level inventory analytics.
+func (s *Service) SetStoreThreshold(ctx context.Context, storeID int64, threshold int32) (int32, error) {
+	if threshold < 0 {
+		return 0, errorx.ErrInvalidThreshold
+	}
+
+	t, err := s.db.Queries.UpdateStoreLowStockThreshold(ctx, models.UpdateStoreLowStockThresholdParams{
+		StoreID:           storeID,
+		LowStockThreshold: threshold,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errorx.ErrStoreNotFound
+	}
+	return t, err
+}
+
+// StoreThreshold returns the store-wide low-stock threshold.
+func (s *Service) StoreThreshold(ctx context.Context, storeID int64) (int32, error) {
+	store, err := s.db.Queries.GetStore(ctx, storeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errorx.ErrStoreNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return store.LowStockThreshold, nil
+}
+
+// SetVariantThreshold overrides the store's low-stock threshold for one
+// variant; a nil threshold removes the override.
+func (s *Service) SetVariantThreshold(
+	ctx context.Context,
+	storeID, productID, variantID int64,
+	threshold *int32,
+) (*models.ProductVariant, error) {
+
+	if threshold != nil && *threshold < 0 {
+		return nil, errorx.ErrInvalidThreshold
+	}
+
+	var variant models.ProductVariant
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		v, err := qtx.GetVariantForUpdate(ctx, variantID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (v.StoreID != storeID || v.ProductID != productID)) {
+			return errorx.ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		arg := models.UpdateVariantLowStockThresholdParams{VariantID: variantID}
+		if threshold != nil {
+			arg.LowStockThreshold = sql.NullInt32{Int32: *threshold, Valid: true}
+		}
+		variant, err = qtx.UpdateVariantLowStockThreshold(ctx, arg)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// ScanAlerts resolves alerts whose variant recovered (or was retired) and
+// opens one for every live variant that has fallen to its threshold since
+// its last alert was resolved. Threshold changes are picked up the same way
+// as stock changes. It reports how many alerts were opened and resolved.
+func (s *Service) ScanAlerts(ctx context.Context) (opened, resolved int64, err error) {
+	resolved, err = s.db.Queries.ResolveStockAlerts(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	opened, err = s.db.Queries.OpenStockAlerts(ctx)
+	if err != nil {
+		return 0, resolved, err
+	}
+
+	return opened, resolved, nil
+}
+
+// RunAlertScanner runs ScanAlerts every interval until ctx is done.
+func (s *Service) RunAlertScanner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			opened, resolved, err := s.ScanAlerts(ctx)
+			if err != nil {
+				log.Printf("stock alert scanner: %v", err)
+				continue
+			}
+			if opened > 0 || resolved > 0 {
+				log.Printf("stock alert scanner: opened %d, resolved %d", opened, resolved)
+			}
+		}
+	}
+}
+
+// ListAlerts pages through the store's alerts in one view, newest first.
+func (s *Service) ListAlerts(
+	ctx context.Context,
+	storeID int64,
+	view string,
+	page, limit int,
+) ([]models.StockAlertDTO, int64, error) {
+
+	switch view {
+	case "":
+		view = AlertViewActive
+	case AlertViewActive, AlertViewSnoozed, AlertViewResolved:
+	default:
+		return nil, 0, errorx.ErrInvalidAlertView
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	rows, err := s.db.Queries.ListStockAlerts(ctx, models.ListStockAlertsParams{
+		StoreID:    storeID,
+		View:       view,
+		PageLimit:  int32(limit),
+		PageOffset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.db.Queries.CountStockAlerts(ctx, models.CountStockAlertsParams{
+		StoreID: storeID,
+		View:    view,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	alerts := make([]models.StockAlertDTO, 0, len(rows))
+	for _, r := range rows {
+		alerts = append(alerts, toAlertDTO(models.GetStockAlertRow(r)))
+	}
+
+	return alerts, total, nil
+}
+
+// AcknowledgeAlert marks an unresolved alert as seen. It stays listed until
+// stock recovers, but no new alert is raised for the same shortage.
+func (s *Service) AcknowledgeAlert(ctx context.Context, storeID, alertID int64) (*models.StockAlertDTO, error) {
+	n, err := s.db.Queries.AcknowledgeStockAlert(ctx, models.AcknowledgeStockAlertParams{
+		AlertID: alertID,
+		StoreID: storeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errorx.ErrStockAlertNotFound
+	}
+
+	return s.getAlert(ctx, storeID, alertID)
+}
+
+// SnoozeAlert hides an unresolved alert from the active view until the
+// given time, at most 30 days ahead.
+func (s *Service) SnoozeAlert(ctx context.Context, storeID, alertID int64, until time.Time) (*models.StockAlertDTO, error) {
+	now := time.Now()
+	if !until.After(now) || until.Sub(now) > maxSnooze {
+		return nil, errorx.ErrInvalidSnooze
+	}
+
+	n, err := s.db.Queries.SnoozeStockAlert(ctx, models.SnoozeStockAlertParams{
+		AlertID:      alertID,
+		StoreID:      storeID,
+		SnoozedUntil: sql.NullTime{Time: until, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errorx.ErrStockAlertNotFound
+	}
+
+	return s.getAlert(ctx, storeID, alertID)
+}
+
+func (s *Service) getAlert(ctx context.Context, storeID, alertID int64) (*models.StockAlertDTO, error) {
+	row, err := s.db.Queries.GetStockAlert(ctx, models.GetStockAlertParams{
+		AlertID: alertID,
+		StoreID: storeID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrStockAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dto := toAlertDTO(row)
+	return &dto, nil
+}
+
+func toAlertDTO(r models.GetStockAlertRow) models.StockAlertDTO {
+	return models.StockAlertDTO{
+		AlertID:        r.AlertID,
+		VariantID:      r.VariantID,
+		ProductID:      r.ProductID,
+		ProductName:    r.ProductName,
+		SKU:            r.Sku,
+		CurrentStock:   r.CurrentStock,
+		StockQuantity:  r.StockQuantity,
+		Threshold:      r.Threshold,
+		Status:         r.Status,
+		SnoozedUntil:   utils.NullTimeToPtr(r.SnoozedUntil),
+		CreatedAt:      r.CreatedAt,
+		AcknowledgedAt: utils.NullTimeToPtr(r.AcknowledgedAt),
+		ResolvedAt:     utils.NullTimeToPtr(r.ResolvedAt),
+	}
+}
@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+type Reason string
+
+const (
+	ReasonRestock     Reason = "restock"
+	ReasonSale        Reason = "sale"
+	ReasonRefund      Reason = "refund"
+	ReasonAdjustment  Reason = "adjustment"
+	ReasonReservation Reason = "reservation"
+)
+
+const (
+	ActorStoreOwner = "store_owner"
+	ActorAdmin      = "admin"
+	ActorCustomer   = "customer"
+	ActorGuest      = "guest"
+	ActorSystem     = "system"
+)
+
+// Actor is who caused a stock movement. ID is nil for guests and the system.
+type Actor struct {
+	Type string
+	ID   *int64
+}
+
+// System is the actor for background jobs and payment callbacks.
+func System() Actor {
+	return Actor{Type: ActorSystem}
+}
+
+// Shopper is the actor for a checkout: the customer, or a guest when
+// customerID is nil.
+func Shopper(customerID *int64) Actor {
+	if customerID == nil {
+		return Actor{Type: ActorGuest}
+	}
+	return Actor{Type: ActorCustomer, ID: customerID}
+}
+
+// Movement is a signed change to one variant's stock.
+type Movement struct {
+	VariantID int64
+	Quantity  int32
+	Reason    Reason
+	Actor     Actor
+	OrderID   *int64
+	Note      string
+}
+
+// Apply changes the variant's stock and its product's stock by m.Quantity
+// and records the movement. It must run inside the caller's transaction.
+// Stock never goes below zero: such a movement fails with
+// errorx.ErrInsufficientStock.
+//
+// The product's in_stock flag follows its stock, and its default variant
+// fails over to a sibling with stock when the current one runs out, so
+// listings never show a sold-out default while other variants can be sold.
+// A retired variant no longer counts towards its product, so stock coming
+// back to one (a release or a restocking refund) only changes the variant.
+func Apply(ctx context.Context, q *models.Queries, m Movement) (models.InventoryMovement, error) {
+	if m.Quantity == 0 {
+		return models.InventoryMovement{}, errorx.ErrInvalidQuantity
+	}
+
+	v, err := q.AdjustVariantStock(ctx, models.AdjustVariantStockParams{
+		QuantityChange: m.Quantity,
+		VariantID:      m.VariantID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.InventoryMovement{}, errorx.ErrInsufficientStock
+	}
+	if err != nil {
+		return models.InventoryMovement{}, err
+	}
+
+	if v.Retired {
+		return record(ctx, q, m)
+	}
+
+	if err := q.UpdateProductStock(ctx, models.UpdateProductStockParams{
+		ProductID:     v.ProductID,
+		StockQuantity: m.Quantity,
+	}); err != nil {
+		return models.InventoryMovement{}, err
+	}
+
+	if err := q.RefreshDefaultVariant(ctx, v.ProductID); err != nil {
+		return models.InventoryMovement{}, err
+	}
+
+	return record(ctx, q, m)
+}
+
+// CommitReservation records that quantity units reserved for an order were
+// sold. Stock already left the variant when it was reserved, so the
+// reservation is given back and taken again as a sale: two movements that
+// cancel out.
+func CommitReservation(ctx context.Context, q *models.Queries, variantID int64, quantity int32, orderID int64, actor Actor) error {
+	m := Movement{
+		VariantID: variantID,
+		Quantity:  quantity,
+		Reason:    ReasonReservation,
+		Actor:     actor,
+		OrderID:   &orderID,
+	}
+	if _, err := record(ctx, q, m); err != nil {
+		return err
+	}
+
+	m.Quantity = -quantity
+	m.Reason = ReasonSale
+	_, err := record(ctx, q, m)
+	return err
+}
+
+func record(ctx context.Context, q *models.Queries, m Movement) (models.InventoryMovement, error) {
+	arg := models.CreateInventoryMovementParams{
+		VariantID:      m.VariantID,
+		QuantityChange: m.Quantity,
+		Reason:         string(m.Reason),
+		ActorType:      m.Actor.Type,
+		Note:           sql.NullString{String: m.Note, Valid: m.Note != ""},
+	}
+	if m.Actor.ID != nil {
+		arg.ActorID = sql.NullInt64{Int64: *m.Actor.ID, Valid: true}
+	}
+	if m.OrderID != nil {
+		arg.OrderID = sql.NullInt64{Int64: *m.OrderID, Valid: true}
+	}
+
+	return q.CreateInventoryMovement(ctx, arg)
+}
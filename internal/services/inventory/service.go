@@ -0,0 +1,122 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+type Service struct {
+	db *database.DB
+}
+
+func New(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// Adjust applies a manual stock correction (positive or negative) to a live
+// variant of the store, e.g. after a stock count or for damaged goods.
+func (s *Service) Adjust(
+	ctx context.Context,
+	storeID, productID, variantID int64,
+	quantity int32,
+	note string,
+	actor Actor,
+) (*models.InventoryMovementDTO, error) {
+
+	var movement models.InventoryMovement
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		v, err := qtx.GetVariantForUpdate(ctx, variantID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (v.StoreID != storeID || v.ProductID != productID)) {
+			return errorx.ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		movement, err = Apply(ctx, qtx, Movement{
+			VariantID: variantID,
+			Quantity:  quantity,
+			Reason:    ReasonAdjustment,
+			Actor:     actor,
+			Note:      note,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toMovementDTO(movement), nil
+}
+
+// Reconcile finds products whose stock_quantity drifted from the sum of
+// their live variants' stock, logs them and resets them to that sum
+// (refreshing in_stock and the default variant along the way).
+// It reports how many products were corrected.
+func (s *Service) Reconcile(ctx context.Context) (int, error) {
+	mismatches, err := s.db.Queries.ListProductStockMismatches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	for _, m := range mismatches {
+		log.Printf("inventory reconciler: product %d (store %d) stock %d, variants hold %d",
+			m.ProductID, m.StoreID, m.StockQuantity, m.VariantStock)
+
+		if err := s.db.Queries.RecalculateProductStock(ctx, m.ProductID); err != nil {
+			return fixed, err
+		}
+		if err := s.db.Queries.RefreshDefaultVariant(ctx, m.ProductID); err != nil {
+			return fixed, err
+		}
+		fixed++
+	}
+
+	return fixed, nil
+}
+
+// RunReconciler runs Reconcile every interval until ctx is done.
+func (s *Service) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Reconcile(ctx)
+			if err != nil {
+				log.Printf("inventory reconciler: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("inventory reconciler: corrected %d products", n)
+			}
+		}
+	}
+}
+
+func toMovementDTO(m models.InventoryMovement) *models.InventoryMovementDTO {
+	return &models.InventoryMovementDTO{
+		MovementID:     m.MovementID,
+		VariantID:      m.VariantID,
+		QuantityChange: m.QuantityChange,
+		Reason:         m.Reason,
+		ActorType:      m.ActorType,
+		ActorID:        utils.NullInt64ToPtr(m.ActorID),
+		OrderID:        utils.NullInt64ToPtr(m.OrderID),
+		Note:           utils.NullStringToPtr(m.Note),
+		CreatedAt:      m.CreatedAt,
+	}
+}
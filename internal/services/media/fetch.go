@@ -0,0 +1,85 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var errNonPublicAddress = errors.New("address is not public")
+
+// fetchClient only connects to public addresses, so image URLs supplied by
+// store owners cannot be used to reach services inside our network. The
+// check runs on every connection, redirects included.
+var fetchClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: publicOnly,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() {
+		return fmt.Errorf("%s: %w", host, errNonPublicAddress)
+	}
+	return nil
+}
+
+// CheckImageURL reports whether raw is an absolute http(s) URL.
+func CheckImageURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("image url must be an absolute http or https url")
+	}
+	return nil
+}
+
+// FetchImage downloads an image from a public http(s) URL. The caller must
+// close the body; it is neither size-limited nor type-checked here, that is
+// left to UploadImage.
+func (s *Service) FetchImage(ctx context.Context, raw string) (io.ReadCloser, error) {
+	if err := CheckImageURL(raw); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching image: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching image: unexpected status %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,248 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+func buildOrderDetail(
+	ctx context.Context,
+	q *models.Queries,
+	o models.CustomerOrder,
+) (*models.OrderDetailDTO, error) {
+
+	itemRows, err := q.ListOrderItems(ctx, o.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.OrderItemDTO, 0, len(itemRows))
+	for _, it := range itemRows {
+		items = append(items, models.OrderItemDTO{
+			OrderItemID: it.OrderItemID,
+			VariantID:   it.VariantID,
+			ProductID:   it.ProductID,
+			Product:     it.ProductName,
+			SKU:         it.Sku,
+			ImageURL:    utils.NullStringToPtr(it.PrimaryImageUrl),
+			Quantity:    it.Quantity,
+			UnitPrice:   it.UnitPrice,
+			Subtotal:    it.Subtotal,
+			Refunded:    it.RefundedQuantity,
+		})
+	}
+
+	paymentRows, err := q.ListOrderPayments(ctx, o.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]models.PaymentDTO, 0, len(paymentRows))
+	for _, p := range paymentRows {
+		payments = append(payments, models.PaymentDTO{
+			PaymentID:      p.PaymentID,
+			Method:         p.Method,
+			Amount:         p.Amount,
+			Status:         p.Status,
+			TransactionRef: utils.NullStringToPtr(p.TransactionRef),
+			CreatedAt:      p.CreatedAt,
+		})
+	}
+
+	shipments, err := buildShipments(ctx, q, o.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderDetailDTO{
+		OrderID:         o.OrderID,
+		StoreID:         o.StoreID,
+		CustomerID:      utils.NullInt64ToPtr(o.CustomerID),
+		TotalAmount:     o.TotalAmount,
+		Status:          o.Status.String,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       utils.NullTimeToPtr(o.UpdatedAt),
+		Email:           utils.NullStringToPtr(o.Email),
+		ShippingAddress: o.ShippingAddress.Addr,
+		Items:           items,
+		Payments:        payments,
+		Shipments:       shipments,
+	}, nil
+}
+
+// buildShipments returns an order's shipments together with the order items
+// each of them carries.
+func buildShipments(ctx context.Context, q *models.Queries, orderID int64) ([]models.ShipmentDTO, error) {
+	shipmentRows, err := q.ListOrderShipments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemRows, err := q.ListOrderShipmentItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[int64][]models.ShipmentItemDTO, len(shipmentRows))
+	for _, it := range itemRows {
+		items[it.ShipmentID] = append(items[it.ShipmentID], models.ShipmentItemDTO{
+			OrderItemID: it.OrderItemID,
+			Quantity:    it.Quantity,
+		})
+	}
+
+	shipments := make([]models.ShipmentDTO, 0, len(shipmentRows))
+	for _, sh := range shipmentRows {
+		shipmentItems := items[sh.ShipmentID]
+		if shipmentItems == nil {
+			shipmentItems = []models.ShipmentItemDTO{}
+		}
+
+		shipments = append(shipments, models.ShipmentDTO{
+			ShipmentID:     sh.ShipmentID,
+			TrackingNumber: utils.NullStringToPtr(sh.TrackingNumber),
+			Carrier:        utils.NullStringToPtr(sh.Carrier),
+			ShippedAt:      utils.NullTimeToPtr(sh.ShippedAt),
+			DeliveredAt:    utils.NullTimeToPtr(sh.DeliveredAt),
+			Status:         sh.Status.String,
+			Items:          shipmentItems,
+		})
+	}
+
+	return shipments, nil
+}
+
+// cancelPending gives back the stock reserved at checkout for a pending order
+// the store owner cancels and marks its pending payments as failed. The
+// payments it failed are returned so their authorizations can be voided once
+// the transaction has committed.
+func cancelPending(ctx context.Context, q *models.Queries, orderID int64, actor inventory.Actor) ([]models.Payment, error) {
+	reservations, err := q.ListActiveReservations(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reservations {
+		if _, err := inventory.Apply(ctx, q, inventory.Movement{
+			VariantID: r.VariantID,
+			Quantity:  r.Quantity,
+			Reason:    inventory.ReasonReservation,
+			Actor:     actor,
+			OrderID:   &orderID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := q.SetOrderReservationsStatus(ctx, models.SetOrderReservationsStatusParams{
+		OrderID: orderID,
+		Status:  "released",
+	}); err != nil {
+		return nil, err
+	}
+
+	payments, err := q.ListOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []models.Payment
+	for _, p := range payments {
+		if p.Status != string(payment.StatusPending) {
+			continue
+		}
+		if err := q.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+			PaymentID: p.PaymentID,
+			Status:    string(payment.StatusFailed),
+		}); err != nil {
+			return nil, err
+		}
+		failed = append(failed, p)
+	}
+
+	return failed, nil
+}
+
+// void releases the authorization of a payment that will never be captured.
+// It is best effort: failures are logged for manual follow-up.
+func (s *Service) void(ctx context.Context, p models.Payment) {
+	if !p.TransactionRef.Valid {
+		return
+	}
+	provider, err := s.payments.Get(p.Method)
+	if err == nil {
+		_, err = provider.Void(ctx, p.TransactionRef.String)
+	}
+	if err != nil {
+		log.Printf("payment: void %s failed: %v", p.TransactionRef.String, err)
+	}
+}
+
+// capturedPayment returns the most recent completed payment that the
+// provider can be asked to refund.
+func capturedPayment(payments []models.Payment) (models.Payment, bool) {
+	for i := len(payments) - 1; i >= 0; i-- {
+		p := payments[i]
+		if p.Status == string(payment.StatusCompleted) && p.TransactionRef.Valid {
+			return p, true
+		}
+	}
+	return models.Payment{}, false
+}
+
+// sumAmounts adds DECIMAL(10,2) values exactly and formats the result the
+// same way Postgres returns them.
+func sumAmounts(amounts []string) (string, error) {
+	total := new(big.Rat)
+	for _, a := range amounts {
+		r, ok := new(big.Rat).SetString(a)
+		if !ok {
+			return "", fmt.Errorf("invalid amount %q", a)
+		}
+		total.Add(total, r)
+	}
+	return total.FloatString(2), nil
+}
+
+// dayRange turns inclusive calendar days in the store's timezone into the
+// half-open [from, to) interval of created_at: to moves to the next midnight.
+// A store without a usable timezone is read as UTC.
+func (s *Service) dayRange(ctx context.Context, storeID int64, from, to *string) (sql.NullTime, sql.NullTime, error) {
+	var start, end sql.NullTime
+	if from == nil && to == nil {
+		return start, end, nil
+	}
+
+	store, err := s.db.Queries.GetStore(ctx, storeID)
+	if err != nil {
+		return start, end, err
+	}
+
+	loc := utils.StoreLocation(store.Timezone)
+
+	if from != nil {
+		t, err := utils.ParseDay(*from, loc)
+		if err != nil {
+			return start, end, err
+		}
+		start = sql.NullTime{Time: t, Valid: true}
+	}
+
+	if to != nil {
+		t, err := utils.ParseDay(*to, loc)
+		if err != nil {
+			return start, end, err
+		}
+		end = sql.NullTime{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,218 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+)
+
+// RefundLine refunds Quantity units of one order item.
+type RefundLine struct {
+	OrderItemID int64
+	Quantity    int32
+}
+
+// RefundRequest describes a refund. With no Lines, everything not refunded
+// yet is refunded. Restock puts the refunded units back on their variants.
+type RefundRequest struct {
+	Lines   []RefundLine
+	Restock bool
+}
+
+// Refund returns money for a whole order or for some of its lines through
+// the provider that captured the order's payment. The refund is recorded as
+// its own payment row with status 'refunded'; once every line is fully
+// refunded the order itself moves to 'refunded'. After a partial refund the
+// order may move to 'shipped' or 'delivered' if its shipments now cover every
+// unit left.
+//
+// Like checkout, the provider is never called while a transaction is open:
+// the refunded quantities and a pending refund payment are written first and
+// rolled back if the provider rejects the refund.
+func (s *Service) Refund(ctx context.Context, storeID, orderID int64, req RefundRequest, actor inventory.Actor) (*models.OrderDetailDTO, error) {
+	for _, l := range req.Lines {
+		if l.Quantity <= 0 {
+			return nil, errorx.ErrInvalidRefund
+		}
+	}
+
+	o, err := s.db.Queries.GetStoreOrder(ctx, models.GetStoreOrderParams{
+		StoreID: storeID,
+		OrderID: orderID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.db.Queries.ListOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	captured, ok := capturedPayment(payments)
+	if !ok || !CanRefund(o.Status.String) {
+		return nil, errorx.ErrOrderNotRefundable
+	}
+
+	provider, err := s.payments.Get(captured.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	type appliedLine struct {
+		RefundLine
+		variantID int64
+	}
+
+	var (
+		applied   []appliedLine
+		refundPay models.Payment
+	)
+
+	err = s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		o, err := qtx.GetStoreOrderForUpdate(ctx, models.GetStoreOrderForUpdateParams{
+			StoreID: storeID,
+			OrderID: orderID,
+		})
+		if err != nil {
+			return err
+		}
+		if !CanRefund(o.Status.String) {
+			return errorx.ErrOrderNotRefundable
+		}
+
+		lines := req.Lines
+		if len(lines) == 0 {
+			items, err := qtx.ListOrderItems(ctx, orderID)
+			if err != nil {
+				return err
+			}
+			for _, it := range items {
+				if left := it.Quantity - it.RefundedQuantity; left > 0 {
+					lines = append(lines, RefundLine{OrderItemID: it.OrderItemID, Quantity: left})
+				}
+			}
+			if len(lines) == 0 {
+				return errorx.ErrOrderNotRefundable
+			}
+		}
+
+		amounts := make([]string, 0, len(lines))
+		for _, l := range lines {
+			row, err := qtx.RefundOrderItem(ctx, models.RefundOrderItemParams{
+				Quantity:    l.Quantity,
+				OrderItemID: l.OrderItemID,
+				OrderID:     orderID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return errorx.ErrInvalidRefund
+			}
+			if err != nil {
+				return err
+			}
+			applied = append(applied, appliedLine{RefundLine: l, variantID: row.VariantID})
+			amounts = append(amounts, row.Amount)
+		}
+
+		total, err := sumAmounts(amounts)
+		if err != nil {
+			return err
+		}
+
+		refundPay, err = qtx.CreatePayment(ctx, models.CreatePaymentParams{
+			OrderID: orderID,
+			Method:  captured.Method,
+			Amount:  total,
+			Status:  string(payment.StatusPending),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// External call, outside any transaction
+	result, refundErr := provider.Refund(ctx, captured.TransactionRef.String, refundPay.Amount)
+
+	err = s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if refundErr != nil {
+			// Undo the bookkeeping so the lines can be refunded again
+			for _, l := range applied {
+				if err := qtx.RevertOrderItemRefund(ctx, models.RevertOrderItemRefundParams{
+					OrderItemID:      l.OrderItemID,
+					RefundedQuantity: l.Quantity,
+				}); err != nil {
+					return err
+				}
+			}
+			return qtx.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+				PaymentID: refundPay.PaymentID,
+				Status:    string(payment.StatusFailed),
+			})
+		}
+
+		if err := qtx.UpdatePaymentStatus(ctx, models.UpdatePaymentStatusParams{
+			PaymentID:      refundPay.PaymentID,
+			Status:         string(payment.StatusRefunded),
+			TransactionRef: sql.NullString{String: result.TransactionRef, Valid: result.TransactionRef != ""},
+		}); err != nil {
+			return err
+		}
+
+		if req.Restock {
+			for _, l := range applied {
+				if _, err := inventory.Apply(ctx, qtx, inventory.Movement{
+					VariantID: l.variantID,
+					Quantity:  l.Quantity,
+					Reason:    inventory.ReasonRefund,
+					Actor:     actor,
+					OrderID:   &orderID,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		left, err := qtx.CountUnrefundedOrderItems(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if left > 0 {
+			// The shipments may now cover everything still owed
+			o, err := qtx.GetStoreOrderForUpdate(ctx, models.GetStoreOrderForUpdateParams{
+				StoreID: storeID,
+				OrderID: orderID,
+			})
+			if err != nil {
+				return err
+			}
+			return syncShippingStatus(ctx, qtx, o)
+		}
+
+		return qtx.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
+			OrderID: orderID,
+			Status:  sql.NullString{String: StatusRefunded, Valid: true},
+		})
+	})
+	if err != nil {
+		if refundErr == nil {
+			log.Printf("refund: order %d refunded by provider (payment %d) but not recorded: %v", orderID, refundPay.PaymentID, err)
+		}
+		return nil, err
+	}
+
+	if refundErr != nil {
+		log.Printf("refund: order %d: %v", orderID, refundErr)
+		return nil, errorx.ErrRefundFailed
+	}
+
+	return s.GetOrder(ctx, storeID, orderID)
+}
@@ -0,0 +1,260 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/payment"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+type Service struct {
+	db       *database.DB
+	payments *payment.Registry
+}
+
+func New(db *database.DB, payments *payment.Registry) *Service {
+	return &Service{db: db, payments: payments}
+}
+
+// ListOrderFilters input shape
+type ListOrderFilters struct {
+	Page       int
+	Limit      int
+	Status     *string
+	CustomerID *int64
+	From       *string // inclusive calendar days, YYYY-MM-DD, in the
+	To         *string // store's timezone
+	MinAmount  *float64
+	MaxAmount  *float64
+}
+
+// ListOrders returns one page of the store's orders matching f, newest first,
+// together with the total number of matching orders.
+func (s *Service) ListOrders(ctx context.Context, storeID int64, f ListOrderFilters) ([]models.OrderSummaryDTO, int64, error) {
+	// sane defaults
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 || f.Limit > 200 {
+		f.Limit = 20
+	}
+
+	if f.Status != nil && !IsValidStatus(*f.Status) {
+		return nil, 0, errorx.ErrInvalidOrderStatus
+	}
+
+	status := sql.NullString{}
+	if f.Status != nil {
+		status = sql.NullString{String: *f.Status, Valid: true}
+	}
+	customerID := sql.NullInt64{}
+	if f.CustomerID != nil {
+		customerID = sql.NullInt64{Int64: *f.CustomerID, Valid: true}
+	}
+	from, to, err := s.dayRange(ctx, storeID, f.From, f.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	minAmount := sql.NullString{}
+	if f.MinAmount != nil {
+		minAmount = sql.NullString{String: fmt.Sprintf("%f", *f.MinAmount), Valid: true}
+	}
+	maxAmount := sql.NullString{}
+	if f.MaxAmount != nil {
+		maxAmount = sql.NullString{String: fmt.Sprintf("%f", *f.MaxAmount), Valid: true}
+	}
+
+	rows, err := s.db.Queries.ListStoreOrders(ctx, models.ListStoreOrdersParams{
+		StoreID:     storeID,
+		Status:      status,
+		CustomerID:  customerID,
+		CreatedFrom: from,
+		CreatedTo:   to,
+		MinAmount:   minAmount,
+		MaxAmount:   maxAmount,
+		PageLimit:   int32(f.Limit),
+		PageOffset:  int32((f.Page - 1) * f.Limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.db.Queries.CountStoreOrders(ctx, models.CountStoreOrdersParams{
+		StoreID:     storeID,
+		Status:      status,
+		CustomerID:  customerID,
+		CreatedFrom: from,
+		CreatedTo:   to,
+		MinAmount:   minAmount,
+		MaxAmount:   maxAmount,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orders := make([]models.OrderSummaryDTO, 0, len(rows))
+	for _, o := range rows {
+		orders = append(orders, models.OrderSummaryDTO{
+			OrderID:       o.OrderID,
+			CustomerID:    utils.NullInt64ToPtr(o.CustomerID),
+			CustomerName:  utils.NullStringToPtr(o.CustomerName),
+			CustomerEmail: utils.NullStringToPtr(o.CustomerEmail),
+			TotalAmount:   o.TotalAmount,
+			Status:        o.Status.String,
+			CreatedAt:     o.CreatedAt,
+			UpdatedAt:     utils.NullTimeToPtr(o.UpdatedAt),
+		})
+	}
+
+	return orders, total, nil
+}
+
+// GetOrder returns a store order with its items, payments and shipments.
+func (s *Service) GetOrder(ctx context.Context, storeID, orderID int64) (*models.OrderDetailDTO, error) {
+	o, err := s.db.Queries.GetStoreOrder(ctx, models.GetStoreOrderParams{
+		StoreID: storeID,
+		OrderID: orderID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildOrderDetail(ctx, s.db.Queries, o)
+}
+
+// UpdateStatus moves an order to status, rejecting moves the state machine
+// does not allow. The order row is locked so concurrent updates serialize.
+// Cancelling a pending order gives its reserved stock back, attributed to
+// actor, and fails and voids its pending payments.
+func (s *Service) UpdateStatus(ctx context.Context, storeID, orderID int64, status string, actor inventory.Actor) (*models.OrderDetailDTO, error) {
+	if !IsValidStatus(status) {
+		return nil, errorx.ErrInvalidOrderStatus
+	}
+
+	var (
+		updated models.CustomerOrder
+		failed  []models.Payment
+	)
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		o, err := qtx.GetStoreOrderForUpdate(ctx, models.GetStoreOrderForUpdateParams{
+			StoreID: storeID,
+			OrderID: orderID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !CanTransition(o.Status.String, status) {
+			return errorx.ErrInvalidStatusTransition
+		}
+
+		if status == StatusCancelled {
+			if failed, err = cancelPending(ctx, qtx, orderID, actor); err != nil {
+				return err
+			}
+		}
+
+		if err := qtx.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
+			OrderID: orderID,
+			Status:  sql.NullString{String: status, Valid: true},
+		}); err != nil {
+			return err
+		}
+
+		o.Status = sql.NullString{String: status, Valid: true}
+		updated = o
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// External calls, outside any transaction
+	for _, p := range failed {
+		s.void(ctx, p)
+	}
+
+	return buildOrderDetail(ctx, s.db.Queries, updated)
+}
+
+// ListCustomerOrders returns one page of a customer's own orders in a store.
+func (s *Service) ListCustomerOrders(
+	ctx context.Context,
+	storeID, customerID int64,
+	page, limit int,
+) ([]models.OrderSummaryDTO, int64, error) {
+
+	// sane defaults
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	customer := sql.NullInt64{Int64: customerID, Valid: true}
+
+	rows, err := s.db.Queries.ListCustomerOrders(ctx, models.ListCustomerOrdersParams{
+		StoreID:    storeID,
+		CustomerID: customer,
+		Limit:      int32(limit),
+		Offset:     int32((page - 1) * limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.db.Queries.CountCustomerOrders(ctx, models.CountCustomerOrdersParams{
+		StoreID:    storeID,
+		CustomerID: customer,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orders := make([]models.OrderSummaryDTO, 0, len(rows))
+	for _, o := range rows {
+		orders = append(orders, models.OrderSummaryDTO{
+			OrderID:     o.OrderID,
+			CustomerID:  &customerID,
+			TotalAmount: o.TotalAmount,
+			Status:      o.Status.String,
+			CreatedAt:   o.CreatedAt,
+			UpdatedAt:   utils.NullTimeToPtr(o.UpdatedAt),
+		})
+	}
+
+	return orders, total, nil
+}
+
+// GetCustomerOrder returns an order only if it belongs to the customer and
+// store; any other order is reported as not found.
+func (s *Service) GetCustomerOrder(ctx context.Context, storeID, customerID, orderID int64) (*models.OrderDetailDTO, error) {
+	o, err := s.db.Queries.GetCustomerOrder(ctx, models.GetCustomerOrderParams{
+		StoreID:    storeID,
+		CustomerID: sql.NullInt64{Int64: customerID, Valid: true},
+		OrderID:    orderID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildOrderDetail(ctx, s.db.Queries, o)
+}
@@ -0,0 +1,216 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+const (
+	ShipmentPending   = "pending"
+	ShipmentShipped   = "shipped"
+	ShipmentDelivered = "delivered"
+)
+
+// ShipmentLine puts Quantity units of one order item into a shipment.
+type ShipmentLine struct {
+	OrderItemID int64
+	Quantity    int32
+}
+
+// NewShipment describes a shipment to create. With no Lines, everything not
+// shipped (or refunded) yet goes into it.
+type NewShipment struct {
+	Carrier        *string
+	TrackingNumber *string
+	Lines          []ShipmentLine
+}
+
+// CreateShipment opens a pending shipment for a paid order. Several
+// shipments can split one order as long as no item is shipped more times
+// than it was ordered (minus what was refunded).
+func (s *Service) CreateShipment(ctx context.Context, storeID, orderID int64, req NewShipment) (*models.OrderDetailDTO, error) {
+	for _, l := range req.Lines {
+		if l.Quantity <= 0 {
+			return nil, errorx.ErrInvalidShipment
+		}
+	}
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		o, err := qtx.GetStoreOrderForUpdate(ctx, models.GetStoreOrderForUpdateParams{
+			StoreID: storeID,
+			OrderID: orderID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if o.Status.String != StatusCompleted {
+			return errorx.ErrOrderNotShippable
+		}
+
+		unshippedRows, err := qtx.ListUnshippedOrderItems(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		unshipped := make(map[int64]int32, len(unshippedRows))
+		for _, r := range unshippedRows {
+			unshipped[r.OrderItemID] = r.Unshipped
+		}
+
+		lines := req.Lines
+		if len(lines) == 0 {
+			for _, r := range unshippedRows {
+				if r.Unshipped > 0 {
+					lines = append(lines, ShipmentLine{OrderItemID: r.OrderItemID, Quantity: r.Unshipped})
+				}
+			}
+			if len(lines) == 0 {
+				return errorx.ErrOrderNotShippable
+			}
+		}
+
+		for _, l := range lines {
+			left, ok := unshipped[l.OrderItemID]
+			if !ok || l.Quantity > left {
+				return errorx.ErrInvalidShipment
+			}
+			unshipped[l.OrderItemID] = left - l.Quantity
+		}
+
+		shipment, err := qtx.CreateShipment(ctx, models.CreateShipmentParams{
+			OrderID:        orderID,
+			Carrier:        utils.PtrToNullString(req.Carrier),
+			TrackingNumber: utils.PtrToNullString(req.TrackingNumber),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, l := range lines {
+			if err := qtx.AddShipmentItem(ctx, models.AddShipmentItemParams{
+				ShipmentID:  shipment.ShipmentID,
+				OrderItemID: l.OrderItemID,
+				Quantity:    l.Quantity,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, storeID, orderID)
+}
+
+// UpdateShipmentStatus moves a shipment from pending to shipped, or from
+// shipped to delivered, stamping shipped_at / delivered_at. The order must
+// be completed or shipped, so a cancelled or refunded order's shipments stay
+// as they are. Once every item of the order is shipped the order becomes
+// 'shipped', and once every item is delivered it becomes 'delivered'.
+func (s *Service) UpdateShipmentStatus(ctx context.Context, storeID, orderID, shipmentID int64, status string) (*models.OrderDetailDTO, error) {
+	if status != ShipmentShipped && status != ShipmentDelivered {
+		return nil, errorx.ErrInvalidShipmentStatus
+	}
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		o, err := qtx.GetStoreOrderForUpdate(ctx, models.GetStoreOrderForUpdateParams{
+			StoreID: storeID,
+			OrderID: orderID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if o.Status.String != StatusCompleted && o.Status.String != StatusShipped {
+			return errorx.ErrOrderNotShippable
+		}
+
+		sh, err := qtx.GetShipmentForUpdate(ctx, models.GetShipmentForUpdateParams{
+			ShipmentID: shipmentID,
+			OrderID:    orderID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrShipmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case status == ShipmentShipped && sh.Status.String == ShipmentPending:
+			err = qtx.MarkShipmentShipped(ctx, shipmentID)
+		case status == ShipmentDelivered && sh.Status.String == ShipmentShipped:
+			err = qtx.MarkShipmentDelivered(ctx, shipmentID)
+		default:
+			return errorx.ErrInvalidShipmentTransition
+		}
+		if err != nil {
+			return err
+		}
+
+		return syncShippingStatus(ctx, qtx, o)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, storeID, orderID)
+}
+
+// ListCustomerShipments returns the shipments of one of the customer's own
+// orders, for tracking.
+func (s *Service) ListCustomerShipments(ctx context.Context, storeID, customerID, orderID int64) ([]models.ShipmentDTO, error) {
+	o, err := s.db.Queries.GetCustomerOrder(ctx, models.GetCustomerOrderParams{
+		StoreID:    storeID,
+		CustomerID: sql.NullInt64{Int64: customerID, Valid: true},
+		OrderID:    orderID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildShipments(ctx, s.db.Queries, o.OrderID)
+}
+
+// syncShippingStatus advances the order once its shipments cover every item
+// still owed to the customer.
+func syncShippingStatus(ctx context.Context, q *models.Queries, o models.CustomerOrder) error {
+	progress, err := q.GetOrderShipmentProgress(ctx, o.OrderID)
+	if err != nil {
+		return err
+	}
+	if progress.Ordered == 0 {
+		return nil
+	}
+
+	status := o.Status.String
+	if status == StatusCompleted && progress.Shipped >= progress.Ordered {
+		status = StatusShipped
+	}
+	if status == StatusShipped && progress.Delivered >= progress.Ordered {
+		status = StatusDelivered
+	}
+	if status == o.Status.String {
+		return nil
+	}
+
+	return q.UpdateOrderStatus(ctx, models.UpdateOrderStatusParams{
+		OrderID: o.OrderID,
+		Status:  sql.NullString{String: status, Valid: true},
+	})
+}
@@ -0,0 +1,54 @@
+package order
+
+// Order statuses as stored in customer_order.status.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded"
+)
+
+// transitions lists, for every status, the statuses an owner may move an
+// order to. cancelled and refunded are terminal. Only an unpaid order can be
+// changed by hand, and only cancelled: completed follows the payment
+// callback, shipped and delivered follow the order's shipments, and a paid
+// order is given up through Refund.
+var transitions = map[string][]string{
+	StatusPending:   {StatusCancelled},
+	StatusCompleted: {},
+	StatusShipped:   {},
+	StatusDelivered: {},
+	StatusCancelled: {},
+	StatusRefunded:  {},
+}
+
+// refundable lists the statuses of orders whose payment has been captured and
+// can still be refunded.
+var refundable = map[string]bool{
+	StatusCompleted: true,
+	StatusShipped:   true,
+	StatusDelivered: true,
+}
+
+// IsValidStatus reports whether status is a known order status.
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition reports whether an order in status from may move to status to.
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRefund reports whether an order in status may be refunded.
+func CanRefund(status string) bool {
+	return refundable[status]
+}
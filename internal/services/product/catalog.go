@@ -0,0 +1,286 @@
+package product
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+// Catalogue file formats, for import and export.
+const (
+	CatalogCSV    = "csv"
+	CatalogNDJSON = "ndjson"
+)
+
+// catalogColumns are the fixed CSV columns, in the order they are exported.
+// Attributes follow them as one "attribute:<name>" column each; an empty
+// cell means the variant has no value for that attribute.
+var catalogColumns = []string{
+	"category", "name", "slug", "description", "brand",
+	"sku", "price", "stock", "image_url",
+}
+
+var requiredCatalogColumns = []string{"category", "name", "sku", "price"}
+
+const attributeColumnPrefix = "attribute:"
+
+// maxCatalogLine bounds one NDJSON line.
+const maxCatalogLine = 1 << 20
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// catalogLine is one data row of an import file and the problems found
+// reading it.
+type catalogLine struct {
+	row  models.CatalogRow
+	errs []rowError
+}
+
+// rowError is a problem with one row; field is empty when it concerns the
+// row as a whole.
+type rowError struct {
+	field   string
+	message string
+}
+
+// parseCatalog reads every data row of an import file. Problems confined to
+// a row are kept with it; a file that cannot be read as a whole yields
+// errorx.ErrInvalidImportFile.
+func parseCatalog(format string, data []byte) ([]catalogLine, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	var (
+		lines []catalogLine
+		err   error
+	)
+	switch format {
+	case CatalogCSV:
+		lines, err = parseCatalogCSV(data)
+	case CatalogNDJSON:
+		lines, err = parseCatalogNDJSON(data)
+	default:
+		return nil, errorx.ErrInvalidCatalogFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errorx.ErrInvalidImportFile
+	}
+
+	return lines, nil
+}
+
+func parseCatalogCSV(data []byte) ([]catalogLine, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errorx.ErrInvalidImportFile, err)
+	}
+
+	known := make(map[string]bool, len(catalogColumns))
+	for _, c := range catalogColumns {
+		known[c] = true
+	}
+
+	index := make(map[string]int, len(header))
+	for i, c := range header {
+		c = strings.TrimSpace(c)
+		if _, dup := index[c]; dup {
+			return nil, fmt.Errorf("%w: duplicate column %q", errorx.ErrInvalidImportFile, c)
+		}
+		if !known[c] && (!strings.HasPrefix(c, attributeColumnPrefix) || c == attributeColumnPrefix) {
+			return nil, fmt.Errorf("%w: unknown column %q", errorx.ErrInvalidImportFile, c)
+		}
+		index[c] = i
+	}
+	for _, c := range requiredCatalogColumns {
+		if _, ok := index[c]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", errorx.ErrInvalidImportFile, c)
+		}
+	}
+
+	var lines []catalogLine
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, csv.ErrFieldCount) {
+			lines = append(lines, catalogLine{errs: []rowError{{
+				message: fmt.Sprintf("expected %d fields, got %d", len(header), len(rec)),
+			}}})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errorx.ErrInvalidImportFile, err)
+		}
+
+		lines = append(lines, catalogLineFromCSV(header, index, rec))
+	}
+
+	return lines, nil
+}
+
+func catalogLineFromCSV(header []string, index map[string]int, rec []string) catalogLine {
+	get := func(col string) string {
+		if i, ok := index[col]; ok {
+			return rec[i]
+		}
+		return ""
+	}
+
+	var line catalogLine
+	line.row = models.CatalogRow{
+		Category:    get("category"),
+		Name:        get("name"),
+		Slug:        get("slug"),
+		Description: get("description"),
+		Brand:       get("brand"),
+		SKU:         get("sku"),
+		ImageURL:    get("image_url"),
+	}
+
+	if price, err := strconv.ParseFloat(strings.TrimSpace(get("price")), 64); err != nil {
+		line.errs = append(line.errs, rowError{"price", "must be a number"})
+	} else {
+		line.row.Price = price
+	}
+
+	if stock := strings.TrimSpace(get("stock")); stock != "" {
+		n, err := strconv.ParseInt(stock, 10, 32)
+		if err != nil {
+			line.errs = append(line.errs, rowError{"stock", "must be a whole number"})
+		}
+		line.row.Stock = int32(n)
+	}
+
+	for i, c := range header {
+		name, ok := strings.CutPrefix(strings.TrimSpace(c), attributeColumnPrefix)
+		if !ok || rec[i] == "" {
+			continue
+		}
+		if line.row.Attributes == nil {
+			line.row.Attributes = make(map[string]string)
+		}
+		line.row.Attributes[name] = rec[i]
+	}
+
+	return line
+}
+
+func parseCatalogNDJSON(data []byte) ([]catalogLine, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxCatalogLine)
+
+	var lines []catalogLine
+	for sc.Scan() {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		var line catalogLine
+		if err := json.Unmarshal(b, &line.row); err != nil {
+			line.errs = append(line.errs, rowError{message: fmt.Sprintf("invalid JSON: %v", err)})
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errorx.ErrInvalidImportFile, err)
+	}
+
+	return lines, nil
+}
+
+// catalogWriter writes export rows in one of the catalogue formats.
+type catalogWriter interface {
+	Write(row models.CatalogRow) error
+	Flush() error
+}
+
+// csvCatalogWriter writes the fixed columns followed by one column per
+// attribute name, in the order given.
+type csvCatalogWriter struct {
+	w          *csv.Writer
+	attributes []string
+	header     bool
+}
+
+func newCSVCatalogWriter(w io.Writer, attributes []string) *csvCatalogWriter {
+	return &csvCatalogWriter{w: csv.NewWriter(w), attributes: attributes}
+}
+
+func (cw *csvCatalogWriter) writeHeader() error {
+	header := make([]string, 0, len(catalogColumns)+len(cw.attributes))
+	header = append(header, catalogColumns...)
+	for _, name := range cw.attributes {
+		header = append(header, attributeColumnPrefix+name)
+	}
+	cw.header = true
+	return cw.w.Write(header)
+}
+
+func (cw *csvCatalogWriter) Write(row models.CatalogRow) error {
+	if !cw.header {
+		if err := cw.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	rec := []string{
+		row.Category,
+		row.Name,
+		row.Slug,
+		row.Description,
+		row.Brand,
+		row.SKU,
+		strconv.FormatFloat(row.Price, 'f', -1, 64),
+		strconv.FormatInt(int64(row.Stock), 10),
+		row.ImageURL,
+	}
+	for _, name := range cw.attributes {
+		rec = append(rec, row.Attributes[name])
+	}
+
+	return cw.w.Write(rec)
+}
+
+// Flush also writes the header of an export without rows.
+func (cw *csvCatalogWriter) Flush() error {
+	if !cw.header {
+		if err := cw.writeHeader(); err != nil {
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonCatalogWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONCatalogWriter(w io.Writer) *ndjsonCatalogWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonCatalogWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (nw *ndjsonCatalogWriter) Write(row models.CatalogRow) error {
+	return nw.enc.Encode(row)
+}
+
+func (nw *ndjsonCatalogWriter) Flush() error {
+	return nw.buf.Flush()
+}
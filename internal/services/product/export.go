@@ -0,0 +1,112 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+// exportCatalogSQL lists the store's live variants, one row each, with their
+// attribute values as a JSON object keyed by attribute name.
+const exportCatalogSQL = `
+SELECT
+  c.name,
+  p.name,
+  COALESCE(p.slug, ''),
+  COALESCE(p.description, ''),
+  COALESCE(p.brand, ''),
+  v.sku,
+  v.price::TEXT,
+  v.stock_quantity,
+  COALESCE(v.primary_image_url, ''),
+  COALESCE((
+    SELECT json_object_agg(ad.name, vav.value ORDER BY ad.name)
+    FROM variant_attribute_value vav
+    JOIN attribute_definition ad
+      ON ad.attribute_id = vav.attribute_id
+    WHERE vav.variant_id = v.variant_id
+  ), '{}')::TEXT
+FROM product p
+JOIN category_definition c
+  ON c.category_id = p.category_id
+JOIN product_variant v
+  ON v.product_id = p.product_id
+WHERE p.store_id = $1
+  AND p.deleted_at IS NULL
+  AND v.deleted_at IS NULL
+ORDER BY p.product_id, v.variant_id
+`
+
+// ExportCatalog writes every live variant of the store to w in the given
+// catalogue format, which StartImport reads back. Rows are streamed from the
+// database as they are written, so memory use does not grow with the store.
+// Nothing is written when the format is invalid.
+func (s *Service) ExportCatalog(ctx context.Context, storeID int64, format string, w io.Writer) error {
+	var cw catalogWriter
+
+	switch format {
+	case CatalogCSV:
+		// the CSV header needs every attribute column up front
+		names, err := s.db.Queries.ListStoreExportAttributeNames(ctx, storeID)
+		if err != nil {
+			return err
+		}
+		cw = newCSVCatalogWriter(w, names)
+	case CatalogNDJSON:
+		cw = newNDJSONCatalogWriter(w)
+	default:
+		return errorx.ErrInvalidCatalogFormat
+	}
+
+	rows, err := s.db.QueryContext(ctx, exportCatalogSQL, storeID)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row        models.CatalogRow
+			price      string
+			attributes []byte
+		)
+		if err := rows.Scan(
+			&row.Category,
+			&row.Name,
+			&row.Slug,
+			&row.Description,
+			&row.Brand,
+			&row.SKU,
+			&price,
+			&row.Stock,
+			&row.ImageURL,
+			&attributes,
+		); err != nil {
+			return fmt.Errorf("export scan: %w", err)
+		}
+
+		if row.Price, err = strconv.ParseFloat(price, 64); err != nil {
+			return fmt.Errorf("export price: %w", err)
+		}
+		if err := json.Unmarshal(attributes, &row.Attributes); err != nil {
+			return fmt.Errorf("export attributes: %w", err)
+		}
+		if len(row.Attributes) == 0 {
+			row.Attributes = nil
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return cw.Flush()
+}
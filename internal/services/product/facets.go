@@ -0,0 +1,232 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// priceBucketEdges splits the price facet: the first bucket starts at zero
+// and the last one is open-ended.
+var priceBucketEdges = []float64{50, 100, 250, 500, 1000}
+
+const brandFacetSQL = `WITH matched AS (%s)
+SELECT brand, COUNT(*)
+FROM matched
+WHERE brand IS NOT NULL
+GROUP BY brand
+ORDER BY COUNT(*) DESC, brand`
+
+const priceFacetSQL = `WITH matched AS (%s)
+SELECT width_bucket(price, $%d::NUMERIC[]) AS bucket, COUNT(*)
+FROM matched
+GROUP BY bucket
+ORDER BY bucket`
+
+const attributeFacetSQL = `WITH matched AS (%s)
+SELECT vav.attribute_id, ad.name, vav.value, COUNT(*)
+FROM matched m
+JOIN variant_attribute_value vav
+  ON vav.variant_id = m.default_variant_id
+JOIN attribute_definition ad
+  ON ad.attribute_id = vav.attribute_id
+WHERE %s
+GROUP BY vav.attribute_id, ad.name, vav.value
+ORDER BY COUNT(*) DESC, vav.value`
+
+// anyVariantAttributeFacetSQL counts a product once per value carried by any
+// of its live in-stock variants, the variants an AnyVariant filter matches.
+const anyVariantAttributeFacetSQL = `WITH matched AS (%s)
+SELECT vav.attribute_id, ad.name, vav.value, COUNT(*)
+FROM matched m
+JOIN LATERAL (
+  SELECT DISTINCT a.attribute_id, a.value
+  FROM product_variant v
+  JOIN variant_attribute_value a
+    ON a.variant_id = v.variant_id
+  WHERE v.product_id = m.product_id
+    AND v.deleted_at IS NULL
+    AND v.stock_quantity > 0
+) vav ON TRUE
+JOIN attribute_definition ad
+  ON ad.attribute_id = vav.attribute_id
+WHERE %s
+GROUP BY vav.attribute_id, ad.name, vav.value
+ORDER BY COUNT(*) DESC, vav.value`
+
+func readProductFacetsTemplate() (string, error) {
+	b, err := os.ReadFile("./internal/database/product_facets_template.sql")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ProductFacets counts the products matching f per brand, price bucket and
+// attribute value of the default variant, or of any live in-stock variant
+// when f.AnyVariant is set. Each facet applies every filter in f
+// except its own, so picking a brand still shows the other brands' counts.
+// Pagination in f is ignored.
+func (s *Service) ProductFacets(ctx context.Context, storeID int64, f ListProductFilters) (*models.ProductFacetsDTO, error) {
+	tpl, err := readProductFacetsTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("read facets template: %w", err)
+	}
+
+	if f.Query != nil {
+		f.Query = database.BuildSearchQuery(*f.Query)
+	}
+
+	facets := &models.ProductFacetsDTO{
+		Brands:     make([]models.FacetCountDTO, 0),
+		Prices:     make([]models.PriceBucketDTO, 0),
+		Attributes: make([]models.AttributeFacetDTO, 0),
+	}
+
+	// brands: every filter but the brand
+	noBrand := f
+	noBrand.Brand = nil
+	matched, args := matchedProducts(tpl, storeID, noBrand, f.Attributes)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(brandFacetSQL, matched), args...)
+	if err != nil {
+		return nil, fmt.Errorf("brand facet: %w", err)
+	}
+	for rows.Next() {
+		var c models.FacetCountDTO
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("brand facet scan: %w", err)
+		}
+		facets.Brands = append(facets.Brands, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("brand facet: %w", err)
+	}
+
+	// prices: every filter but the price range
+	noPrice := f
+	noPrice.MinPrice, noPrice.MaxPrice = nil, nil
+	matched, args = matchedProducts(tpl, storeID, noPrice, f.Attributes)
+	args = append(args, pq.Array(priceBucketEdges))
+
+	rows, err = s.db.QueryContext(ctx, fmt.Sprintf(priceFacetSQL, matched, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("price facet: %w", err)
+	}
+	for rows.Next() {
+		var bucket int
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("price facet scan: %w", err)
+		}
+		b := models.PriceBucketDTO{Count: count}
+		if bucket > 0 {
+			b.Min = &priceBucketEdges[bucket-1]
+		}
+		if bucket < len(priceBucketEdges) {
+			b.Max = &priceBucketEdges[bucket]
+		}
+		facets.Prices = append(facets.Prices, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("price facet: %w", err)
+	}
+
+	// attributes: unfiltered attributes share one query over all filters,
+	// each filtered attribute gets one over all filters but its own
+	byID := make(map[int64]*models.AttributeFacetDTO)
+
+	attrSQL := attributeFacetSQL
+	if f.AnyVariant {
+		attrSQL = anyVariantAttributeFacetSQL
+	}
+
+	filtered := make([]int64, 0, len(f.Attributes))
+	for _, a := range f.Attributes {
+		filtered = append(filtered, a.AttributeID)
+	}
+
+	matched, args = matchedProducts(tpl, storeID, f, f.Attributes)
+	args = append(args, pq.Array(filtered))
+	cond := fmt.Sprintf("NOT (vav.attribute_id = ANY($%d::BIGINT[]))", len(args))
+	if err := s.collectAttributeFacets(ctx, attrSQL, matched, cond, args, byID); err != nil {
+		return nil, err
+	}
+
+	for i, a := range f.Attributes {
+		others := make([]database.AttributeFilter, 0, len(f.Attributes)-1)
+		others = append(others, f.Attributes[:i]...)
+		others = append(others, f.Attributes[i+1:]...)
+
+		matched, args = matchedProducts(tpl, storeID, f, others)
+		args = append(args, a.AttributeID)
+		cond := fmt.Sprintf("vav.attribute_id = $%d", len(args))
+		if err := s.collectAttributeFacets(ctx, attrSQL, matched, cond, args, byID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range byID {
+		facets.Attributes = append(facets.Attributes, *a)
+	}
+	sort.Slice(facets.Attributes, func(i, j int) bool {
+		return facets.Attributes[i].Name < facets.Attributes[j].Name
+	})
+
+	return facets, nil
+}
+
+// matchedProducts fills the facets template with f and the given attribute
+// filters and returns the SQL with its arguments.
+func matchedProducts(tpl string, storeID int64, f ListProductFilters, attrs []database.AttributeFilter) (string, []interface{}) {
+	args := []interface{}{storeID, f.CategoryID, f.Brand, f.MinPrice, f.MaxPrice, f.InStock, f.Query}
+
+	joinSQL, joinArgs := database.BuildAttributeFilterSQL(attrs, len(args)+1)
+	args = append(args, joinArgs...)
+
+	return strings.Replace(tpl, "/*{{DYNAMIC_JOINS}}*/", joinSQL, 1), args
+}
+
+func (s *Service) collectAttributeFacets(
+	ctx context.Context,
+	query, matched, cond string,
+	args []interface{},
+	byID map[int64]*models.AttributeFacetDTO,
+) error {
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(query, matched, cond), args...)
+	if err != nil {
+		return fmt.Errorf("attribute facet: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+			c    models.FacetCountDTO
+		)
+		if err := rows.Scan(&id, &name, &c.Value, &c.Count); err != nil {
+			return fmt.Errorf("attribute facet scan: %w", err)
+		}
+
+		a, ok := byID[id]
+		if !ok {
+			a = &models.AttributeFacetDTO{AttributeID: id, Name: name}
+			byID[id] = a
+		}
+		a.Values = append(a.Values, c)
+	}
+
+	return rows.Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"mime/multipart"
 
 	"github.com/Secure-Website-Builder/Backend/internal/models"
@@ -64,7 +65,7 @@ func (s *Service) UploadVariantImage(
 	})
 
 	if err != nil {
-		// TODO: Later we can use outbox pattern to handler the failure of Deleting image 
+		// TODO: Later we can use outbox pattern to handler the failure of Deleting image
 		// right now we assume that delete always succeeds
 		s.storage.Delete(ctx, key)
 		return "", err
@@ -72,3 +73,31 @@ func (s *Service) UploadVariantImage(
 
 	return url, nil
 }
+
+// setPrimaryImage uploads r as the variant's primary image after the variant
+// was committed. If recording the URL fails, the upload is deleted again so
+// no media is orphaned; the variant is left without an image either way.
+func (s *Service) setPrimaryImage(ctx context.Context, storeID int64, variant *models.ProductVariant, r io.Reader) error {
+	key := generateImageUploadKey(storeID, variant.VariantID)
+	url, _, err := s.media.UploadImage(ctx, key, r)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Queries.SetPrimaryVariantImage(ctx, models.SetPrimaryVariantImageParams{
+		VariantID: variant.VariantID,
+		PrimaryImageUrl: sql.NullString{
+			String: url,
+			Valid:  true,
+		},
+	})
+	if err != nil {
+		// TODO: Later we can use outbox pattern to handler the failure of Deleting image
+		// right now we assume that delete always succeeds
+		_ = s.storage.Delete(ctx, key)
+		return err
+	}
+
+	variant.PrimaryImageUrl = sql.NullString{String: url, Valid: true}
+	return nil
+}
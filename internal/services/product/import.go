@@ -0,0 +1,356 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/Secure-Website-Builder/Backend/internal/services/media"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+// MaxImportSize caps an import file. The file is held in memory until its
+// job finishes.
+const MaxImportSize = 10 << 20
+
+// Import job statuses.
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// errDryRun rolls back a row's transaction once it has been fully applied.
+var errDryRun = errors.New("dry run")
+
+// StartImport checks that data can be read as a catalogue in the given
+// format and imports its rows in the background, each one like a call to
+// CreateProduct, except that a row naming an existing product and attribute
+// set sets that variant's stock to the row's rather than adding to it, so
+// importing an export leaves the catalogue as it was. Rows fail on their own;
+// their errors are kept on the job. A dry run applies every row and rolls it
+// back, so it reports the same errors without changing the store or fetching
+// images, except for conflicts between rows of the same file.
+func (s *Service) StartImport(
+	ctx context.Context,
+	storeID int64,
+	format string,
+	data []byte,
+	dryRun bool,
+	actor inventory.Actor,
+) (*models.ImportJobDTO, error) {
+
+	lines, err := parseCatalog(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.db.Queries.CreateImportJob(ctx, models.CreateImportJobParams{
+		StoreID:   storeID,
+		Format:    format,
+		DryRun:    dryRun,
+		TotalRows: int32(len(lines)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go s.runImport(job, lines, actor)
+
+	return toImportJobDTO(job, nil), nil
+}
+
+// GetImport returns an import job of the store with its row errors so far.
+func (s *Service) GetImport(ctx context.Context, storeID, jobID int64) (*models.ImportJobDTO, error) {
+	job, err := s.db.Queries.GetImportJob(ctx, models.GetImportJobParams{
+		JobID:   jobID,
+		StoreID: storeID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorx.ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrs, err := s.db.Queries.ListImportErrors(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toImportJobDTO(job, rowErrs), nil
+}
+
+// FailInterruptedImports marks jobs left open by a previous run of the
+// server as failed; they are not resumed.
+func (s *Service) FailInterruptedImports(ctx context.Context) (int64, error) {
+	return s.db.Queries.FailInterruptedImportJobs(ctx)
+}
+
+func (s *Service) runImport(job models.ProductImportJob, lines []catalogLine, actor inventory.Actor) {
+	ctx := context.Background()
+
+	if err := s.db.Queries.StartImportJob(ctx, job.JobID); err != nil {
+		s.failImport(ctx, job.JobID, err)
+		return
+	}
+
+	res := &importResolver{
+		svc:        s,
+		storeID:    job.StoreID,
+		categories: make(map[string]int64),
+		attributes: make(map[string]int64),
+	}
+
+	var processed, failed int32
+	for i, line := range lines {
+		errs, rowFailed, err := s.importRow(ctx, job.StoreID, job.DryRun, line, actor, res)
+		if err != nil {
+			s.failImport(ctx, job.JobID, err)
+			return
+		}
+
+		for _, e := range errs {
+			if err := s.db.Queries.InsertImportError(ctx, models.InsertImportErrorParams{
+				JobID:     job.JobID,
+				RowNumber: int32(i + 1),
+				Field:     sql.NullString{String: e.field, Valid: e.field != ""},
+				Message:   e.message,
+			}); err != nil {
+				s.failImport(ctx, job.JobID, err)
+				return
+			}
+		}
+
+		processed++
+		if rowFailed {
+			failed++
+		}
+		if err := s.db.Queries.UpdateImportJobProgress(ctx, models.UpdateImportJobProgressParams{
+			JobID:         job.JobID,
+			ProcessedRows: processed,
+			FailedRows:    failed,
+		}); err != nil {
+			s.failImport(ctx, job.JobID, err)
+			return
+		}
+	}
+
+	if err := s.db.Queries.FinishImportJob(ctx, models.FinishImportJobParams{
+		JobID:  job.JobID,
+		Status: ImportCompleted,
+	}); err != nil {
+		log.Printf("product import %d: %v", job.JobID, err)
+	}
+}
+
+func (s *Service) failImport(ctx context.Context, jobID int64, cause error) {
+	log.Printf("product import %d: %v", jobID, cause)
+
+	if err := s.db.Queries.FinishImportJob(ctx, models.FinishImportJobParams{
+		JobID:  jobID,
+		Status: ImportFailed,
+		Error:  sql.NullString{String: "internal error", Valid: true},
+	}); err != nil {
+		log.Printf("product import %d: %v", jobID, err)
+	}
+}
+
+// importRow applies one row. It returns the row's errors and whether they
+// kept it from being imported; an image that cannot be fetched is reported
+// but does not. A non-nil error means the job itself cannot go on.
+func (s *Service) importRow(
+	ctx context.Context,
+	storeID int64,
+	dryRun bool,
+	line catalogLine,
+	actor inventory.Actor,
+	res *importResolver,
+) ([]rowError, bool, error) {
+
+	row := line.row
+	errs := line.errs
+	if len(errs) > 0 {
+		return errs, true, nil
+	}
+
+	if strings.TrimSpace(row.Category) == "" {
+		errs = append(errs, rowError{"category", "is required"})
+	}
+	if strings.TrimSpace(row.Name) == "" {
+		errs = append(errs, rowError{"name", "is required"})
+	}
+	if strings.TrimSpace(row.SKU) == "" {
+		errs = append(errs, rowError{"sku", "is required"})
+	}
+	if row.Price < 0 {
+		errs = append(errs, rowError{"price", "must not be negative"})
+	}
+	if row.Stock < 0 {
+		errs = append(errs, rowError{"stock", "must not be negative"})
+	}
+
+	var in models.CreateProductInput
+
+	if strings.TrimSpace(row.Category) != "" {
+		id, err := res.category(ctx, row.Category)
+		if err != nil {
+			return nil, false, err
+		}
+		if id == 0 {
+			errs = append(errs, rowError{"category", fmt.Sprintf("category %q is not enabled in this store", row.Category)})
+		}
+		in.CategoryID = id
+	}
+
+	names := make([]string, 0, len(row.Attributes))
+	for name := range row.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		id, err := res.attribute(ctx, name)
+		if err != nil {
+			return nil, false, err
+		}
+		if id == 0 {
+			errs = append(errs, rowError{attributeColumnPrefix + name, "unknown attribute"})
+			continue
+		}
+		in.Variant.Attributes = append(in.Variant.Attributes, models.VariantAttributeInput{
+			AttributeID: id,
+			Value:       row.Attributes[name],
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs, true, nil
+	}
+
+	in.Name = row.Name
+	in.Slug = row.Slug
+	in.Description = row.Description
+	in.Brand = row.Brand
+	in.Variant.SKU = row.SKU
+	in.Variant.Price = row.Price
+	in.Variant.Stock = row.Stock
+
+	var variant models.ProductVariant
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		var err error
+		if _, variant, err = createProductTx(ctx, qtx, storeID, in, true, actor); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return []rowError{publicRowError(storeID, "", err, "product could not be imported")}, true, nil
+	}
+
+	if row.ImageURL == "" {
+		return nil, false, nil
+	}
+	if err := media.CheckImageURL(row.ImageURL); err != nil {
+		return []rowError{{"image_url", "must be an absolute http or https url"}}, false, nil
+	}
+	if dryRun || variant.PrimaryImageUrl.Valid {
+		return nil, false, nil
+	}
+
+	body, err := s.media.FetchImage(ctx, row.ImageURL)
+	if err == nil {
+		err = s.setPrimaryImage(ctx, storeID, &variant, body)
+		body.Close()
+	}
+	if err != nil {
+		return []rowError{publicRowError(storeID, "image_url", err, "image could not be fetched")}, false, nil
+	}
+
+	return nil, false, nil
+}
+
+// publicRowError turns err into a row error the store owner may see: the
+// public message of a known error, or else generic, with err only logged.
+func publicRowError(storeID int64, field string, err error, generic string) rowError {
+	if e := errorx.Resolve(err); e.Status < http.StatusInternalServerError {
+		return rowError{field, e.Message}
+	}
+	log.Printf("product import for store %d: %v", storeID, err)
+	return rowError{field, generic}
+}
+
+// importResolver looks up category and attribute names for one job,
+// remembering the answers; 0 stands for an unknown name.
+type importResolver struct {
+	svc        *Service
+	storeID    int64
+	categories map[string]int64
+	attributes map[string]int64
+}
+
+func (r *importResolver) category(ctx context.Context, name string) (int64, error) {
+	if id, ok := r.categories[name]; ok {
+		return id, nil
+	}
+	id, err := r.svc.ResolveCategoryNameToID(ctx, r.storeID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	r.categories[name] = id
+	return id, nil
+}
+
+func (r *importResolver) attribute(ctx context.Context, name string) (int64, error) {
+	if id, ok := r.attributes[name]; ok {
+		return id, nil
+	}
+	id, err := r.svc.ResolveAttributeNameToID(ctx, r.storeID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	r.attributes[name] = id
+	return id, nil
+}
+
+func toImportJobDTO(job models.ProductImportJob, rowErrs []models.ProductImportError) *models.ImportJobDTO {
+	dto := &models.ImportJobDTO{
+		JobID:         job.JobID,
+		Format:        job.Format,
+		DryRun:        job.DryRun,
+		Status:        job.Status,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		FailedRows:    job.FailedRows,
+		Error:         utils.NullStringToPtr(job.Error),
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    utils.NullTimeToPtr(job.FinishedAt),
+		Errors:        make([]models.ImportRowErrorDTO, 0, len(rowErrs)),
+	}
+	for _, e := range rowErrs {
+		dto.Errors = append(dto.Errors, models.ImportRowErrorDTO{
+			Row:     e.RowNumber,
+			Field:   utils.NullStringToPtr(e.Field),
+			Message: e.Message,
+		})
+	}
+	return dto
+}
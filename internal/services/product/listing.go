@@ -0,0 +1,230 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+// Sort orders accepted by ListProducts.
+const (
+	SortNewest      = "newest"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortName        = "name"
+	SortBestSelling = "best_selling"
+	SortRelevance   = "relevance" // only with a search query
+)
+
+// listSort describes one sort order of the product listing. Every order is
+// made total by product_id, which breaks ties in the same direction.
+type listSort struct {
+	key     string // SQL expression of the sort column
+	keyType string // type the cursor's key is cast back to
+	desc    bool
+	joins   string // extra joins the key needs
+}
+
+// bestSellingJoin sums the units of each product sold in fulfilled orders.
+const bestSellingJoin = `
+LEFT JOIN (
+  SELECT v.product_id,
+         SUM(oi.quantity)::BIGINT AS units
+  FROM order_item oi
+  JOIN customer_order co
+    ON co.order_id = oi.order_id
+  JOIN product_variant v
+    ON v.variant_id = oi.variant_id
+  WHERE co.store_id = $1
+    AND co.status IN ('completed', 'shipped', 'delivered')
+  GROUP BY v.product_id
+) sales
+  ON sales.product_id = p.product_id
+`
+
+var listSorts = map[string]listSort{
+	SortNewest:      {key: "p.created_at", keyType: "TIMESTAMPTZ", desc: true},
+	SortPriceAsc:    {key: "pv.price", keyType: "NUMERIC"},
+	SortPriceDesc:   {key: "pv.price", keyType: "NUMERIC", desc: true},
+	SortName:        {key: "p.name", keyType: "TEXT"},
+	SortBestSelling: {key: "COALESCE(sales.units, 0)", keyType: "BIGINT", desc: true, joins: bestSellingJoin},
+	SortRelevance:   {key: "ts_rank(ps.document, to_tsquery('simple', $8))", keyType: "REAL", desc: true},
+}
+
+// orderBy is the ORDER BY list of the sort.
+func (ls listSort) orderBy() string {
+	dir := "ASC"
+	if ls.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, p.product_id %s", ls.key, dir, dir)
+}
+
+// after is the keyset condition resuming after the row whose sort key and
+// product_id are bound to placeholders keyParam and keyParam+1.
+func (ls listSort) after(keyParam int) string {
+	op := ">"
+	if ls.desc {
+		op = "<"
+	}
+	return fmt.Sprintf("AND (%s, p.product_id) %s ($%d::%s, $%d::BIGINT)",
+		ls.key, op, keyParam, ls.keyType, keyParam+1)
+}
+
+// listCursor is the position after the last row of a page. It is handed to
+// clients as opaque base64 and only valid for the sort it was made for.
+type listCursor struct {
+	Sort      string `json:"s"`
+	Key       string `json:"k"`
+	ProductID int64  `json:"id"`
+}
+
+func encodeListCursor(c listCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeListCursor also checks that the key reads as the sort's key type, so
+// a tampered cursor is rejected here rather than failing the query's cast.
+func decodeListCursor(s, sort string) (listCursor, error) {
+	var c listCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errorx.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.ProductID <= 0 {
+		return c, errorx.ErrInvalidCursor
+	}
+	if !validCursorKey(listSorts[sort].keyType, c.Key) {
+		return c, errorx.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// cursorTimeLayouts are the shapes Postgres prints a TIMESTAMPTZ in,
+// depending on the offset.
+var cursorTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+}
+
+// validCursorKey reports whether key, a sort key printed as text by the
+// listing query, can be cast back to keyType.
+func validCursorKey(keyType, key string) bool {
+	switch keyType {
+	case "TIMESTAMPTZ":
+		for _, layout := range cursorTimeLayouts {
+			if _, err := time.Parse(layout, key); err == nil {
+				return true
+			}
+		}
+		return false
+	case "NUMERIC":
+		return database.NumericValue.MatchString(key)
+	case "BIGINT":
+		_, err := strconv.ParseInt(key, 10, 64)
+		return err == nil
+	case "REAL":
+		_, err := strconv.ParseFloat(key, 32)
+		return err == nil
+	}
+	return true
+}
+
+// ResolveAttributeFilter turns the query values of the attribute called name,
+// global or private to the store, into a filter, reading them according to
+// the attribute's data type:
+//   - numeric: exact numbers, compared as numbers, and at most one range
+//     "min..max" where either end may be left out ("13..15", "13..", "..15");
+//     a value matching any of them passes
+//   - boolean: anything strconv.ParseBool accepts
+//   - enum, text: exact values
+//
+// It returns nil for an unknown attribute and errorx.ErrInvalidAttributeFilter
+// for values that do not fit the type.
+func (s *Service) ResolveAttributeFilter(
+	ctx context.Context,
+	storeID int64,
+	name string,
+	values []string,
+	anyVariant bool,
+) (*database.AttributeFilter, error) {
+
+	attr, err := s.db.Queries.GetAttributeByName(ctx, models.GetAttributeByNameParams{
+		Name:    name,
+		StoreID: sql.NullInt64{Int64: storeID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &database.AttributeFilter{
+		AttributeID: attr.AttributeID,
+		Numeric:     attr.DataType == database.AttributeNumeric,
+		AnyVariant:  anyVariant,
+	}
+
+	hasRange := false
+	for _, v := range values {
+		switch attr.DataType {
+		case database.AttributeNumeric:
+			if lo, hi, ok := strings.Cut(v, ".."); ok {
+				if hasRange {
+					return nil, errorx.ErrInvalidAttributeFilter
+				}
+				hasRange = true
+				if f.Min, err = parseBound(lo); err != nil {
+					return nil, errorx.ErrInvalidAttributeFilter
+				}
+				if f.Max, err = parseBound(hi); err != nil {
+					return nil, errorx.ErrInvalidAttributeFilter
+				}
+				continue
+			}
+			if !database.NumericValue.MatchString(v) {
+				return nil, errorx.ErrInvalidAttributeFilter
+			}
+			f.Values = append(f.Values, v)
+
+		case database.AttributeBoolean:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errorx.ErrInvalidAttributeFilter
+			}
+			f.Values = append(f.Values, strconv.FormatBool(b))
+
+		default:
+			f.Values = append(f.Values, v)
+		}
+	}
+
+	return f, nil
+}
+
+// parseBound parses one end of a numeric range; an empty end is open.
+func parseBound(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
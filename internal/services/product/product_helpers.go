@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
 )
 
 func findOrCreateProduct(
@@ -43,3 +46,79 @@ func findOrCreateProduct(
 	wasOutOfStock = true 
 	return
 }
+
+// createProductTx is the body of the CreateProduct transaction: it checks the
+// store offers the category, validates the variant's attributes, finds or
+// creates the product and the variant, and makes the variant the default one
+// if the product was not sellable. setStock is passed to findOrCreateVariant.
+func createProductTx(
+	ctx context.Context,
+	qtx *models.Queries,
+	storeID int64,
+	in models.CreateProductInput,
+	setStock bool,
+	actor inventory.Actor,
+) (product models.Product, variant models.ProductVariant, err error) {
+
+	var productWasOutOfStock bool
+
+	// The category must be one the store offers
+	enabled, err := qtx.IsStoreCategory(ctx, models.IsStoreCategoryParams{
+		StoreID:    storeID,
+		CategoryID: in.CategoryID,
+	})
+	if err != nil {
+		return
+	}
+	if !enabled {
+		err = errorx.ErrInvalidCategory
+		return
+	}
+
+	// Validate attributes in request against preset category attributes
+	if err = validateVariantAttributes(ctx, qtx, in.CategoryID, in.Variant.Attributes); err != nil {
+		return
+	}
+
+	// Find or create product
+	product, productWasOutOfStock, err = findOrCreateProduct(ctx, qtx, storeID, in)
+	if err != nil {
+		return
+	}
+
+	// Compute attribute hash
+	hash := utils.HashAttributes(in.Variant.Attributes)
+
+	// Find or create variant
+	variant, err = findOrCreateVariant(
+		ctx,
+		qtx,
+		storeID,
+		product.ProductID,
+		hash,
+		in.Variant,
+		setStock,
+		actor,
+	)
+	if err != nil {
+		return
+	}
+
+	// Set default variant if product was previously not sellable
+	// either new product or existing product out of stock
+	if productWasOutOfStock {
+		err = qtx.SetDefaultVariant(ctx, models.SetDefaultVariantParams{
+			ProductID: product.ProductID,
+			DefaultVariantID: sql.NullInt64{
+				Int64: variant.VariantID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	err = qtx.RefreshProductSearch(ctx, product.ProductID)
+	return
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
 	"github.com/Secure-Website-Builder/Backend/internal/storage"
 	"github.com/Secure-Website-Builder/Backend/internal/utils"
 	"github.com/Secure-Website-Builder/Backend/internal/services/media"
@@ -119,14 +121,25 @@ func (s *Service) GetFullProduct(ctx context.Context, storeID, productID int64)
 
 // ListProductFilters input shape
 type ListProductFilters struct {
-	Page       int
 	Limit      int
+	Cursor     *string // next_cursor of the previous page
+	Sort       string  // one of the Sort* orders; empty picks the default
 	CategoryID *int64
 	MinPrice   *float64
 	MaxPrice   *float64
 	Brand      *string
 	InStock    *bool
+	Query      *string // free text; results are ranked by relevance
 	Attributes []database.AttributeFilter
+	AnyVariant bool // attribute facets count any live in-stock variant, as with ?match=any
+}
+
+// ProductPage is one page of the product listing. NextCursor is nil on the
+// last page.
+type ProductPage struct {
+	Products   []models.ProductDTO
+	Total      int64
+	NextCursor *string
 }
 
 // readTemplate reads the template file once
@@ -144,7 +157,10 @@ func readListProductsTemplate() (string, error) {
 func (s *Service) ResolveAttributeNameToID(ctx context.Context, storeID int64, name string) (int64, error) {
 	// The sqlc function generated from queries.sql is called ResolveAttributeIDByName
 	// (ensure names match your sqlc config; adjust name if sqlc generated a different function).
-	id, err := s.db.Queries.ResolveAttributeIDByName(ctx, name)
+	id, err := s.db.Queries.ResolveAttributeIDByName(ctx, models.ResolveAttributeIDByNameParams{
+		Name:    name,
+		StoreID: sql.NullInt64{Int64: storeID, Valid: true},
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -162,7 +178,12 @@ func (s *Service) ResolveCategoryNameToID(ctx context.Context, storeID int64, na
 }
 
 // ListProducts builds SQL from template + dynamic joins and executes it.
-func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProductFilters) ([]models.ProductDTO, error) {
+//
+// Pages are keyset-paginated: the cursor carries the last row's sort key and
+// product_id, so deep pages stay cheap and products added meanwhile neither
+// shift nor repeat rows. Searches sort by relevance unless told otherwise,
+// everything else by newest first.
+func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProductFilters) (*ProductPage, error) {
 	// Load template
 	tpl, err := readListProductsTemplate()
 	if err != nil {
@@ -170,15 +191,27 @@ func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProduct
 	}
 
 	// sane defaults
-	if f.Page <= 0 {
-		f.Page = 1
-	}
 	if f.Limit <= 0 || f.Limit > 200 {
 		f.Limit = 20
 	}
-	offset := (f.Page - 1) * f.Limit
 
-	args := []interface{}{storeID, f.Limit, offset, f.CategoryID, f.Brand, f.MinPrice, f.MaxPrice, f.InStock}
+	if f.Query != nil {
+		f.Query = database.BuildSearchQuery(*f.Query)
+	}
+
+	if f.Sort == "" {
+		f.Sort = SortNewest
+		if f.Query != nil {
+			f.Sort = SortRelevance
+		}
+	}
+	order, ok := listSorts[f.Sort]
+	if !ok || (f.Sort == SortRelevance && f.Query == nil) {
+		return nil, errorx.ErrInvalidSort
+	}
+
+	// one extra row tells whether there is a next page
+	args := []interface{}{storeID, f.Limit + 1, f.CategoryID, f.Brand, f.MinPrice, f.MaxPrice, f.InStock, f.Query}
 	paramIndex := len(args) + 1 // next placeholder index
 
 	// attribute joins
@@ -188,8 +221,26 @@ func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProduct
 		paramIndex += len(joinArgs)
 	}
 
+	// keyset cursor
+	cursorSQL := ""
+	if f.Cursor != nil {
+		cur, err := decodeListCursor(*f.Cursor, f.Sort)
+		if err != nil {
+			return nil, err
+		}
+		cursorSQL = order.after(paramIndex)
+		args = append(args, cur.Key, cur.ProductID)
+		paramIndex += 2
+	}
+
 	// assemble SQL
-	sqlFinal := strings.Replace(tpl, "/*{{DYNAMIC_JOINS}}*/", joinSQL, 1)
+	sqlFinal := strings.NewReplacer(
+		"/*{{SORT_KEY}}*/", order.key,
+		"/*{{SORT_JOINS}}*/", order.joins,
+		"/*{{DYNAMIC_JOINS}}*/", joinSQL,
+		"/*{{CURSOR}}*/", cursorSQL,
+		"/*{{ORDER_BY}}*/", order.orderBy(),
+	).Replace(tpl)
 
 	// Debugging
 	// fmt.Println("SQL:", sqlFinal)
@@ -203,8 +254,10 @@ func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProduct
 	defer rows.Close()
 
 	res := make([]models.ProductDTO, 0)
+	keys := make([]string, 0)
 	for rows.Next() {
 		var dto models.ProductDTO
+		var key string
 		if err := rows.Scan(
 			&dto.ProductID,
 			&dto.Name,
@@ -217,16 +270,41 @@ func (s *Service) ListProducts(ctx context.Context, storeID int64, f ListProduct
 			&dto.Price,
 			&dto.ImageURL,
 			&dto.InStock,
+			&key,
 		); err != nil {
 			return nil, fmt.Errorf("scan: %w", err)
 		}
 		res = append(res, dto)
+		keys = append(keys, key)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows err: %w", err)
 	}
 
-	return res, nil
+	page := &ProductPage{Products: res}
+
+	if len(res) > f.Limit {
+		page.Products = res[:f.Limit]
+		last := f.Limit - 1
+		next := encodeListCursor(listCursor{
+			Sort:      f.Sort,
+			Key:       keys[last],
+			ProductID: res[last].ProductID,
+		})
+		page.NextCursor = &next
+	}
+
+	// total over the same filters, without the cursor
+	countTpl, err := readProductFacetsTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("read count template: %w", err)
+	}
+	matched, countArgs := matchedProducts(countTpl, storeID, f, f.Attributes)
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+matched+") matched", countArgs...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("count: %w", err)
+	}
+
+	return page, nil
 }
 
 // CreateProduct creates or updates a product and its variant inside a single database transaction.
@@ -249,6 +327,7 @@ func (s *Service) CreateProduct(
 	storeID int64,
 	in models.CreateProductInput,
 	image multipart.File,
+	actor inventory.Actor,
 ) (*models.Product, *models.ProductVariant, error) {
 
 	var (
@@ -259,61 +338,8 @@ func (s *Service) CreateProduct(
 
 	// Start transaction
 	err = s.db.RunInTx(ctx, func(qtx *models.Queries) error {
-
-		var productWasOutOfStock bool
-		
-
-		// Validate attributes in request against preset category attributes
-		if err := validateVariantAttributes(ctx, qtx, in.CategoryID, in.Variant.Attributes); err != nil {
-			return err
-		}
-
-		// Find or create product
-		product, productWasOutOfStock, err = findOrCreateProduct(ctx, qtx, storeID, in)
-		if err != nil {
-			return err
-		}
-
-		// Compute attribute hash
-		hash := utils.HashAttributes(in.Variant.Attributes)
-
-		// Find or create variant
-		finalVariant, err = findOrCreateVariant(
-			ctx,
-			qtx,
-			storeID,
-			product.ProductID,
-			hash,
-			in.Variant,
-		)
-		if err != nil {
-			return err
-		}
-
-		// Set default variant if product was previously not sellable
-		// either new product or existing product out of stock
-		if productWasOutOfStock {
-			err = qtx.SetDefaultVariant(ctx, models.SetDefaultVariantParams{
-				ProductID: product.ProductID,
-				DefaultVariantID: sql.NullInt64{
-					Int64: finalVariant.VariantID,
-					Valid: true,
-				},
-			})
-			if err != nil {
-				return err
-			}
-		}
-
-		// Update product stock
-		err = qtx.UpdateProductStock(ctx, models.UpdateProductStockParams{
-			ProductID:     product.ProductID,
-			StockQuantity: in.Variant.Stock,
-		})
-		if err != nil {
-			return err
-		}
-			return nil
+		product, finalVariant, err = createProductTx(ctx, qtx, storeID, in, false, actor)
+		return err
 	})
 
 	if err != nil {
@@ -323,34 +349,11 @@ func (s *Service) CreateProduct(
 	// Upload image if provided and variant has no image yet
 	// either new variant or existing variant without image
 	if image != nil && finalVariant.PrimaryImageUrl.Valid == false {
-		key := generateImageUploadKey(storeID, finalVariant.VariantID)
-		url, _, err := s.media.UploadImage(ctx, key, image) 
 		// if the upload image fails we do not rollback the whole transaction as the product and variant were created/updated successfully
 		// we just skip setting the image and return success to the user
 		// the user can try to upload the image again later
-		// so we silently skip handling err != nil later
 		// TODO: Log that the image was not uploaded on err != nil
-		if err == nil {
-			err = s.db.Queries.SetPrimaryVariantImage(ctx, models.SetPrimaryVariantImageParams{
-				VariantID: finalVariant.VariantID,
-				PrimaryImageUrl: sql.NullString{
-					String: url,
-					Valid:  true,
-					},
-			})
-			
-			// we will not return error here also, just delete the uploaded image
-			if err != nil {
-					// TODO: Later we can use outbox pattern to handler the failure of Deleting image 
-					// right now we assume that delete always succeeds
-					_ = s.storage.Delete(ctx, key)
-			}else {
-				finalVariant.PrimaryImageUrl = sql.NullString{
-					String: url,
-					Valid:  true,
-				}
-			}
-		}
+		_ = s.setPrimaryImage(ctx, storeID, &finalVariant, image)
 	}
 
 	return &product, &finalVariant, nil
@@ -377,6 +380,7 @@ func (s *Service) AddVariant(
 	storeID, productID int64,
 	in models.VariantInput,
 	image multipart.File,
+	actor inventory.Actor,
 ) (*models.ProductVariant, error) {
 
 	var finalVariant  models.ProductVariant
@@ -402,16 +406,7 @@ func (s *Service) AddVariant(
 		hash := utils.HashAttributes(in.Attributes)
 
 		// Find or create variant
-		finalVariant, err = findOrCreateVariant(ctx, qtx, storeID, productID, hash, in)
-		if err != nil {
-			return err
-		}
-
-		// Update product stock
-		err = qtx.UpdateProductStock(ctx, models.UpdateProductStockParams{
-			ProductID:     productID,
-			StockQuantity: in.Stock,
-		})
+		finalVariant, err = findOrCreateVariant(ctx, qtx, storeID, productID, hash, in, false, actor)
 		if err != nil {
 			return err
 		}
@@ -429,7 +424,7 @@ func (s *Service) AddVariant(
 				return err
 			}
 		}
-		return nil
+		return qtx.RefreshProductSearch(ctx, productID)
 	})
 
 	if err != nil {
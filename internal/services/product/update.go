@@ -0,0 +1,177 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+)
+
+// UpdateProduct applies a partial update to a product of the store.
+//
+// A soft-deleted product can only be touched by a request that restores it.
+// Deleting a product takes its variants out of every cart.
+// Moving the product to another category re-validates the attributes of all
+// its live variants against the new category, inside the same transaction.
+func (s *Service) UpdateProduct(
+	ctx context.Context,
+	storeID, productID int64,
+	in models.UpdateProductInput,
+) (*models.Product, error) {
+
+	var product models.Product
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		current, err := qtx.GetStoreProductForUpdate(ctx, models.GetStoreProductForUpdateParams{
+			ProductID: productID,
+			StoreID:   storeID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorx.ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		restoring := in.Deleted != nil && !*in.Deleted
+		if current.DeletedAt.Valid && !restoring {
+			return errorx.ErrProductNotFound
+		}
+
+		params := models.UpdateProductParams{
+			ProductID:   productID,
+			StoreID:     storeID,
+			Name:        current.Name,
+			Slug:        current.Slug,
+			Description: current.Description,
+			Brand:       current.Brand,
+			CategoryID:  current.CategoryID,
+			DeletedAt:   current.DeletedAt,
+		}
+
+		if in.Name != nil {
+			name := strings.TrimSpace(*in.Name)
+			if name == "" {
+				return errorx.ErrInvalidProduct
+			}
+			params.Name = name
+		}
+
+		if in.Slug != nil {
+			params.Slug = sql.NullString{String: *in.Slug, Valid: *in.Slug != ""}
+			if params.Slug.Valid && params.Slug != current.Slug {
+				taken, err := qtx.ProductSlugTaken(ctx, models.ProductSlugTakenParams{
+					StoreID:   storeID,
+					Slug:      params.Slug,
+					ProductID: productID,
+				})
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errorx.ErrSlugTaken
+				}
+			}
+		}
+
+		if in.Description != nil {
+			params.Description = sql.NullString{String: *in.Description, Valid: *in.Description != ""}
+		}
+
+		if in.Brand != nil {
+			params.Brand = sql.NullString{String: *in.Brand, Valid: *in.Brand != ""}
+		}
+
+		if in.CategoryID != nil && *in.CategoryID != current.CategoryID {
+			if err := validateCategoryChange(ctx, qtx, storeID, productID, *in.CategoryID); err != nil {
+				return err
+			}
+			params.CategoryID = *in.CategoryID
+		}
+
+		if in.Deleted != nil {
+			switch {
+			case *in.Deleted && !current.DeletedAt.Valid:
+				params.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			case !*in.Deleted:
+				params.DeletedAt = sql.NullTime{}
+			}
+		}
+
+		product, err = qtx.UpdateProduct(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if params.DeletedAt.Valid && !current.DeletedAt.Valid {
+			if err := qtx.DeleteProductCartItems(ctx, productID); err != nil {
+				return err
+			}
+		}
+
+		return qtx.RefreshProductSearch(ctx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// DeleteProduct soft-deletes a product: it disappears from the storefront and
+// from every cart and can no longer be added to one, but orders keep
+// referencing it and it can be restored with UpdateProduct.
+func (s *Service) DeleteProduct(ctx context.Context, storeID, productID int64) error {
+	deleted := true
+	_, err := s.UpdateProduct(ctx, storeID, productID, models.UpdateProductInput{Deleted: &deleted})
+	return err
+}
+
+// validateCategoryChange checks that categoryID is offered by the store and
+// that every live variant of the product carries valid attributes for it.
+func validateCategoryChange(
+	ctx context.Context,
+	qtx *models.Queries,
+	storeID, productID, categoryID int64,
+) error {
+	ok, err := qtx.IsStoreCategory(ctx, models.IsStoreCategoryParams{
+		StoreID:    storeID,
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errorx.ErrInvalidCategory
+	}
+
+	variants, err := qtx.GetProductVariants(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := qtx.ListProductVariantAttributes(ctx, productID)
+	if err != nil {
+		return err
+	}
+	attrs := make(map[int64][]models.VariantAttributeInput, len(variants))
+	for _, r := range rows {
+		attrs[r.VariantID] = append(attrs[r.VariantID], models.VariantAttributeInput{
+			AttributeID: r.AttributeID,
+			Value:       r.Value,
+		})
+	}
+
+	for _, v := range variants {
+		if err := validateVariantAttributes(ctx, qtx, categoryID, attrs[v.VariantID]); err != nil {
+			return fmt.Errorf("%w: variant %d: %v", errorx.ErrCategoryAttributeMismatch, v.VariantID, err)
+		}
+	}
+
+	return nil
+}
@@ -3,7 +3,9 @@ package product
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/Secure-Website-Builder/Backend/internal/database"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
 )
 
@@ -20,9 +22,13 @@ func validateVariantAttributes(
 
 	allowed := make(map[int64]bool)
 	required := make(map[int64]bool)
+	dataType := make(map[int64]string)
+	values := make(map[int64][]string)
 
 	for _, a := range categoryAttrs {
 		allowed[a.AttributeID] = true
+		dataType[a.AttributeID] = a.DataType
+		values[a.AttributeID] = a.AllowedValues
 		if a.IsRequired {
 			required[a.AttributeID] = true
 		}
@@ -36,6 +42,12 @@ func validateVariantAttributes(
 				categoryID,
 			)
 		}
+		if err := database.ValidateAttributeValue(dataType[attr.AttributeID], attr.Value); err != nil {
+			return fmt.Errorf("attribute %d: %w", attr.AttributeID, err)
+		}
+		if list := values[attr.AttributeID]; list != nil && !slices.Contains(list, attr.Value) {
+			return fmt.Errorf("attribute %d: value %q is not allowed", attr.AttributeID, attr.Value)
+		}
 		delete(required, attr.AttributeID)
 	}
 
@@ -0,0 +1,178 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+)
+
+// UpdateVariant changes a variant's price, SKU and/or attributes.
+//
+// New attributes are validated against the product's category and replace
+// the variant's whole attribute set; the attribute hash is recomputed and
+// must not collide with another live variant of the same product.
+// Carts keep the unit price they were filled at.
+func (s *Service) UpdateVariant(
+	ctx context.Context,
+	storeID, productID, variantID int64,
+	in models.UpdateVariantInput,
+) (*models.ProductVariant, error) {
+
+	var variant models.ProductVariant
+
+	err := s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		product, current, err := lockVariant(ctx, qtx, storeID, productID, variantID)
+		if err != nil {
+			return err
+		}
+
+		params := models.UpdateVariantParams{
+			VariantID:     variantID,
+			Sku:           current.Sku,
+			Price:         current.Price,
+			AttributeHash: current.AttributeHash,
+		}
+
+		if in.Price != nil {
+			if *in.Price <= 0 {
+				return errorx.ErrInvalidVariantUpdate
+			}
+			params.Price = fmt.Sprintf("%f", *in.Price)
+		}
+
+		if in.SKU != nil {
+			sku := strings.TrimSpace(*in.SKU)
+			if sku == "" {
+				return errorx.ErrInvalidVariantUpdate
+			}
+			if sku != current.Sku {
+				taken, err := qtx.VariantSKUTaken(ctx, models.VariantSKUTakenParams{
+					StoreID:   storeID,
+					Sku:       sku,
+					VariantID: variantID,
+				})
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errorx.ErrSKUTaken
+				}
+			}
+			params.Sku = sku
+		}
+
+		if in.Attributes != nil {
+			seen := make(map[int64]bool, len(in.Attributes))
+			for _, a := range in.Attributes {
+				if seen[a.AttributeID] {
+					return errorx.ErrInvalidVariantUpdate
+				}
+				seen[a.AttributeID] = true
+			}
+
+			if err := validateVariantAttributes(ctx, qtx, product.CategoryID, in.Attributes); err != nil {
+				return fmt.Errorf("%w: %v", errorx.ErrCategoryAttributeMismatch, err)
+			}
+
+			params.AttributeHash = utils.HashAttributes(in.Attributes)
+
+			other, err := qtx.GetVariantByAttributeHash(ctx, models.GetVariantByAttributeHashParams{
+				ProductID:     productID,
+				AttributeHash: params.AttributeHash,
+			})
+			if err == nil && other.VariantID != variantID {
+				return errorx.ErrVariantExists
+			}
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			if err := qtx.DeleteVariantAttributes(ctx, variantID); err != nil {
+				return err
+			}
+			if err := insertVariantAttributes(ctx, qtx, variantID, in.Attributes); err != nil {
+				return err
+			}
+		}
+
+		variant, err = qtx.UpdateVariant(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if in.Attributes == nil {
+			return nil
+		}
+		return qtx.RefreshProductSearch(ctx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// RetireVariant soft-deletes a variant and takes it out of every cart.
+// Product stock is recomputed from the remaining variants and, when the
+// retired variant was the default one, another live variant (preferably one
+// in stock) becomes the default.
+func (s *Service) RetireVariant(ctx context.Context, storeID, productID, variantID int64) error {
+	return s.db.RunInTx(ctx, func(qtx *models.Queries) error {
+		if _, _, err := lockVariant(ctx, qtx, storeID, productID, variantID); err != nil {
+			return err
+		}
+
+		if err := qtx.RetireVariant(ctx, variantID); err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteVariantCartItems(ctx, variantID); err != nil {
+			return err
+		}
+
+		if err := qtx.RecalculateProductStock(ctx, productID); err != nil {
+			return err
+		}
+
+		if err := qtx.RefreshDefaultVariant(ctx, productID); err != nil {
+			return err
+		}
+
+		return qtx.RefreshProductSearch(ctx, productID)
+	})
+}
+
+// lockVariant locks a live product of the store and one of its live variants.
+func lockVariant(
+	ctx context.Context,
+	qtx *models.Queries,
+	storeID, productID, variantID int64,
+) (models.Product, models.ProductVariant, error) {
+
+	product, err := qtx.GetStoreProductForUpdate(ctx, models.GetStoreProductForUpdateParams{
+		ProductID: productID,
+		StoreID:   storeID,
+	})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && product.DeletedAt.Valid) {
+		return models.Product{}, models.ProductVariant{}, errorx.ErrProductNotFound
+	}
+	if err != nil {
+		return models.Product{}, models.ProductVariant{}, err
+	}
+
+	variant, err := qtx.GetVariantForUpdate(ctx, variantID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && variant.ProductID != productID) {
+		return models.Product{}, models.ProductVariant{}, errorx.ErrVariantNotFound
+	}
+	if err != nil {
+		return models.Product{}, models.ProductVariant{}, err
+	}
+
+	return product, variant, nil
+}
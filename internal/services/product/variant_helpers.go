@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/services/inventory"
 	"github.com/google/uuid"
 )
 
@@ -26,6 +27,9 @@ func insertVariantAttributes(
 	return nil
 }
 
+// findOrCreateVariant adds the input's stock to the product's variant with
+// the same attributes, creating it if needed. With setStock an existing
+// variant's stock is set to the input's instead, as a catalogue import does.
 func findOrCreateVariant(
 	ctx context.Context,
 	qtx *models.Queries,
@@ -33,34 +37,65 @@ func findOrCreateVariant(
 	productID int64,
 	hash string,
 	inputVariant models.VariantInput,
+	setStock bool,
+	actor inventory.Actor,
 ) (variant models.ProductVariant, err error) {
 
+	restock := func(variantID int64) error {
+		if inputVariant.Stock == 0 {
+			return nil
+		}
+		_, err := inventory.Apply(ctx, qtx, inventory.Movement{
+			VariantID: variantID,
+			Quantity:  inputVariant.Stock,
+			Reason:    inventory.ReasonRestock,
+			Actor:     actor,
+		})
+		return err
+	}
+
 	// Try to find existing variant
 	existingVariant, err := qtx.GetVariantByAttributeHash(ctx, models.GetVariantByAttributeHashParams{
 		ProductID:     productID,
 		AttributeHash: hash,
 	})
 
+	if err == nil && setStock {
+		// Variant exists - bring its stock to the input's
+		delta := inputVariant.Stock - existingVariant.StockQuantity
+		if delta != 0 {
+			if _, err := inventory.Apply(ctx, qtx, inventory.Movement{
+				VariantID: existingVariant.VariantID,
+				Quantity:  delta,
+				Reason:    inventory.ReasonAdjustment,
+				Actor:     actor,
+			}); err != nil {
+				return models.ProductVariant{}, err
+			}
+		}
+
+		existingVariant.StockQuantity = inputVariant.Stock
+		return existingVariant, nil
+	}
+
 	if err == nil {
 		// Variant exists - increase stock
-		if err := qtx.IncreaseVariantStock(ctx, models.IncreaseVariantStockParams{
-			VariantID:     existingVariant.VariantID,
-			StockQuantity: inputVariant.Stock,
-		}); err != nil {
+		if err := restock(existingVariant.VariantID); err != nil {
 			return models.ProductVariant{}, err
 		}
 
+		existingVariant.StockQuantity += inputVariant.Stock
 		return existingVariant, nil
 	}
 
-	// Variant does not exist - create new one
+	// Variant does not exist - create new one, stock arrives through the ledger
 	newVariant, err := qtx.CreateVariant(ctx, models.CreateVariantParams{
 		ProductID:     productID,
 		StoreID:       storeID,
 		AttributeHash: hash,
 		Sku:           inputVariant.SKU,
 		Price:         fmt.Sprintf("%f", inputVariant.Price),
-		StockQuantity: inputVariant.Stock,
+		StockQuantity: 0,
 	})
 	if err != nil {
 		return models.ProductVariant{}, err
@@ -70,6 +105,11 @@ func findOrCreateVariant(
 		return models.ProductVariant{}, err
 	}
 
+	if err := restock(newVariant.VariantID); err != nil {
+		return models.ProductVariant{}, err
+	}
+
+	newVariant.StockQuantity = inputVariant.Stock
 	return newVariant, nil
 }
 
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/Secure-Website-Builder/Backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// visitTimeout is how long a session may stay idle before its next use
+// counts as a new visit, turning the visitor into a returning one.
+const visitTimeout = 30 * time.Minute
+
+type Service struct {
+	db *database.DB
+}
+
+func New(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// Client describes where a session request came from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Start resumes the visitor session sessionID when it exists in the store,
+// or creates a new one. A session already owned by another customer (or by
+// a customer when the caller is a guest) is never resumed; a fresh one is
+// issued instead. created reports whether a new session was issued.
+func (s *Service) Start(
+	ctx context.Context,
+	storeID int64,
+	sessionID *uuid.UUID,
+	customerID *int64,
+	client Client,
+) (dto *models.SessionDTO, created bool, err error) {
+
+	if sessionID != nil {
+		vs, err := s.db.Queries.GetVisitorSession(ctx, models.GetVisitorSessionParams{
+			SessionID: *sessionID,
+			StoreID:   storeID,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, err
+		}
+
+		if err == nil && canResume(vs, customerID) {
+			vs, err = s.db.Queries.TouchVisitorSession(ctx, models.TouchVisitorSessionParams{
+				VisitStartedBefore: sql.NullTime{Time: time.Now().Add(-visitTimeout), Valid: true},
+				IpAddress:          toInet(client.IP),
+				UserAgent:          utils.PtrToNullString(&client.UserAgent),
+				CustomerID:         toNullInt64(customerID),
+				SessionID:          vs.SessionID,
+				StoreID:            storeID,
+			})
+			if err != nil {
+				return nil, false, err
+			}
+			return toSessionDTO(vs), false, nil
+		}
+	}
+
+	if _, err := s.db.Queries.GetStore(ctx, storeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, errorx.ErrStoreNotFound
+		}
+		return nil, false, err
+	}
+
+	vs, err := s.db.Queries.CreateVisitorSession(ctx, models.CreateVisitorSessionParams{
+		StoreID:    storeID,
+		CustomerID: toNullInt64(customerID),
+		IpAddress:  toInet(client.IP),
+		UserAgent:  utils.PtrToNullString(&client.UserAgent),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return toSessionDTO(vs), true, nil
+}
+
+// canResume reports whether the caller may keep using vs.
+func canResume(vs models.VisitorSession, customerID *int64) bool {
+	if !vs.CustomerID.Valid {
+		return true
+	}
+	return customerID != nil && *customerID == vs.CustomerID.Int64
+}
+
+func toNullInt64(id *int64) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *id, Valid: true}
+}
+
+func toInet(raw string) pqtype.Inet {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return pqtype.Inet{}
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	bits := len(ip) * 8
+	return pqtype.Inet{
+		IPNet: net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)},
+		Valid: true,
+	}
+}
+
+func toSessionDTO(vs models.VisitorSession) *models.SessionDTO {
+	return &models.SessionDTO{
+		SessionID:   vs.SessionID,
+		StoreID:     vs.StoreID,
+		CustomerID:  utils.NullInt64ToPtr(vs.CustomerID),
+		FirstSeenAt: utils.NullTimeToPtr(vs.FirstSeenAt),
+		LastSeenAt:  utils.NullTimeToPtr(vs.LastSeenAt),
+		IsReturning: vs.IsReturning.Bool,
+	}
+}
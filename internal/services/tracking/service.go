@@ -0,0 +1,187 @@
+package tracking
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/Secure-Website-Builder/Backend/internal/database"
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
+	"github.com/Secure-Website-Builder/Backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const EventProductView = "product_view"
+
+const (
+	// maxEventAge bounds how far back a client may date a batched event.
+	maxEventAge = 24 * time.Hour
+	// maxClockSkew tolerates client clocks slightly ahead of ours.
+	maxClockSkew = time.Minute
+	// shutdownFlushTimeout bounds the final flush once Run is stopped.
+	shutdownFlushTimeout = 5 * time.Second
+)
+
+// ProductView is one buffered product_view row.
+type ProductView struct {
+	StoreID   int64
+	ProductID int64
+	SessionID uuid.UUID
+	ViewedAt  time.Time
+}
+
+// Event is a client-reported event for the batch ingest endpoint.
+type Event struct {
+	Type       string
+	ProductID  int64
+	OccurredAt *time.Time
+}
+
+// Service buffers analytics events in memory and writes them in bulk from
+// Run, so request handlers never wait on an INSERT. When the buffer is full
+// new events are dropped rather than slowing requests down.
+type Service struct {
+	db        *database.DB
+	views     chan ProductView
+	batchSize int
+	dropped   atomic.Int64
+}
+
+func New(db *database.DB, bufferSize, batchSize int) *Service {
+	return &Service{
+		db:        db,
+		views:     make(chan ProductView, bufferSize),
+		batchSize: batchSize,
+	}
+}
+
+// RecordProductView queues a view without blocking.
+// It reports false when the buffer is full and the view was dropped.
+func (s *Service) RecordProductView(v ProductView) bool {
+	if v.ViewedAt.IsZero() {
+		v.ViewedAt = time.Now()
+	}
+
+	select {
+	case s.views <- v:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Ingest validates a batch of events reported by a visitor session and
+// queues them. It returns how many events were accepted into the buffer.
+func (s *Service) Ingest(ctx context.Context, storeID int64, sessionID uuid.UUID, events []Event) (int, error) {
+	now := time.Now()
+	views := make([]ProductView, 0, len(events))
+	for _, e := range events {
+		if e.Type != EventProductView || e.ProductID <= 0 {
+			return 0, errorx.ErrInvalidEvent
+		}
+
+		viewedAt := now
+		if e.OccurredAt != nil {
+			if e.OccurredAt.After(now.Add(maxClockSkew)) || e.OccurredAt.Before(now.Add(-maxEventAge)) {
+				return 0, errorx.ErrInvalidEvent
+			}
+			viewedAt = *e.OccurredAt
+		}
+
+		views = append(views, ProductView{
+			StoreID:   storeID,
+			ProductID: e.ProductID,
+			SessionID: sessionID,
+			ViewedAt:  viewedAt,
+		})
+	}
+
+	_, err := s.db.Queries.GetSession(ctx, models.GetSessionParams{
+		SessionID: sessionID,
+		StoreID:   storeID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errorx.ErrInvalidSession
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	accepted := 0
+	for _, v := range views {
+		if s.RecordProductView(v) {
+			accepted++
+		}
+	}
+	return accepted, nil
+}
+
+// Run drains the buffer until ctx is done, writing a batch whenever
+// batchSize events are queued or the flush interval elapses. Whatever is
+// still buffered when ctx is done is written before Run returns.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]ProductView, 0, s.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for drained := false; !drained; {
+				select {
+				case v := <-s.views:
+					batch = append(batch, v)
+				default:
+					drained = true
+				}
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+			s.flush(flushCtx, batch)
+			cancel()
+			return
+
+		case v := <-s.views:
+			batch = append(batch, v)
+			if len(batch) >= s.batchSize {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			s.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes one batch in a single statement. A failed batch is logged
+// and discarded: view tracking is best effort.
+func (s *Service) flush(ctx context.Context, batch []ProductView) {
+	if n := s.dropped.Swap(0); n > 0 {
+		log.Printf("tracking: buffer full, dropped %d product views", n)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	arg := models.InsertProductViewsParams{
+		ProductIds: make([]int64, len(batch)),
+		StoreIds:   make([]int64, len(batch)),
+		SessionIds: make([]uuid.UUID, len(batch)),
+		ViewedAts:  make([]time.Time, len(batch)),
+	}
+	for i, v := range batch {
+		arg.ProductIds[i] = v.ProductID
+		arg.StoreIds[i] = v.StoreID
+		arg.SessionIds[i] = v.SessionID
+		arg.ViewedAts[i] = v.ViewedAt
+	}
+
+	if err := s.db.Queries.InsertProductViews(ctx, arg); err != nil {
+		log.Printf("tracking: failed to write %d product views: %v", len(batch), err)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Secure-Website-Builder/Backend/internal/errorx"
 	"github.com/Secure-Website-Builder/Backend/internal/models"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -227,3 +228,47 @@ func NullStringToPtr(ns sql.NullString) *string {
 	}
 	return nil
 }
+
+func NullInt64ToPtr(n sql.NullInt64) *int64 {
+	if n.Valid {
+		return &n.Int64
+	}
+	return nil
+}
+
+func NullTimeToPtr(t sql.NullTime) *time.Time {
+	if t.Valid {
+		return &t.Time
+	}
+	return nil
+}
+
+func PtrToNullString(s *string) sql.NullString {
+	if s == nil || *s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// Store calendar days
+
+// StoreLocation returns a store's timezone, or UTC when it is unset or
+// unknown.
+func StoreLocation(timezone sql.NullString) *time.Location {
+	if timezone.Valid && timezone.String != "" {
+		if loc, err := time.LoadLocation(timezone.String); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// ParseDay reads a YYYY-MM-DD calendar day as its midnight in loc, or
+// returns errorx.ErrInvalidDateRange.
+func ParseDay(day string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		return time.Time{}, errorx.ErrInvalidDateRange
+	}
+	return t, nil
+}